@@ -0,0 +1,44 @@
+// Command reconcile-services reports drift between the track records in
+// tb_students and their copies in tb_service_student. Run it with -repair to
+// rewrite the copies from tb_students.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"elible/internal/app/repository"
+	"elible/internal/config"
+	"elible/internal/mongodb"
+)
+
+func main() {
+	repair := flag.Bool("repair", false, "rewrite drifted tb_service_student records from tb_students")
+	flag.Parse()
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Error loading configuration: %v", err)
+	}
+
+	mongoClient, err := mongodb.ConnectMongoDB(cfg.MongoDBURI, cfg.MongoDBName)
+	if err != nil {
+		log.Fatalf("Error connecting to MongoDB: %v", err)
+	}
+	defer mongoClient.Disconnect(context.Background())
+
+	report, err := repository.NewStudentRepository(cfg, mongoClient).ReconcileServices(*repair)
+	if err != nil {
+		log.Fatalf("Error reconciling services: %v", err)
+	}
+
+	for _, drift := range report.Drifts {
+		log.Printf("%s: student %s (%s)\n", drift.Issue, drift.StudentID, drift.Name)
+	}
+	log.Printf("Checked %d students, found %d drifted records, repaired %d\n", report.Checked, len(report.Drifts), report.Repaired)
+
+	if len(report.Drifts) > 0 && !*repair {
+		log.Println("Run again with -repair to fix them")
+	}
+}
@@ -6,6 +6,7 @@ require (
 	github.com/didip/tollbooth v4.0.2+incompatible
 	github.com/didip/tollbooth_gin v0.0.0-20170928041415-5752492be505
 	github.com/gin-gonic/gin v1.9.1
+	github.com/go-pdf/fpdf v0.8.0
 	github.com/golang-jwt/jwt v3.2.2+incompatible
 	github.com/joho/godotenv v1.5.1
 	github.com/xuri/excelize/v2 v2.7.1
@@ -4,6 +4,7 @@ import (
 	"elible/internal/app/repository"
 	"elible/internal/app/services"
 	"elible/internal/config"
+	"elible/internal/mailer"
 
 	"go.mongodb.org/mongo-driver/mongo"
 )
@@ -19,6 +20,17 @@ type Dependencies struct {
 	UniversityService *services.UniversityService
 	StudyProgramService *services.StudyProgramService
 	KnowledgeBaseService *services.KnowledgeBaseService
+	UserService          *services.UserService
+	APIKeyService        *services.APIKeyService
+	AuditService         *services.AuditService
+	TrashService         *services.TrashService
+	PipelineService      *services.PipelineService
+	InvoiceService       *services.InvoiceService
+	AnalyticsService     *services.AnalyticsService
+	SchoolService        *services.SchoolService
+	HistoryService       *services.HistoryService
+	TaskService          *services.TaskService
+	InteractionService   *services.InteractionService
 	// Add your other services here
 }
 
@@ -28,12 +40,55 @@ func InitializeDependencies(cfg *config.Config, mongoClient *mongo.Client) (*Dep
 	univRepo := repository.NewUniversityRepository(cfg, mongoClient)
 	programtRepo := repository.NewStudyProgramRepository(cfg, mongoClient)
 	knowRepo := repository.NewKnowledgeBaseRepository(cfg, mongoClient)
+	userRepo := repository.NewUserRepository(cfg, mongoClient)
+	apiKeyRepo := repository.NewAPIKeyRepository(cfg, mongoClient)
+	auditRepo := repository.NewAuditRepository(cfg, mongoClient)
+	pipelineRepo := repository.NewPipelineRepository(cfg, mongoClient)
+	invoiceRepo := repository.NewInvoiceRepository(cfg, mongoClient)
+	analyticsRepo := repository.NewAnalyticsRepository(cfg, mongoClient)
+	schoolRepo := repository.NewSchoolRepository(cfg, mongoClient)
+	historyRepo := repository.NewHistoryRepository(cfg, mongoClient)
+	counselorRepo := repository.NewCounselorRepository(cfg, mongoClient)
+	taskRepo := repository.NewTaskRepository(cfg, mongoClient)
+	interactionRepo := repository.NewInteractionRepository(cfg, mongoClient)
 
-	adminService := services.NewAdminService(adminRepo)
-	studentService := services.NewStudentService(studentRepo)
+	mail := mailer.NewMailer(cfg)
+	adminService := services.NewAdminService(cfg, adminRepo, mail)
+	pipelineService := services.NewPipelineService(pipelineRepo)
+	counselorService := services.NewCounselorService(cfg, counselorRepo, adminRepo)
+	taskService := services.NewTaskService(cfg, taskRepo, studentRepo, adminRepo, pipelineService, mail)
+	interactionService := services.NewInteractionService(interactionRepo, studentRepo)
+	studentService := services.NewStudentService(studentRepo, pipelineService, counselorService, taskService, interactionService)
 	univService := services.NewUniversityService(univRepo)
 	programService := services.NewStudyProgramService(programtRepo)
 	knowService := services.NewKnowledgeBaseService(knowRepo)
+	userService := services.NewUserService(cfg, userRepo, studentRepo, mail)
+	apiKeyService := services.NewAPIKeyService(apiKeyRepo)
+	auditService := services.NewAuditService(auditRepo)
+	invoiceService := services.NewInvoiceService(cfg, invoiceRepo, studentRepo)
+	analyticsService := services.NewAnalyticsService(analyticsRepo, pipelineService)
+	schoolService := services.NewSchoolService(schoolRepo, studentRepo)
+	historyService := services.NewHistoryService(historyRepo, studentRepo)
+	trashService := services.NewTrashService(studentService, univService, programService, knowService, cfg.TrashRetention())
+
+	if err := adminService.EnsureIndexes(); err != nil {
+		return nil, err
+	}
+	if err := adminService.EnsureSuperAdmin(); err != nil {
+		return nil, err
+	}
+	if err := pipelineService.EnsureDefaultStages(); err != nil {
+		return nil, err
+	}
+	if err := interactionService.EnsureIndexes(); err != nil {
+		return nil, err
+	}
+	if err := invoiceService.EnsureIndexes(); err != nil {
+		return nil, err
+	}
+	if err := schoolService.EnsureIndexes(); err != nil {
+		return nil, err
+	}
 
 	return &Dependencies{
 		AdminService:   adminService,
@@ -41,5 +96,16 @@ func InitializeDependencies(cfg *config.Config, mongoClient *mongo.Client) (*Dep
 		UniversityService: univService,
 		StudyProgramService: programService,
 		KnowledgeBaseService: knowService,
+		UserService:          userService,
+		APIKeyService:        apiKeyService,
+		AuditService:         auditService,
+		TrashService:         trashService,
+		PipelineService:      pipelineService,
+		InvoiceService:       invoiceService,
+		AnalyticsService:     analyticsService,
+		SchoolService:        schoolService,
+		HistoryService:       historyService,
+		TaskService:          taskService,
+		InteractionService:   interactionService,
 	}, nil
 }
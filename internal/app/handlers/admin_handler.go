@@ -1,9 +1,12 @@
 package handlers
 
 import (
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
 
+	"elible/internal/app/middleware"
 	"elible/internal/app/models"
 	"elible/internal/app/services"
 	errors "elible/internal/pkg"
@@ -22,18 +25,26 @@ func NewAdminHandler(service *services.AdminService) *AdminHandler {
 }
 
 func (h *AdminHandler) RegisterAdmin(c *gin.Context) {
-	var admin models.Admin
-	if err := c.ShouldBind(&admin); err != nil {
+	var request RegisterAdminRequest
+	if err := c.ShouldBind(&request); err != nil {
 		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
 		return
 	}
 
+	admin := models.Admin{
+		Username: request.Username,
+		Password: request.Password,
+		Role:     request.Role,
+		Email:    request.Email,
+		FullName: request.FullName,
+	}
+
 	if err := h.service.Create(&admin); err != nil {
 		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
 		return
 	}
 
-	response := errors.NewResponseData(http.StatusCreated, "Admin created successfully", admin)
+	response := errors.NewResponseData(http.StatusCreated, "Admin created successfully", adminResponse(&admin))
 	c.JSON(http.StatusCreated, response)
 }
 
@@ -44,21 +55,156 @@ func (h *AdminHandler) LoginAdmin(c *gin.Context) {
 		return
 	}
 
-	admin, token, err := h.service.Login(credentials.Username, credentials.Password)
+	result, err := h.service.Login(credentials.Username, credentials.Password, models.SessionInfo{
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
+	})
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, errors.NewResponseError(http.StatusUnauthorized, err.Error()))
+		writeLoginError(c, err)
+		return
+	}
+
+	writeLoginResult(c, result)
+}
+
+func (h *AdminHandler) VerifyLogin(c *gin.Context) {
+	var request VerifyLoginRequest
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	result, err := h.service.VerifyLogin(request.MFAToken, request.Code, request.RecoveryCode, models.SessionInfo{
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
+	})
+	if err != nil {
+		writeLoginError(c, err)
+		return
+	}
+
+	writeLoginResult(c, result)
+}
+
+func writeLoginError(c *gin.Context, err error) {
+	if throttled, ok := err.(*services.LoginThrottledError); ok {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+		c.JSON(http.StatusTooManyRequests, errors.NewResponseError(http.StatusTooManyRequests, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusUnauthorized, errors.NewResponseError(http.StatusUnauthorized, err.Error()))
+}
+
+func writeLoginResult(c *gin.Context, result *models.LoginResult) {
+	if result.MFARequired {
+		response := errors.NewResponseData(http.StatusOK, "Two-factor authentication required", gin.H{
+			"mfa_required": true,
+			"mfa_token":    result.MFAToken,
+		})
+		c.JSON(http.StatusOK, response)
 		return
 	}
 
 	response := errors.NewResponseData(http.StatusOK, "Login successful", gin.H{
-		"admin": gin.H{
-			"Username": admin.Username,
-			"Email":    admin.Email,
-			"FullName": admin.FullName,
-		},
-		"token": token,
+		"admin":         adminResponse(result.Admin),
+		"token":         result.Token.AccessToken,
+		"token_expires": result.Token.AtExpires,
+		"refresh_token": result.Token.RefreshToken,
+	})
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AdminHandler) RefreshToken(c *gin.Context) {
+	var request RefreshTokenRequest
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	token, err := h.service.Refresh(request.RefreshToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, errors.NewResponseError(http.StatusUnauthorized, err.Error()))
+		return
+	}
+
+	response := errors.NewResponseData(http.StatusOK, "Token refreshed successfully", gin.H{
+		"token":         token.AccessToken,
+		"token_expires": token.AtExpires,
+		"refresh_token": token.RefreshToken,
 	})
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AdminHandler) Logout(c *gin.Context) {
+	token, ok := bearerToken(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, errors.NewResponseError(http.StatusUnauthorized, "Invalid Authorization header format"))
+		return
+	}
+
+	if err := h.service.Logout(token); err != nil {
+		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	response := errors.NewResponseData(http.StatusOK, "Logged out successfully", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AdminHandler) LogoutAll(c *gin.Context) {
+	if err := h.service.LogoutAll(middleware.CurrentAdmin(c)); err != nil {
+		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	response := errors.NewResponseData(http.StatusOK, "Logged out from all sessions successfully", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AdminHandler) ListSessions(c *gin.Context) {
+	token, _ := bearerToken(c)
+
+	sessions, err := h.service.ListSessions(middleware.CurrentAdmin(c), token)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	response := errors.NewResponseData(http.StatusOK, "Sessions fetched successfully", sessions)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AdminHandler) RevokeSession(c *gin.Context) {
+	var request RequestWithID
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if err := h.service.RevokeSession(middleware.CurrentAdmin(c), request.ID); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
 
+	response := errors.NewResponseData(http.StatusOK, "Session revoked successfully", gin.H{"id": request.ID})
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AdminHandler) LoginHistory(c *gin.Context) {
+	var request LoginHistoryRequest
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	history, err := h.service.LoginHistory(middleware.CurrentAdmin(c), request.Limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	response := errors.NewResponseData(http.StatusOK, "Login history fetched successfully", history)
 	c.JSON(http.StatusOK, response)
 }
 
@@ -83,13 +229,279 @@ func (h *AdminHandler) GetProfileByToken(c *gin.Context) {
 	}
 
 	response := errors.NewResponseData(http.StatusOK, "Fetched admin profile successfully", gin.H{
-		"admin": gin.H{
-			"Username": admin.Username,
-			"Email":    admin.Email,
-			"FullName": admin.FullName,
-		},
+		"admin": adminResponse(admin),
 		"token": token,
 	})
 
 	c.JSON(http.StatusOK, response)
 }
+
+func (h *AdminHandler) AssignRole(c *gin.Context) {
+	var request AssignRoleRequest
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	admin, err := h.service.AssignRole(request.ID, models.Role(request.Role))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	response := errors.NewResponseData(http.StatusOK, "Role assigned successfully", gin.H{
+		"admin": adminResponse(admin),
+	})
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AdminHandler) ListRoles(c *gin.Context) {
+	response := errors.NewResponseData(http.StatusOK, "Roles fetched successfully", models.RolePermissions)
+	c.JSON(http.StatusOK, response)
+}
+
+func bearerToken(c *gin.Context) (string, bool) {
+	tokenParts := strings.Split(c.GetHeader("Authorization"), " ")
+	if len(tokenParts) != 2 || strings.ToLower(tokenParts[0]) != "bearer" {
+		return "", false
+	}
+	return tokenParts[1], true
+}
+
+func (h *AdminHandler) ListAdmins(c *gin.Context) {
+	admins, err := h.service.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	results := make([]gin.H, 0, len(admins))
+	for i := range admins {
+		results = append(results, adminResponse(&admins[i]))
+	}
+
+	response := errors.NewResponseData(http.StatusOK, "Admins fetched successfully", results)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AdminHandler) UpdateAdmin(c *gin.Context) {
+	var request UpdateAdminRequest
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	admin, err := h.service.UpdateProfile(request.ID, request.Admin)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	response := errors.NewResponseData(http.StatusOK, "Admin updated successfully", adminResponse(admin))
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AdminHandler) UpdateOwnProfile(c *gin.Context) {
+	var request models.AdminProfile
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	admin, err := h.service.UpdateProfile(middleware.CurrentAdmin(c).ID.Hex(), request)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	response := errors.NewResponseData(http.StatusOK, "Profile updated successfully", adminResponse(admin))
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AdminHandler) ChangePassword(c *gin.Context) {
+	var request ChangePasswordRequest
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	token, _ := bearerToken(c)
+	if err := h.service.ChangePassword(middleware.CurrentAdmin(c), token, request.OldPassword, request.NewPassword); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	response := errors.NewResponseData(http.StatusOK, "Password changed successfully", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AdminHandler) DisableAdmin(c *gin.Context) {
+	h.setDisabled(c, true, "Admin disabled successfully")
+}
+
+func (h *AdminHandler) EnableAdmin(c *gin.Context) {
+	h.setDisabled(c, false, "Admin enabled successfully")
+}
+
+func (h *AdminHandler) setDisabled(c *gin.Context, disabled bool, message string) {
+	var request RequestWithID
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	admin, err := h.service.SetDisabled(middleware.CurrentAdmin(c), request.ID, disabled)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	response := errors.NewResponseData(http.StatusOK, message, adminResponse(admin))
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AdminHandler) DeleteAdmin(c *gin.Context) {
+	var request RequestWithID
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if err := h.service.Delete(middleware.CurrentAdmin(c), request.ID); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	response := errors.NewResponseData(http.StatusOK, "Admin deleted successfully", gin.H{"id": request.ID})
+	c.JSON(http.StatusOK, response)
+}
+
+// adminResponse never exposes the password hash.
+func adminResponse(admin *models.Admin) gin.H {
+	return gin.H{
+		"ID":               admin.ID,
+		"Username":         admin.Username,
+		"Email":            admin.Email,
+		"FullName":         admin.FullName,
+		"Role":             admin.EffectiveRole(),
+		"Disabled":         admin.Disabled,
+		"TwoFactorEnabled": admin.TOTPEnabled,
+	}
+}
+
+func (h *AdminHandler) ForgotPassword(c *gin.Context) {
+	var request ForgotPasswordRequest
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	h.service.ForgotPassword(request.Email)
+
+	response := errors.NewResponseData(http.StatusOK, "If the email belongs to an admin, a reset link has been sent", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AdminHandler) ResetPassword(c *gin.Context) {
+	var request ResetPasswordRequest
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if err := h.service.ResetPassword(request.Token, request.Password); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	response := errors.NewResponseData(http.StatusOK, "Password reset successfully", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AdminHandler) SetupTOTP(c *gin.Context) {
+	setup, err := h.service.SetupTOTP(middleware.CurrentAdmin(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	response := errors.NewResponseData(http.StatusOK, "Scan the provisioning URI with an authenticator app", setup)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AdminHandler) ConfirmTOTP(c *gin.Context) {
+	var request TOTPCodeRequest
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	codes, err := h.service.ConfirmTOTP(middleware.CurrentAdmin(c), request.Code)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	response := errors.NewResponseData(http.StatusOK, "Two-factor authentication enabled", gin.H{"recovery_codes": codes})
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AdminHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var request TOTPCodeRequest
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	codes, err := h.service.RegenerateRecoveryCodes(middleware.CurrentAdmin(c), request.Code)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	response := errors.NewResponseData(http.StatusOK, "Recovery codes regenerated", gin.H{"recovery_codes": codes})
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AdminHandler) DisableTOTP(c *gin.Context) {
+	var request DisableTOTPRequest
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if err := h.service.DisableTOTP(middleware.CurrentAdmin(c), request.Password, request.Code); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	response := errors.NewResponseData(http.StatusOK, "Two-factor authentication disabled", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AdminHandler) UnlockAdmin(c *gin.Context) {
+	var request UnlockRequest
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if err := h.service.Unlock(request.Username, request.IPAddress); err != nil {
+		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	response := errors.NewResponseData(http.StatusOK, "Login lock removed successfully", request)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AdminHandler) ListLockouts(c *gin.Context) {
+	lockouts, err := h.service.ListLockouts()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	response := errors.NewResponseData(http.StatusOK, "Locked logins fetched successfully", lockouts)
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"net/http"
+
+	"elible/internal/app/models"
+	"elible/internal/app/services"
+	errors "elible/internal/pkg"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AnalyticsHandler struct {
+	service *services.AnalyticsService
+}
+
+func NewAnalyticsHandler(service *services.AnalyticsService) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		service: service,
+	}
+}
+
+// Revenue returns a handler reporting revenue grouped by the given models.RevenueBy* grouping.
+func (h *AnalyticsHandler) Revenue(grouping string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var filter models.AnalyticsFilter
+		if err := c.ShouldBind(&filter); err != nil {
+			c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+			return
+		}
+
+		rows, err := h.service.RevenueBy(grouping, &filter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
+			return
+		}
+
+		response := errors.NewResponseData(http.StatusOK, "Revenue fetched successfully", rows)
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func (h *AnalyticsHandler) CountByStatus(c *gin.Context) {
+	var filter models.AnalyticsFilter
+	if err := c.ShouldBind(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	counts, err := h.service.CountByStatus(&filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	response := errors.NewResponseData(http.StatusOK, "Service statuses fetched successfully", counts)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AnalyticsHandler) Outstanding(c *gin.Context) {
+	var filter models.AnalyticsFilter
+	if err := c.ShouldBind(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	summary, err := h.service.Outstanding(&filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	response := errors.NewResponseData(http.StatusOK, "Outstanding amounts fetched successfully", summary)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AnalyticsHandler) Funnel(c *gin.Context) {
+	var filter models.FunnelFilter
+	if err := c.ShouldBind(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	funnel, err := h.service.Funnel(&filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	response := errors.NewResponseData(http.StatusOK, "Funnel fetched successfully", funnel)
+	c.JSON(http.StatusOK, response)
+}
+
+// DropOff returns a handler comparing students grouped by the given models.DropOffBy* grouping.
+func (h *AnalyticsHandler) DropOff(grouping string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var filter models.FunnelFilter
+		if err := c.ShouldBind(&filter); err != nil {
+			c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+			return
+		}
+
+		rows, err := h.service.DropOff(grouping, &filter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
+			return
+		}
+
+		response := errors.NewResponseData(http.StatusOK, "Drop-off fetched successfully", rows)
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func (h *AnalyticsHandler) Cohorts(c *gin.Context) {
+	var filter models.FunnelFilter
+	if err := c.ShouldBind(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	cohorts, err := h.service.Cohorts(&filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	response := errors.NewResponseData(http.StatusOK, "Cohorts fetched successfully", cohorts)
+	c.JSON(http.StatusOK, response)
+}
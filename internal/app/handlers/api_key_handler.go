@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"net/http"
+
+	"elible/internal/app/middleware"
+	"elible/internal/app/services"
+	errors "elible/internal/pkg"
+
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyHandler struct {
+	service *services.APIKeyService
+}
+
+func NewAPIKeyHandler(service *services.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		service: service,
+	}
+}
+
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	var request CreateAPIKeyRequest
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	key, plainKey, err := h.service.Create(request.Name, request.Scopes, middleware.CurrentAdmin(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	// The plain key is never stored, so this is the only time it can be shown
+	response := errors.NewResponseData(http.StatusCreated, "API key created successfully, store the key now as it will not be shown again", gin.H{
+		"api_key": key,
+		"key":     plainKey,
+	})
+	c.JSON(http.StatusCreated, response)
+}
+
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	keys, err := h.service.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	response := errors.NewResponseData(http.StatusOK, "API keys fetched successfully", keys)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	var request RequestWithID
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if err := h.service.Revoke(request.ID); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	response := errors.NewResponseData(http.StatusOK, "API key revoked successfully", nil)
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"net/http"
+
+	"elible/internal/app/middleware"
+	"elible/internal/app/models"
+	"elible/internal/app/services"
+	errors "elible/internal/pkg"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditHandler struct {
+	service *services.AuditService
+}
+
+func NewAuditHandler(service *services.AuditService) *AuditHandler {
+	return &AuditHandler{
+		service: service,
+	}
+}
+
+func (h *AuditHandler) ListAuditLogs(c *gin.Context) {
+	var filter models.AuditFilter
+	if err := c.ShouldBind(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	logs, err := h.service.List(&filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	response := errors.NewResponseData(http.StatusOK, "Audit logs fetched successfully", logs)
+	c.JSON(http.StatusOK, response)
+}
+
+// recordAudit writes an audit entry for a successful write made in the current request.
+func recordAudit(c *gin.Context, audit *services.AuditService, action, collection, documentID string, before, after interface{}, details interface{}) {
+	audit.Record(&models.AuditLog{
+		Actor:      middleware.CurrentActor(c),
+		Action:     action,
+		Method:     c.Request.Method,
+		Route:      c.FullPath(),
+		Collection: collection,
+		DocumentID: documentID,
+		Details:    details,
+	}, before, after)
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"elible/internal/app/middleware"
+	"elible/internal/app/models"
+	"elible/internal/app/services"
+	errors "elible/internal/pkg"
+
+	"github.com/gin-gonic/gin"
+)
+
+type InteractionHandler struct {
+	service *services.InteractionService
+	audit   *services.AuditService
+}
+
+func NewInteractionHandler(service *services.InteractionService, audit *services.AuditService) *InteractionHandler {
+	return &InteractionHandler{
+		service: service,
+		audit:   audit,
+	}
+}
+
+func (h *InteractionHandler) CreateInteraction(c *gin.Context) {
+	var draft models.InteractionDraft
+	if err := c.ShouldBind(&draft); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+	draft.Attachment = strings.TrimSpace(draft.Attachment)
+	if draft.Attachment != "" && !isUploadURL(draft.Attachment, "interactions") {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, "attachment must be a file uploaded with /interaction/upload"))
+		return
+	}
+
+	interaction, err := h.service.Create(&draft, middleware.CurrentActor(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+	recordAudit(c, h.audit, models.AuditActionCreate, "tb_interactions", interaction.ID.Hex(), nil, interaction, nil)
+
+	response := errors.NewResponseData(http.StatusCreated, "Interaction logged successfully", interaction)
+	c.JSON(http.StatusCreated, response)
+}
+
+func (h *InteractionHandler) SearchInteractions(c *gin.Context) {
+	var filter models.InteractionFilter
+	if err := c.ShouldBind(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	interactions, err := h.service.Search(&filter)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	response := errors.NewResponseData(http.StatusOK, "Interactions fetched successfully", interactions)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *InteractionHandler) DeleteInteraction(c *gin.Context) {
+	var request RequestWithID
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	before, _ := h.service.GetByID(request.ID)
+	if err := h.service.Delete(request.ID); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+	recordAudit(c, h.audit, models.AuditActionDelete, "tb_interactions", request.ID, before, nil, nil)
+
+	response := errors.NewResponseData(http.StatusOK, "Interaction deleted successfully", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+// UploadAttachment stores a file for an interaction and returns the URL to
+// pass as attachment when logging it.
+func (h *InteractionHandler) UploadAttachment(c *gin.Context) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, "File is not present in the form data"))
+		return
+	}
+
+	fullPath, status, err := saveAttachment(c, file, "interactions")
+	if err != nil {
+		c.JSON(status, errors.NewResponseError(status, err.Error()))
+		return
+	}
+
+	response := errors.NewResponseData(http.StatusCreated, "Upload Attachment Success", fullPath)
+	c.JSON(http.StatusCreated, response)
+}
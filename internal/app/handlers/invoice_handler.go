@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"elible/internal/app/middleware"
+	"elible/internal/app/models"
+	"elible/internal/app/services"
+	errors "elible/internal/pkg"
+
+	"github.com/gin-gonic/gin"
+)
+
+type InvoiceHandler struct {
+	service *services.InvoiceService
+	audit   *services.AuditService
+}
+
+func NewInvoiceHandler(service *services.InvoiceService, audit *services.AuditService) *InvoiceHandler {
+	return &InvoiceHandler{
+		service: service,
+		audit:   audit,
+	}
+}
+
+// snapshot loads an invoice for the audit log, ignoring lookup errors.
+func (h *InvoiceHandler) snapshot(invoiceID string) *models.Invoice {
+	invoice, _ := h.service.GetByID(invoiceID)
+	return invoice
+}
+
+func (h *InvoiceHandler) CreateInvoice(c *gin.Context) {
+	var draft models.InvoiceDraft
+	if err := c.ShouldBind(&draft); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	invoice, err := h.service.Create(&draft, middleware.CurrentActor(c).ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+	recordAudit(c, h.audit, models.AuditActionCreate, "tb_invoices", invoice.ID.Hex(), nil, invoice, nil)
+
+	response := errors.NewResponseData(http.StatusCreated, "Invoice created successfully", invoice)
+	c.JSON(http.StatusCreated, response)
+}
+
+func (h *InvoiceHandler) GetInvoices(c *gin.Context) {
+	var filter models.InvoiceFilter
+	if err := c.ShouldBind(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	invoices, err := h.service.List(&filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	response := errors.NewResponseData(http.StatusOK, "Invoices fetched successfully", invoices)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *InvoiceHandler) GetInvoice(c *gin.Context) {
+	var request RequestWithID
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	invoice, err := h.service.GetByID(request.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
+		return
+	}
+	if invoice == nil {
+		c.JSON(http.StatusNotFound, errors.NewResponseError(http.StatusNotFound, "Invoice not found"))
+		return
+	}
+
+	response := errors.NewResponseData(http.StatusOK, "Invoice fetched successfully", invoice)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *InvoiceHandler) UpdateInvoice(c *gin.Context) {
+	var update models.InvoiceTermsUpdate
+	if err := c.ShouldBind(&update); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	before := h.snapshot(update.ID)
+	invoice, err := h.service.UpdateTerms(&update)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	recordAudit(c, h.audit, models.AuditActionUpdate, "tb_invoices", update.ID, before, invoice, nil)
+
+	response := errors.NewResponseData(http.StatusOK, "Invoice updated successfully", invoice)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *InvoiceHandler) RecordPayment(c *gin.Context) {
+	var draft models.PaymentDraft
+	if err := c.ShouldBind(&draft); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	before := h.snapshot(draft.InvoiceID)
+	invoice, err := h.service.RecordPayment(&draft, middleware.CurrentActor(c).ID)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	recordAudit(c, h.audit, models.AuditActionUpdate, "tb_invoices", draft.InvoiceID, before, invoice, gin.H{"payment_amount": draft.Amount})
+
+	response := errors.NewResponseData(http.StatusOK, "Payment recorded successfully", invoice)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *InvoiceHandler) VoidInvoice(c *gin.Context) {
+	var request VoidInvoiceRequest
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	before := h.snapshot(request.ID)
+	if err := h.service.Void(request.ID, request.Reason); err != nil {
+		h.writeError(c, err)
+		return
+	}
+	recordAudit(c, h.audit, models.AuditActionUpdate, "tb_invoices", request.ID, before, h.snapshot(request.ID), nil)
+
+	response := errors.NewResponseData(http.StatusOK, "Invoice voided successfully", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+// DownloadReceipt returns the invoice as a PDF file.
+func (h *InvoiceHandler) DownloadReceipt(c *gin.Context) {
+	var request RequestWithID
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	invoice, pdf, err := h.service.Receipt(request.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	filename := strings.ReplaceAll(invoice.Number, "/", "-") + ".pdf"
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+// writeError answers 409 when the invoice changed concurrently and 400 otherwise.
+func (h *InvoiceHandler) writeError(c *gin.Context, err error) {
+	if err == services.ErrInvoiceChanged {
+		c.JSON(http.StatusConflict, errors.NewResponseError(http.StatusConflict, err.Error()))
+		return
+	}
+	c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"net/http"
 
+	"elible/internal/app/middleware"
 	"elible/internal/app/models"
 	"elible/internal/app/services"
 	errors "elible/internal/pkg"
@@ -12,14 +13,22 @@ import (
 
 type KnowledgeBaseHandler struct {
 	service *services.KnowledgeBaseService
+	audit   *services.AuditService
 }
 
-func NewKnowledgeBaseHandler(service *services.KnowledgeBaseService) *KnowledgeBaseHandler {
+func NewKnowledgeBaseHandler(service *services.KnowledgeBaseService, audit *services.AuditService) *KnowledgeBaseHandler {
 	return &KnowledgeBaseHandler{
 		service: service,
+		audit:   audit,
 	}
 }
 
+// snapshot loads a knowledge base for the audit log, ignoring lookup errors.
+func (h *KnowledgeBaseHandler) snapshot(id string) *models.KnowledgeBase {
+	kb, _ := h.service.GetKnowledgeBase(id)
+	return kb
+}
+
 func (h *KnowledgeBaseHandler) CreateKnowledgeBase(c *gin.Context) {
 	var kb models.KnowledgeBase
 	if err := c.ShouldBindJSON(&kb); err != nil {
@@ -31,6 +40,7 @@ func (h *KnowledgeBaseHandler) CreateKnowledgeBase(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
 		return
 	}
+	recordAudit(c, h.audit, models.AuditActionCreate, "tb_knowledge_bases", kb.ID.Hex(), nil, &kb, nil)
 
 	response := errors.NewResponseData(http.StatusCreated, "KnowledgeBase created successfully", kb)
 	c.JSON(http.StatusCreated, response)
@@ -43,10 +53,12 @@ func (h *KnowledgeBaseHandler) DeleteKnowledgeBase(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.DeleteKnowledgeBase(request.ID); err != nil {
-		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
+	before := h.snapshot(request.ID)
+	if err := h.service.DeleteKnowledgeBase(request.ID, middleware.CurrentActor(c).ID); err != nil {
+		errors.WriteStoreError(c, err, "Knowledge Base not found")
 		return
 	}
+	recordAudit(c, h.audit, models.AuditActionDelete, "tb_knowledge_bases", request.ID, before, nil, nil)
 
 	c.JSON(http.StatusOK, gin.H{"message": "KnowledgeBase deleted successfully"})
 }
@@ -58,10 +70,12 @@ func (h *KnowledgeBaseHandler) UpdateKnowledgeBase(c *gin.Context) {
 		return
 	}
 
+	before := h.snapshot(request.ID)
 	if err := h.service.UpdateKnowledgeBase(request.ID, &request.KnowledgeBase); err != nil {
-		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
+		errors.WriteStoreError(c, err, "Knowledge Base not found")
 		return
 	}
+	recordAudit(c, h.audit, models.AuditActionUpdate, "tb_knowledge_bases", request.ID, before, h.snapshot(request.ID), nil)
 
 	response := errors.NewResponseData(http.StatusOK, "Knowledge Base updated successfully", request.KnowledgeBase)
 	c.JSON(http.StatusOK, response)
@@ -85,10 +99,12 @@ func (h *KnowledgeBaseHandler) AddKnowledgeProgram(c *gin.Context) {
 		return
 	}
 
+	before := h.snapshot(request.ID)
 	if err := h.service.AddKnowledgeProgram(request.ID, request.KnowledgeProgram); err != nil {
 		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
 		return
 	}
+	recordAudit(c, h.audit, models.AuditActionUpdate, "tb_knowledge_bases", request.ID, before, h.snapshot(request.ID), nil)
 
 	response := errors.NewResponseData(http.StatusOK, "Knowledge Program added successfully", request)
 	c.JSON(http.StatusOK, response)
@@ -101,10 +117,12 @@ func (h *KnowledgeBaseHandler) RemoveKnowledgeProgram(c *gin.Context) {
 		return
 	}
 
+	before := h.snapshot(request.ID)
 	if err := h.service.RemoveKnowledgeProgram(request.ID, request.ProgramName); err != nil {
 		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
 		return
 	}
+	recordAudit(c, h.audit, models.AuditActionUpdate, "tb_knowledge_bases", request.ID, before, h.snapshot(request.ID), nil)
 
 	response := errors.NewResponseData(http.StatusOK, "Knowledge Program removed successfully", request)
 	c.JSON(http.StatusOK, response)
@@ -136,11 +154,41 @@ func (h *KnowledgeBaseHandler) UpdateKnowledgeProgram(c *gin.Context) {
 		return
 	}
 
+	before := h.snapshot(request.ID)
 	if err := h.service.UpdateKnowledgeProgram(request.ID, request.OldName, request.KnowledgeProgram); err != nil {
-		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
+		errors.WriteStoreError(c, err, "Knowledge Base not found")
 		return
 	}
+	recordAudit(c, h.audit, models.AuditActionUpdate, "tb_knowledge_bases", request.ID, before, h.snapshot(request.ID), nil)
 
 	response := errors.NewResponseData(http.StatusOK, "Knowledge Program update successfully", request)
 	c.JSON(http.StatusOK, response)
 }
+
+func (h *KnowledgeBaseHandler) ListDeletedKnowledgeBase(c *gin.Context) {
+	knowledgeBases, err := h.service.ListDeletedKnowledgeBases()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	response := errors.NewResponseData(http.StatusOK, "Deleted Knowledge Base fetched successfully", knowledgeBases)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *KnowledgeBaseHandler) RestoreKnowledgeBase(c *gin.Context) {
+	var request RequestWithID
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if err := h.service.RestoreKnowledgeBase(request.ID); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+	recordAudit(c, h.audit, models.AuditActionRestore, "tb_knowledge_bases", request.ID, nil, h.snapshot(request.ID), nil)
+
+	response := errors.NewResponseData(http.StatusOK, "Knowledge Base restored successfully", nil)
+	c.JSON(http.StatusOK, response)
+}
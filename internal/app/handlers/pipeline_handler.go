@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+
+	"elible/internal/app/services"
+	errors "elible/internal/pkg"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PipelineHandler struct {
+	service *services.PipelineService
+}
+
+func NewPipelineHandler(service *services.PipelineService) *PipelineHandler {
+	return &PipelineHandler{
+		service: service,
+	}
+}
+
+func (h *PipelineHandler) GetPipeline(c *gin.Context) {
+	stages, err := h.service.GetPipeline()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	response := errors.NewResponseData(http.StatusOK, "Pipeline fetched successfully", stages)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *PipelineHandler) UpdatePipeline(c *gin.Context) {
+	var request UpdatePipelineRequest
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if err := h.service.UpdatePipeline(request.Stages); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	response := errors.NewResponseData(http.StatusOK, "Pipeline updated successfully", request.Stages)
+	c.JSON(http.StatusOK, response)
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path"
 
+	"elible/internal/app/middleware"
 	"elible/internal/app/models"
 	"elible/internal/app/services"
 	errors "elible/internal/pkg"
@@ -15,14 +16,22 @@ import (
 
 type StudyProgramHandler struct {
 	service *services.StudyProgramService
+	audit   *services.AuditService
 }
 
-func NewStudyProgramHandler(service *services.StudyProgramService) *StudyProgramHandler {
+func NewStudyProgramHandler(service *services.StudyProgramService, audit *services.AuditService) *StudyProgramHandler {
 	return &StudyProgramHandler{
 		service: service,
+		audit:   audit,
 	}
 }
 
+// snapshot loads a study program for the audit log, ignoring lookup errors.
+func (h *StudyProgramHandler) snapshot(id string) *models.StudyProgram {
+	sp, _ := h.service.FindStudyProgram(id)
+	return sp
+}
+
 func (h *StudyProgramHandler) CreateStudyProgram(c *gin.Context) {
 
 	var request AddProgramRequest
@@ -37,6 +46,7 @@ func (h *StudyProgramHandler) CreateStudyProgram(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
 		return
 	}
+	recordAudit(c, h.audit, models.AuditActionCreate, "tb_study_programs", spID.Hex(), nil, h.snapshot(spID.Hex()), gin.H{"kb_year": request.KbYear, "kp_name": request.KpName})
 
 	response := errors.NewResponseData(http.StatusOK, "Study Program created successfully", gin.H{"id": spID})
 	c.JSON(http.StatusOK, response)
@@ -50,10 +60,12 @@ func (h *StudyProgramHandler) UpdateStudyProgram(c *gin.Context) {
 		return
 	}
 
+	before := h.snapshot(request.ID)
 	if err := h.service.UpdateStudyProgram(request.ID, request.StudyProgram); err != nil {
-		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
+		errors.WriteStoreError(c, err, "Study Program not found")
 		return
 	}
+	recordAudit(c, h.audit, models.AuditActionUpdate, "tb_study_programs", request.ID, before, h.snapshot(request.ID), nil)
 
 	response := errors.NewResponseData(http.StatusOK, "Study Program updated successfully", request.StudyProgram)
 	c.JSON(http.StatusOK, response)
@@ -66,10 +78,12 @@ func (h *StudyProgramHandler) DeleteStudyProgram(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.DeleteStudyProgram(request.ID); err != nil {
-		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
+	before := h.snapshot(request.ID)
+	if err := h.service.DeleteStudyProgram(request.ID, middleware.CurrentActor(c).ID); err != nil {
+		errors.WriteStoreError(c, err, "Study Program not found")
 		return
 	}
+	recordAudit(c, h.audit, models.AuditActionDelete, "tb_study_programs", request.ID, before, nil, nil)
 
 	response := errors.NewResponseData(http.StatusOK, "Study Program deleted successfully", gin.H{"id": request.ID})
 	c.JSON(http.StatusOK, response)
@@ -143,8 +157,42 @@ func (h *StudyProgramHandler) UploadAndImportData(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
 		return
 	}
+	recordAudit(c, h.audit, models.AuditActionImport, "tb_study_programs", "", nil, nil, gin.H{
+		"file":    file.Filename,
+		"kb_year": knowledgeBaseYear,
+		"kp_name": knowledgeProgramName,
+		"result":  stat,
+	})
 
 	// Respond to the client
 	response := errors.NewResponseData(http.StatusOK, "Data imported successfully", stat)
 	c.JSON(http.StatusOK, response)
 }
+
+func (h *StudyProgramHandler) GetDeletedStudyPrograms(c *gin.Context) {
+	programs, err := h.service.GetDeletedStudyPrograms()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	response := errors.NewResponseData(http.StatusOK, "Fetched deleted study programs successfully", programs)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *StudyProgramHandler) RestoreStudyProgram(c *gin.Context) {
+	var request RequestWithID
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if err := h.service.RestoreStudyProgram(request.ID); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+	recordAudit(c, h.audit, models.AuditActionRestore, "tb_study_programs", request.ID, nil, h.snapshot(request.ID), nil)
+
+	response := errors.NewResponseData(http.StatusOK, "Study Program restored successfully", gin.H{"id": request.ID})
+	c.JSON(http.StatusOK, response)
+}
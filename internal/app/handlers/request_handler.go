@@ -64,3 +64,147 @@ type UpdateUniversityRequest struct {
 	ID         string            `json:"id" binding:"required"`
 	University models.University `json:"university"`
 }
+
+// RegisterAdminRequest holds what a caller may set on a new admin, everything
+// else is decided by the server.
+type RegisterAdminRequest struct {
+	Username string      `json:"username" binding:"required"`
+	Password string      `json:"password" binding:"required"`
+	Role     models.Role `json:"role"`
+	Email    string      `json:"email"`
+	FullName string      `json:"fullName"`
+}
+
+type AssignRoleRequest struct {
+	ID   string `json:"id" binding:"required"`
+	Role string `json:"role" binding:"required"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type LoginHistoryRequest struct {
+	Limit int64 `json:"limit"`
+}
+
+type UpdateAdminRequest struct {
+	ID    string              `json:"id" binding:"required"`
+	Admin models.AdminProfile `json:"admin"`
+}
+
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type VerifyLoginRequest struct {
+	MFAToken     string `json:"mfa_token" binding:"required"`
+	Code         string `json:"code" binding:"required_without=RecoveryCode"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+type TOTPCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type DisableTOTPRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+type UnlockRequest struct {
+	Username  string `json:"username" binding:"required_without=IPAddress"`
+	IPAddress string `json:"ip_address"`
+}
+
+type MagicLinkRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type MagicLinkVerifyRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type UserLoginRequest struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+type SetPasswordRequest struct {
+	OldPassword string `json:"old_password"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+type CreateAPIKeyRequest struct {
+	Name   string              `json:"name" binding:"required"`
+	Scopes []models.Permission `json:"scopes" binding:"required"`
+}
+
+type UpdatePipelineRequest struct {
+	Stages []models.PipelineStage `json:"stages" binding:"required,dive"`
+}
+
+type UpdateServiceRequest struct {
+	ID             string             `json:"id" binding:"required"`
+	OldServiceName string             `json:"old_service_name" binding:"required"`
+	Service        models.TrackRecord `json:"service" binding:"required"`
+}
+
+type DeleteServiceRequest struct {
+	ID          string `json:"id" binding:"required"`
+	ServiceName string `json:"service_name" binding:"required"`
+}
+
+type VoidInvoiceRequest struct {
+	ID     string `json:"id" binding:"required"`
+	Reason string `json:"reason" binding:"required"`
+}
+
+type UpdateSchoolRequest struct {
+	ID     string        `json:"id" binding:"required"`
+	School models.School `json:"school" binding:"required"`
+}
+
+type SchoolStudentsRequest struct {
+	ID string `json:"id" binding:"required"`
+	models.StudentFilter
+}
+
+type SchoolDuplicatesRequest struct {
+	MinSimilarity float64 `json:"min_similarity"`
+}
+
+type MergeRequest struct {
+	WinnerID string   `json:"winner_id" binding:"required"`
+	LoserIDs []string `json:"loser_ids" binding:"required"`
+}
+
+type DuplicateStudentsRequest struct {
+	MinNameSimilarity float64 `json:"min_name_similarity"`
+	Limit             int     `json:"limit"`
+}
+
+type CompareStudentsRequest struct {
+	FirstID  string `json:"first_id" binding:"required"`
+	SecondID string `json:"second_id" binding:"required"`
+}
+
+type RevertChangeRequest struct {
+	ID     string   `json:"id" binding:"required"`
+	Fields []string `json:"fields"`
+}
+
+type AssignCounselorRequest struct {
+	IDs         []string `json:"ids" binding:"required"`
+	CounselorID string   `json:"counselor_id" binding:"required"`
+}
@@ -4,6 +4,7 @@ package handlers
 import (
 	internal "elible/internal/app"
 	"elible/internal/app/middleware"
+	"elible/internal/app/models"
 	"elible/internal/app/services"
 	"elible/internal/config"
 
@@ -18,78 +19,227 @@ type RoutesHandler struct {
 	knowledgeBaseHandler *KnowledgeBaseHandler
 }
 
-func NewRoutesHandler(adminService *services.AdminService, studentService *services.StudentService, universityService *services.UniversityService, studyProgramService *services.StudyProgramService, knowledgeBaseService *services.KnowledgeBaseService) *RoutesHandler {
+func NewRoutesHandler(adminService *services.AdminService, studentService *services.StudentService, universityService *services.UniversityService, studyProgramService *services.StudyProgramService, knowledgeBaseService *services.KnowledgeBaseService, auditService *services.AuditService, historyService *services.HistoryService) *RoutesHandler {
 	return &RoutesHandler{
 		adminHandler:         NewAdminHandler(adminService),
-		studentHandler:       NewStudentHandler(studentService),
-		universityHandler:    NewUniversityHandler(universityService),
-		studyProgramHandler:  NewStudyProgramHandler(studyProgramService),
-		knowledgeBaseHandler: NewKnowledgeBaseHandler(knowledgeBaseService),
+		studentHandler:       NewStudentHandler(studentService, auditService, historyService),
+		universityHandler:    NewUniversityHandler(universityService, auditService),
+		studyProgramHandler:  NewStudyProgramHandler(studyProgramService, auditService),
+		knowledgeBaseHandler: NewKnowledgeBaseHandler(knowledgeBaseService, auditService),
 	}
 }
 
 func Routes(router *gin.Engine, cfg *config.Config, deps *internal.Dependencies) {
 	adminHandler := NewAdminHandler(deps.AdminService)
-	studentHandler := NewStudentHandler(deps.StudentService)
-	universityHandler := NewUniversityHandler(deps.UniversityService)
-	studyProgramHandler := NewStudyProgramHandler(deps.StudyProgramService)
-	knowledgeBaseHandler := NewKnowledgeBaseHandler(deps.KnowledgeBaseService)
+	studentHandler := NewStudentHandler(deps.StudentService, deps.AuditService, deps.HistoryService)
+	universityHandler := NewUniversityHandler(deps.UniversityService, deps.AuditService)
+	studyProgramHandler := NewStudyProgramHandler(deps.StudyProgramService, deps.AuditService)
+	knowledgeBaseHandler := NewKnowledgeBaseHandler(deps.KnowledgeBaseService, deps.AuditService)
+	userHandler := NewUserHandler(deps.UserService, deps.HistoryService)
+	apiKeyHandler := NewAPIKeyHandler(deps.APIKeyService)
+	auditHandler := NewAuditHandler(deps.AuditService)
+	pipelineHandler := NewPipelineHandler(deps.PipelineService)
+	invoiceHandler := NewInvoiceHandler(deps.InvoiceService, deps.AuditService)
+	analyticsHandler := NewAnalyticsHandler(deps.AnalyticsService)
+	schoolHandler := NewSchoolHandler(deps.SchoolService, deps.AuditService)
+	taskHandler := NewTaskHandler(deps.TaskService, deps.AuditService)
+	interactionHandler := NewInteractionHandler(deps.InteractionService, deps.AuditService)
+
+	// protected authenticates the admin against tb_tokens and checks the role's permission matrix
+	protected := func(permission models.Permission, next gin.HandlerFunc) gin.HandlerFunc {
+		return middleware.AdminMiddleware(cfg, deps.AdminService, false, true, middleware.RequirePermission(permission, next))
+	}
+
+	// integration additionally accepts API keys that carry the permission as a scope.
+	// Only routes that integrations need use it; deleting, trash and bulk imports stay admin only.
+	integration := func(permission models.Permission, next gin.HandlerFunc) gin.HandlerFunc {
+		return middleware.APIKeyMiddleware(cfg, deps.AdminService, deps.APIKeyService, permission, next)
+	}
 
 	adminGroup := router.Group("/admin")
 	{
-		adminGroup.POST("/create", middleware.AdminMiddleware(cfg, deps.AdminService, true, false, adminHandler.RegisterAdmin))
+		adminGroup.POST("/create", middleware.AdminMiddleware(cfg, deps.AdminService, true, true, middleware.RequirePermission(models.PermissionAdminManage, adminHandler.RegisterAdmin)))
 		adminGroup.POST("/login", adminHandler.LoginAdmin)
+		adminGroup.POST("/login/verify", adminHandler.VerifyLogin)
+		adminGroup.POST("/refresh", adminHandler.RefreshToken)
+		adminGroup.POST("/forgot-password", adminHandler.ForgotPassword)
+		adminGroup.POST("/reset-password", adminHandler.ResetPassword)
+		adminGroup.POST("/logout", middleware.AdminMiddleware(cfg, deps.AdminService, false, true, adminHandler.Logout))
+		adminGroup.POST("/logout-all", middleware.AdminMiddleware(cfg, deps.AdminService, false, true, adminHandler.LogoutAll))
+		adminGroup.POST("/sessions", middleware.AdminMiddleware(cfg, deps.AdminService, false, true, adminHandler.ListSessions))
+		adminGroup.POST("/sessions/revoke", middleware.AdminMiddleware(cfg, deps.AdminService, false, true, adminHandler.RevokeSession))
+		adminGroup.POST("/login-history", middleware.AdminMiddleware(cfg, deps.AdminService, false, true, adminHandler.LoginHistory))
 		adminGroup.POST("/profil", middleware.AdminMiddleware(cfg, deps.AdminService, false, true, adminHandler.GetProfileByToken))
+		adminGroup.POST("/profil/update", middleware.AdminMiddleware(cfg, deps.AdminService, false, true, adminHandler.UpdateOwnProfile))
+		adminGroup.POST("/change-password", middleware.AdminMiddleware(cfg, deps.AdminService, false, true, adminHandler.ChangePassword))
+		adminGroup.POST("/2fa/setup", middleware.AdminMiddleware(cfg, deps.AdminService, false, true, adminHandler.SetupTOTP))
+		adminGroup.POST("/2fa/confirm", middleware.AdminMiddleware(cfg, deps.AdminService, false, true, adminHandler.ConfirmTOTP))
+		adminGroup.POST("/2fa/recovery-codes", middleware.AdminMiddleware(cfg, deps.AdminService, false, true, adminHandler.RegenerateRecoveryCodes))
+		adminGroup.POST("/2fa/disable", middleware.AdminMiddleware(cfg, deps.AdminService, false, true, adminHandler.DisableTOTP))
+		adminGroup.POST("/all", protected(models.PermissionAdminManage, adminHandler.ListAdmins))
+		adminGroup.POST("/update", protected(models.PermissionAdminManage, adminHandler.UpdateAdmin))
+		adminGroup.POST("/disable", protected(models.PermissionAdminManage, adminHandler.DisableAdmin))
+		adminGroup.POST("/enable", protected(models.PermissionAdminManage, adminHandler.EnableAdmin))
+		adminGroup.POST("/delete", protected(models.PermissionAdminManage, adminHandler.DeleteAdmin))
+		adminGroup.POST("/lockouts", protected(models.PermissionAdminManage, adminHandler.ListLockouts))
+		adminGroup.POST("/unlock", protected(models.PermissionAdminManage, adminHandler.UnlockAdmin))
+		adminGroup.POST("/roles", protected(models.PermissionAdminManage, adminHandler.ListRoles))
+		adminGroup.POST("/assign-role", protected(models.PermissionAdminManage, adminHandler.AssignRole))
 	}
 
 	studentGroup := router.Group("/student")
 	{
-		studentGroup.POST("/create", middleware.AdminMiddleware(cfg, deps.AdminService, false, true, studentHandler.RegisterStudent))
-		studentGroup.POST("/all", middleware.AdminMiddleware(cfg, deps.AdminService, false, true, studentHandler.GetAllStudents))
-		studentGroup.POST("/id", middleware.AdminMiddleware(cfg, deps.AdminService, false, true, studentHandler.GetIdStudents))
-		studentGroup.POST("/delete", middleware.AdminMiddleware(cfg, deps.AdminService, false, true, studentHandler.DeleteStudent))
-		studentGroup.POST("/deactivate", middleware.AdminMiddleware(cfg, deps.AdminService, false, true, studentHandler.DeactivateStudent))
-		studentGroup.POST("/update", middleware.AdminMiddleware(cfg, deps.AdminService, false, true, studentHandler.UpdateStudent))
-		studentGroup.POST("/add-service", middleware.AdminMiddleware(cfg, deps.AdminService, false, true, studentHandler.AddServiceToStudent))
-		studentGroup.POST("/add-lobby", middleware.AdminMiddleware(cfg, deps.AdminService, false, true, studentHandler.AddLobbyProgressToStudent))
-		studentGroup.POST("/upload", middleware.AdminMiddleware(cfg, deps.AdminService, false, true, studentHandler.uploadImage))
-		studentGroup.POST("/activated-all", middleware.AdminMiddleware(cfg, deps.AdminService, false, true, studentHandler.ActivateStudnetAll))
-		studentGroup.POST("/upload-excel", middleware.AdminMiddleware(cfg, deps.AdminService, false, true, studentHandler.UploadAndImportDataStudent))
+		studentGroup.POST("/create", integration(models.PermissionStudentWrite, studentHandler.RegisterStudent))
+		studentGroup.POST("/all", integration(models.PermissionStudentRead, studentHandler.GetAllStudents))
+		studentGroup.POST("/id", integration(models.PermissionStudentRead, studentHandler.GetIdStudents))
+		studentGroup.POST("/delete", protected(models.PermissionStudentDelete, studentHandler.DeleteStudent))
+		studentGroup.POST("/trash", protected(models.PermissionStudentDelete, studentHandler.GetDeletedStudents))
+		studentGroup.POST("/restore", protected(models.PermissionStudentDelete, studentHandler.RestoreStudent))
+		studentGroup.POST("/deactivate", protected(models.PermissionStudentWrite, studentHandler.DeactivateStudent))
+		studentGroup.POST("/update", integration(models.PermissionStudentWrite, studentHandler.UpdateStudent))
+		studentGroup.POST("/add-service", integration(models.PermissionStudentWrite, studentHandler.AddServiceToStudent))
+		studentGroup.POST("/update-service", integration(models.PermissionStudentWrite, studentHandler.UpdateServiceOfStudent))
+		studentGroup.POST("/delete-service", protected(models.PermissionStudentWrite, studentHandler.DeleteServiceFromStudent))
+		studentGroup.POST("/services", integration(models.PermissionStudentRead, studentHandler.SearchServices))
+		studentGroup.POST("/history", integration(models.PermissionStudentRead, studentHandler.GetStudentHistory))
+		studentGroup.POST("/history/revert", protected(models.PermissionStudentWrite, studentHandler.RevertStudentChange))
+		studentGroup.POST("/duplicates", protected(models.PermissionStudentRead, studentHandler.FindDuplicateStudents))
+		studentGroup.POST("/compare", protected(models.PermissionStudentRead, studentHandler.CompareStudents))
+		studentGroup.POST("/merge", protected(models.PermissionStudentDelete, studentHandler.MergeStudents))
+		studentGroup.POST("/assign", protected(models.PermissionStudentAssign, studentHandler.AssignCounselor))
+		studentGroup.POST("/caseload", protected(models.PermissionStudentAssign, studentHandler.GetCaseloads))
+		studentGroup.POST("/add-lobby", integration(models.PermissionStudentWrite, studentHandler.AddLobbyProgressToStudent))
+		studentGroup.POST("/upload", integration(models.PermissionStudentWrite, studentHandler.uploadImage))
+		studentGroup.POST("/activated-all", protected(models.PermissionStudentWrite, studentHandler.ActivateStudnetAll))
+		studentGroup.POST("/upload-excel", protected(models.PermissionStudentImport, studentHandler.UploadAndImportDataStudent))
 	}
 
 	universityGroup := router.Group("/university")
 	{
-		universityGroup.POST("/create", middleware.AdminMiddleware(cfg, deps.AdminService, false, true, universityHandler.CreateUniversity))
-		universityGroup.POST("/update", middleware.AdminMiddleware(cfg, deps.AdminService, false, true, universityHandler.UpdateUniversity))
-		universityGroup.POST("/delete", middleware.AdminMiddleware(cfg, deps.AdminService, false, true, universityHandler.DeleteUniversity))
-		universityGroup.POST("/id", middleware.AdminMiddleware(cfg, deps.AdminService, false, true, universityHandler.GetUniversity))
-		universityGroup.POST("/all", middleware.AdminMiddleware(cfg, deps.AdminService, false, true, universityHandler.GetUniversities))
+		universityGroup.POST("/create", integration(models.PermissionCatalogWrite, universityHandler.CreateUniversity))
+		universityGroup.POST("/update", integration(models.PermissionCatalogWrite, universityHandler.UpdateUniversity))
+		universityGroup.POST("/delete", protected(models.PermissionCatalogDelete, universityHandler.DeleteUniversity))
+		universityGroup.POST("/trash", protected(models.PermissionCatalogDelete, universityHandler.GetDeletedUniversities))
+		universityGroup.POST("/restore", protected(models.PermissionCatalogDelete, universityHandler.RestoreUniversity))
+		universityGroup.POST("/id", integration(models.PermissionCatalogRead, universityHandler.GetUniversity))
+		universityGroup.POST("/all", integration(models.PermissionCatalogRead, universityHandler.GetUniversities))
 	}
 
 	studyProgramGroup := router.Group("/study-program")
 	{
-		studyProgramGroup.POST("/create", middleware.AdminMiddleware(cfg, deps.AdminService, false, true, studyProgramHandler.CreateStudyProgram))
-		studyProgramGroup.POST("/update", middleware.AdminMiddleware(cfg, deps.AdminService, false, true, studyProgramHandler.UpdateStudyProgram))
-		studyProgramGroup.POST("/delete", middleware.AdminMiddleware(cfg, deps.AdminService, false, true, studyProgramHandler.DeleteStudyProgram))
-		studyProgramGroup.POST("/id", middleware.AdminMiddleware(cfg, deps.AdminService, false, true, studyProgramHandler.GetStudyProgram))
-		studyProgramGroup.POST("/all", middleware.AdminMiddleware(cfg, deps.AdminService, false, true, studyProgramHandler.GetStudyPrograms))
-		studyProgramGroup.POST("/upload", middleware.AdminMiddleware(cfg, deps.AdminService, false, true, studyProgramHandler.UploadAndImportData))
+		studyProgramGroup.POST("/create", integration(models.PermissionCatalogWrite, studyProgramHandler.CreateStudyProgram))
+		studyProgramGroup.POST("/update", integration(models.PermissionCatalogWrite, studyProgramHandler.UpdateStudyProgram))
+		studyProgramGroup.POST("/delete", protected(models.PermissionCatalogDelete, studyProgramHandler.DeleteStudyProgram))
+		studyProgramGroup.POST("/trash", protected(models.PermissionCatalogDelete, studyProgramHandler.GetDeletedStudyPrograms))
+		studyProgramGroup.POST("/restore", protected(models.PermissionCatalogDelete, studyProgramHandler.RestoreStudyProgram))
+		studyProgramGroup.POST("/id", integration(models.PermissionCatalogRead, studyProgramHandler.GetStudyProgram))
+		studyProgramGroup.POST("/all", integration(models.PermissionCatalogRead, studyProgramHandler.GetStudyPrograms))
+		studyProgramGroup.POST("/upload", protected(models.PermissionCatalogImport, studyProgramHandler.UploadAndImportData))
+	}
+
+	schoolGroup := router.Group("/school")
+	{
+		schoolGroup.POST("/create", integration(models.PermissionCatalogWrite, schoolHandler.CreateSchool))
+		schoolGroup.POST("/update", integration(models.PermissionCatalogWrite, schoolHandler.UpdateSchool))
+		schoolGroup.POST("/delete", protected(models.PermissionCatalogDelete, schoolHandler.DeleteSchool))
+		schoolGroup.POST("/id", integration(models.PermissionCatalogRead, schoolHandler.GetSchool))
+		schoolGroup.POST("/all", integration(models.PermissionCatalogRead, schoolHandler.GetSchools))
+		schoolGroup.POST("/students", integration(models.PermissionStudentRead, schoolHandler.GetSchoolStudents))
+		schoolGroup.POST("/upload", integration(models.PermissionCatalogWrite, schoolHandler.UploadImage))
+		schoolGroup.POST("/normalize", protected(models.PermissionCatalogWrite, schoolHandler.NormalizeSchools))
+		schoolGroup.POST("/duplicates", protected(models.PermissionCatalogRead, schoolHandler.GetDuplicateSchools))
+		schoolGroup.POST("/merge", protected(models.PermissionCatalogDelete, schoolHandler.MergeSchools))
 	}
 
 	knowledgeBaseGroup := router.Group("/knowledge-base")
 	{
-		knowledgeBaseGroup.POST("/create", middleware.AdminMiddleware(cfg, deps.AdminService, false, true, knowledgeBaseHandler.CreateKnowledgeBase))
-		knowledgeBaseGroup.POST("/update", middleware.AdminMiddleware(cfg, deps.AdminService, false, true, knowledgeBaseHandler.UpdateKnowledgeBase))
-		knowledgeBaseGroup.POST("/delete", middleware.AdminMiddleware(cfg, deps.AdminService, false, true, knowledgeBaseHandler.DeleteKnowledgeBase))
-		knowledgeBaseGroup.POST("/all", middleware.AdminMiddleware(cfg, deps.AdminService, false, true, knowledgeBaseHandler.ListKnowledgeBase))
+		knowledgeBaseGroup.POST("/create", integration(models.PermissionCatalogWrite, knowledgeBaseHandler.CreateKnowledgeBase))
+		knowledgeBaseGroup.POST("/update", integration(models.PermissionCatalogWrite, knowledgeBaseHandler.UpdateKnowledgeBase))
+		knowledgeBaseGroup.POST("/delete", protected(models.PermissionCatalogDelete, knowledgeBaseHandler.DeleteKnowledgeBase))
+		knowledgeBaseGroup.POST("/trash", protected(models.PermissionCatalogDelete, knowledgeBaseHandler.ListDeletedKnowledgeBase))
+		knowledgeBaseGroup.POST("/restore", protected(models.PermissionCatalogDelete, knowledgeBaseHandler.RestoreKnowledgeBase))
+		knowledgeBaseGroup.POST("/all", integration(models.PermissionCatalogRead, knowledgeBaseHandler.ListKnowledgeBase))
 	}
 
 	knowledgeProgramsGroup := router.Group("/knowledge-programs")
 	{
-		knowledgeProgramsGroup.POST("/add", middleware.AdminMiddleware(cfg, deps.AdminService, false, true, knowledgeBaseHandler.AddKnowledgeProgram))
-		knowledgeProgramsGroup.POST("/update", middleware.AdminMiddleware(cfg, deps.AdminService, false, true, knowledgeBaseHandler.UpdateKnowledgeProgram))
-		knowledgeProgramsGroup.POST("/delete", middleware.AdminMiddleware(cfg, deps.AdminService, false, true, knowledgeBaseHandler.RemoveKnowledgeProgram))
-		knowledgeProgramsGroup.POST("/get", middleware.AdminMiddleware(cfg, deps.AdminService, false, true, knowledgeBaseHandler.ListKnowledgePrograms))
+		knowledgeProgramsGroup.POST("/add", integration(models.PermissionCatalogWrite, knowledgeBaseHandler.AddKnowledgeProgram))
+		knowledgeProgramsGroup.POST("/update", integration(models.PermissionCatalogWrite, knowledgeBaseHandler.UpdateKnowledgeProgram))
+		knowledgeProgramsGroup.POST("/delete", protected(models.PermissionCatalogDelete, knowledgeBaseHandler.RemoveKnowledgeProgram))
+		knowledgeProgramsGroup.POST("/get", integration(models.PermissionCatalogRead, knowledgeBaseHandler.ListKnowledgePrograms))
+	}
+
+	apiKeyGroup := router.Group("/api-key")
+	{
+		apiKeyGroup.POST("/create", protected(models.PermissionAPIKeyManage, apiKeyHandler.CreateAPIKey))
+		apiKeyGroup.POST("/all", protected(models.PermissionAPIKeyManage, apiKeyHandler.ListAPIKeys))
+		apiKeyGroup.POST("/revoke", protected(models.PermissionAPIKeyManage, apiKeyHandler.RevokeAPIKey))
+	}
+
+	pipelineGroup := router.Group("/pipeline")
+	{
+		pipelineGroup.POST("/get", integration(models.PermissionStudentRead, pipelineHandler.GetPipeline))
+		pipelineGroup.POST("/update", protected(models.PermissionAdminManage, pipelineHandler.UpdatePipeline))
+	}
+
+	taskGroup := router.Group("/task")
+	{
+		taskGroup.POST("/create", integration(models.PermissionStudentWrite, taskHandler.CreateTask))
+		taskGroup.POST("/all", integration(models.PermissionStudentRead, taskHandler.GetTasks))
+		taskGroup.POST("/overdue", integration(models.PermissionStudentRead, taskHandler.GetOverdueTasks))
+		taskGroup.POST("/update", integration(models.PermissionStudentWrite, taskHandler.UpdateTask))
+		taskGroup.POST("/complete", integration(models.PermissionStudentWrite, taskHandler.CompleteTask))
+	}
+
+	interactionGroup := router.Group("/interaction")
+	{
+		interactionGroup.POST("/create", integration(models.PermissionStudentWrite, interactionHandler.CreateInteraction))
+		interactionGroup.POST("/search", integration(models.PermissionStudentRead, interactionHandler.SearchInteractions))
+		interactionGroup.POST("/delete", protected(models.PermissionStudentDelete, interactionHandler.DeleteInteraction))
+		interactionGroup.POST("/upload", integration(models.PermissionStudentWrite, interactionHandler.UploadAttachment))
+	}
+
+	invoiceGroup := router.Group("/invoice")
+	{
+		invoiceGroup.POST("/create", protected(models.PermissionBillingWrite, invoiceHandler.CreateInvoice))
+		invoiceGroup.POST("/all", protected(models.PermissionBillingRead, invoiceHandler.GetInvoices))
+		invoiceGroup.POST("/id", protected(models.PermissionBillingRead, invoiceHandler.GetInvoice))
+		invoiceGroup.POST("/update", protected(models.PermissionBillingWrite, invoiceHandler.UpdateInvoice))
+		invoiceGroup.POST("/pay", protected(models.PermissionBillingWrite, invoiceHandler.RecordPayment))
+		invoiceGroup.POST("/void", protected(models.PermissionBillingWrite, invoiceHandler.VoidInvoice))
+		invoiceGroup.POST("/receipt", protected(models.PermissionBillingRead, invoiceHandler.DownloadReceipt))
+	}
+
+	analyticsGroup := router.Group("/analytics")
+	{
+		analyticsGroup.POST("/revenue/service", protected(models.PermissionAnalyticsRead, analyticsHandler.Revenue(models.RevenueByService)))
+		analyticsGroup.POST("/revenue/month", protected(models.PermissionAnalyticsRead, analyticsHandler.Revenue(models.RevenueByMonth)))
+		analyticsGroup.POST("/revenue/school", protected(models.PermissionAnalyticsRead, analyticsHandler.Revenue(models.RevenueBySchool)))
+		analyticsGroup.POST("/revenue/counselor", protected(models.PermissionAnalyticsRead, analyticsHandler.Revenue(models.RevenueByCounselor)))
+		analyticsGroup.POST("/status", protected(models.PermissionAnalyticsRead, analyticsHandler.CountByStatus))
+		analyticsGroup.POST("/outstanding", protected(models.PermissionAnalyticsRead, analyticsHandler.Outstanding))
+		analyticsGroup.POST("/funnel", protected(models.PermissionAnalyticsRead, analyticsHandler.Funnel))
+		analyticsGroup.POST("/drop-off/school", protected(models.PermissionAnalyticsRead, analyticsHandler.DropOff(models.DropOffBySchool)))
+		analyticsGroup.POST("/drop-off/province", protected(models.PermissionAnalyticsRead, analyticsHandler.DropOff(models.DropOffByProvince)))
+		analyticsGroup.POST("/drop-off/interest", protected(models.PermissionAnalyticsRead, analyticsHandler.DropOff(models.DropOffByInterest)))
+		analyticsGroup.POST("/cohorts", protected(models.PermissionAnalyticsRead, analyticsHandler.Cohorts))
+	}
+
+	auditGroup := router.Group("/audit")
+	{
+		auditGroup.POST("/list", protected(models.PermissionAuditRead, auditHandler.ListAuditLogs))
+	}
+
+	// The student portal uses its own tokens and never accepts admin credentials
+	portalGroup := router.Group("/portal")
+	{
+		portalGroup.POST("/magic-link", userHandler.RequestMagicLink)
+		portalGroup.POST("/magic-link/verify", userHandler.VerifyMagicLink)
+		portalGroup.POST("/login", userHandler.Login)
+		portalGroup.POST("/logout", middleware.StudentMiddleware(deps.UserService, userHandler.Logout))
+		portalGroup.POST("/set-password", middleware.StudentMiddleware(deps.UserService, userHandler.SetPassword))
+		portalGroup.POST("/me", middleware.StudentMiddleware(deps.UserService, userHandler.GetMe))
+		portalGroup.POST("/me/update", middleware.StudentMiddleware(deps.UserService, userHandler.UpdateMe))
 	}
 
 }
@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"net/http"
+
+	"elible/internal/app/models"
+	"elible/internal/app/services"
+	errors "elible/internal/pkg"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SchoolHandler struct {
+	service *services.SchoolService
+	audit   *services.AuditService
+}
+
+func NewSchoolHandler(service *services.SchoolService, audit *services.AuditService) *SchoolHandler {
+	return &SchoolHandler{
+		service: service,
+		audit:   audit,
+	}
+}
+
+// snapshot loads a school for the audit log, ignoring lookup errors.
+func (h *SchoolHandler) snapshot(schoolID string) *models.School {
+	school, _ := h.service.GetByID(schoolID)
+	return school
+}
+
+func (h *SchoolHandler) CreateSchool(c *gin.Context) {
+	var school models.School
+	if err := c.ShouldBind(&school); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if err := h.service.Create(&school); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+	recordAudit(c, h.audit, models.AuditActionCreate, "tb_schools", school.ID.Hex(), nil, &school, nil)
+
+	response := errors.NewResponseData(http.StatusCreated, "School created successfully", school)
+	c.JSON(http.StatusCreated, response)
+}
+
+func (h *SchoolHandler) GetSchools(c *gin.Context) {
+	var filter models.SchoolFilter
+	if err := c.ShouldBind(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	schools, err := h.service.List(&filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	response := errors.NewResponseData(http.StatusOK, "Schools fetched successfully", schools)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *SchoolHandler) GetSchool(c *gin.Context) {
+	var request RequestWithID
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	school, err := h.service.GetByID(request.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
+		return
+	}
+	if school == nil {
+		c.JSON(http.StatusNotFound, errors.NewResponseError(http.StatusNotFound, "School not found"))
+		return
+	}
+
+	response := errors.NewResponseData(http.StatusOK, "School fetched successfully", school)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *SchoolHandler) UpdateSchool(c *gin.Context) {
+	var request UpdateSchoolRequest
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	before := h.snapshot(request.ID)
+	if err := h.service.Update(request.ID, &request.School); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+	after := h.snapshot(request.ID)
+	recordAudit(c, h.audit, models.AuditActionUpdate, "tb_schools", request.ID, before, after, nil)
+
+	response := errors.NewResponseData(http.StatusOK, "School updated successfully", after)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *SchoolHandler) DeleteSchool(c *gin.Context) {
+	var request RequestWithID
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	before := h.snapshot(request.ID)
+	if err := h.service.Delete(request.ID); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+	recordAudit(c, h.audit, models.AuditActionDelete, "tb_schools", request.ID, before, nil, nil)
+
+	response := errors.NewResponseData(http.StatusOK, "School deleted successfully", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *SchoolHandler) GetSchoolStudents(c *gin.Context) {
+	var request SchoolStudentsRequest
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	students, err := h.service.Students(request.ID, &request.StudentFilter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	response := errors.NewResponseData(http.StatusOK, "Students fetched successfully", students)
+	c.JSON(http.StatusOK, response)
+}
+
+// UploadImage stores a logo or image from the "image" form field and sets it on the school given in "id".
+func (h *SchoolHandler) UploadImage(c *gin.Context) {
+	file, err := c.FormFile("image")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, "File is not present in the form data"))
+		return
+	}
+
+	schoolID := c.PostForm("id")
+	before := h.snapshot(schoolID)
+	if before == nil {
+		c.JSON(http.StatusNotFound, errors.NewResponseError(http.StatusNotFound, "School not found"))
+		return
+	}
+
+	kind := c.PostForm("type")
+	if kind != services.SchoolImageLogo && kind != services.SchoolImagePhoto {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, "Invalid form type"))
+		return
+	}
+
+	fullPath, status, err := saveImage(c, file, "school")
+	if err != nil {
+		c.JSON(status, errors.NewResponseError(status, err.Error()))
+		return
+	}
+
+	if err := h.service.SetImage(schoolID, kind, fullPath); err != nil {
+		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
+		return
+	}
+	recordAudit(c, h.audit, models.AuditActionUpdate, "tb_schools", schoolID, before, h.snapshot(schoolID), nil)
+
+	response := errors.NewResponseData(http.StatusCreated, "Upload Image Success ", fullPath)
+	c.JSON(http.StatusCreated, response)
+}
+
+func (h *SchoolHandler) NormalizeSchools(c *gin.Context) {
+	updated, err := h.service.Normalize()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
+		return
+	}
+	recordAudit(c, h.audit, models.AuditActionUpdate, "tb_schools", "", nil, nil, gin.H{"normalized": updated})
+
+	response := errors.NewResponseData(http.StatusOK, "School names normalized successfully", gin.H{"updated": updated})
+	c.JSON(http.StatusOK, response)
+}
+
+// GetDuplicateSchools previews the schools that look like the same school and would be merged.
+func (h *SchoolHandler) GetDuplicateSchools(c *gin.Context) {
+	var request SchoolDuplicatesRequest
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	groups, err := h.service.FindDuplicates(request.MinSimilarity)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	response := errors.NewResponseData(http.StatusOK, "Duplicate schools fetched successfully", groups)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *SchoolHandler) MergeSchools(c *gin.Context) {
+	var request MergeRequest
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	before := h.snapshot(request.WinnerID)
+	losers := make([]*models.School, 0, len(request.LoserIDs))
+	for _, loserID := range request.LoserIDs {
+		losers = append(losers, h.snapshot(loserID))
+	}
+
+	result, err := h.service.Merge(request.WinnerID, request.LoserIDs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+	recordAudit(c, h.audit, models.AuditActionMerge, "tb_schools", request.WinnerID, before, h.snapshot(request.WinnerID), gin.H{
+		"merged":         losers,
+		"students_moved": result.StudentsMoved,
+	})
+
+	response := errors.NewResponseData(http.StatusOK, "Schools merged successfully", result)
+	c.JSON(http.StatusOK, response)
+}
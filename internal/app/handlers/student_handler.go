@@ -2,16 +2,13 @@
 package handlers
 
 import (
-	"fmt"
 	"net/http"
 	"os"
 	"path"
-	"path/filepath"
-	"time"
 
+	"elible/internal/app/middleware"
 	"elible/internal/app/models"
 	"elible/internal/app/services"
-	utils "elible/internal/app/utils"
 	errors "elible/internal/pkg"
 
 	"github.com/gin-gonic/gin"
@@ -20,14 +17,39 @@ import (
 
 type StudentHandler struct {
 	service *services.StudentService
+	audit   *services.AuditService
+	history *services.HistoryService
 }
 
-func NewStudentHandler(service *services.StudentService) *StudentHandler {
+func NewStudentHandler(service *services.StudentService, audit *services.AuditService, history *services.HistoryService) *StudentHandler {
 	return &StudentHandler{
 		service: service,
+		audit:   audit,
+		history: history,
 	}
 }
 
+// snapshot loads a student for the audit log, ignoring lookup errors.
+func (h *StudentHandler) snapshot(studentID string) *models.Student {
+	student, _ := h.service.GetByID(studentID)
+	return student
+}
+
+// recordChange writes the audit entry for an update to a student and appends
+// the changed fields to the student's history.
+func (h *StudentHandler) recordChange(c *gin.Context, action, studentID string, before *models.Student, details interface{}) {
+	after := h.snapshot(studentID)
+	recordAudit(c, h.audit, action, "tb_students", studentID, before, after, details)
+	h.history.Record(studentID, middleware.CurrentActor(c), action, c.FullPath(), before, after)
+}
+
+// recordHistory appends the changes of a bulk write to one student's history.
+// Bulk writes are audited once as a whole, not per student.
+func (h *StudentHandler) recordHistory(c *gin.Context, action string, before *models.Student) {
+	studentID := before.ID.Hex()
+	h.history.Record(studentID, middleware.CurrentActor(c), action, c.FullPath(), before, h.snapshot(studentID))
+}
+
 func (h *StudentHandler) RegisterStudent(c *gin.Context) {
 	var student models.Student
 	if err := c.ShouldBind(&student); err != nil {
@@ -39,6 +61,7 @@ func (h *StudentHandler) RegisterStudent(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
 		return
 	}
+	recordAudit(c, h.audit, models.AuditActionCreate, "tb_students", student.ID.Hex(), nil, &student, nil)
 
 	response := errors.NewResponseData(http.StatusCreated, "Student created successfully", student)
 	c.JSON(http.StatusCreated, response)
@@ -50,6 +73,17 @@ func (h *StudentHandler) GetAllStudents(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
 		return
 	}
+
+	// "My students" only makes sense for an admin, API keys have no caseload
+	if filter.Mine != nil && *filter.Mine {
+		actor := middleware.CurrentActor(c)
+		if actor.Type != models.ActorTypeAdmin {
+			c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, "mine can only be used by an admin"))
+			return
+		}
+		filter.CounselorID = &actor.ID
+	}
+
 	students, err := h.service.GetAll(&filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
@@ -67,13 +101,17 @@ func (h *StudentHandler) GetIdStudents(c *gin.Context) {
 		return
 	}
 
-	students, err := h.service.GetByID(request.ID)
+	student, err := h.service.GetDetail(request.ID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
 		return
 	}
+	if student == nil {
+		c.JSON(http.StatusNotFound, errors.NewResponseError(http.StatusNotFound, "Student not found"))
+		return
+	}
 
-	response := errors.NewResponseData(http.StatusOK, "Students fetched successfully", students)
+	response := errors.NewResponseData(http.StatusOK, "Students fetched successfully", student)
 	c.JSON(http.StatusOK, response)
 }
 
@@ -84,10 +122,12 @@ func (h *StudentHandler) DeleteStudent(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.Delete(request.ID); err != nil {
-		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
+	before := h.snapshot(request.ID)
+	if err := h.service.Delete(request.ID, middleware.CurrentActor(c).ID); err != nil {
+		errors.WriteStoreError(c, err, "Student not found")
 		return
 	}
+	recordAudit(c, h.audit, models.AuditActionDelete, "tb_students", request.ID, before, nil, nil)
 
 	response := errors.NewResponseData(http.StatusOK, "Student deleted successfully", nil)
 	c.JSON(http.StatusOK, response)
@@ -99,10 +139,12 @@ func (h *StudentHandler) DeactivateStudent(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
 		return
 	}
+	before := h.snapshot(request.ID)
 	if err := h.service.Deactivate(request.ID); err != nil {
-		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
+		errors.WriteStoreError(c, err, "Student not found")
 		return
 	}
+	h.recordChange(c, models.AuditActionUpdate, request.ID, before, nil)
 
 	response := errors.NewResponseData(http.StatusOK, "Student deactivated successfully", nil)
 	c.JSON(http.StatusOK, response)
@@ -122,10 +164,12 @@ func (h *StudentHandler) UpdateStudent(c *gin.Context) {
 		return
 	}
 
+	before := h.snapshot(objectId.Hex())
 	if err := h.service.Update(objectId.Hex(), &request.Student); err != nil {
-		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
+		errors.WriteStoreError(c, err, "Student not found")
 		return
 	}
+	h.recordChange(c, models.AuditActionUpdate, objectId.Hex(), before, nil)
 	response := errors.NewResponseData(http.StatusOK, "Student updated successfully", request.Student)
 	c.JSON(http.StatusOK, response)
 }
@@ -143,15 +187,75 @@ func (h *StudentHandler) AddServiceToStudent(c *gin.Context) {
 		return
 	}
 
+	// Credit the service to the admin who records it unless a counselor is given
+	if actor := middleware.CurrentActor(c); request.Service.CounselorID == "" && actor.Type == models.ActorTypeAdmin {
+		request.Service.CounselorID = actor.ID
+	}
+
+	before := h.snapshot(objectId.Hex())
 	if err := h.service.AddService(objectId.Hex(), &request.Service); err != nil {
 		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
 		return
 	}
+	h.recordChange(c, models.AuditActionUpdate, objectId.Hex(), before, nil)
 
 	response := errors.NewResponseData(http.StatusOK, "Service added to student successfully", request.Service)
 	c.JSON(http.StatusOK, response)
 }
 
+func (h *StudentHandler) UpdateServiceOfStudent(c *gin.Context) {
+	var request UpdateServiceRequest
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	before := h.snapshot(request.ID)
+	if err := h.service.UpdateService(request.ID, request.OldServiceName, &request.Service); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+	h.recordChange(c, models.AuditActionUpdate, request.ID, before, nil)
+
+	response := errors.NewResponseData(http.StatusOK, "Service updated successfully", request.Service)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *StudentHandler) DeleteServiceFromStudent(c *gin.Context) {
+	var request DeleteServiceRequest
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	before := h.snapshot(request.ID)
+	if err := h.service.DeleteService(request.ID, request.ServiceName); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+	h.recordChange(c, models.AuditActionUpdate, request.ID, before, nil)
+
+	response := errors.NewResponseData(http.StatusOK, "Service deleted successfully", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *StudentHandler) SearchServices(c *gin.Context) {
+	var filter models.ServiceFilter
+	if err := c.ShouldBind(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	students, err := h.service.FilterServices(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	response := errors.NewResponseData(http.StatusOK, "Services fetched successfully", students)
+	c.JSON(http.StatusOK, response)
+}
+
 func (h *StudentHandler) AddLobbyProgressToStudent(c *gin.Context) {
 	var request AddLobbyRequest
 	if err := c.ShouldBind(&request); err != nil {
@@ -165,10 +269,20 @@ func (h *StudentHandler) AddLobbyProgressToStudent(c *gin.Context) {
 		return
 	}
 
+	before := h.snapshot(objectId.Hex())
 	if err := h.service.AddLobby(objectId.Hex(), &request.Lobby); err != nil {
+		if _, ok := err.(*services.TransitionError); ok {
+			c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+			return
+		}
+		if err == services.ErrStageChanged {
+			c.JSON(http.StatusConflict, errors.NewResponseError(http.StatusConflict, err.Error()))
+			return
+		}
 		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
 		return
 	}
+	h.recordChange(c, models.AuditActionUpdate, objectId.Hex(), before, nil)
 
 	response := errors.NewResponseData(http.StatusOK, "Lobby Proggress added to student successfully", map[string]interface{}{
 		"Progress": request.Lobby.Progress,
@@ -183,69 +297,24 @@ func (h *StudentHandler) uploadImage(c *gin.Context) {
 		return
 	}
 
-	if valid := utils.IsImage(file); !valid {
-		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, "File is not an image"))
-		return
-	}
-
 	formType := c.PostForm("type")
 	if formType == "" {
 		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, "Form type is not set"))
 		return
 	}
 
-	dir := os.Getenv("IMAGE_DIR")
-	if dir == "" {
-		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, "IMAGE_DIR environment variable is not set"))
-		return
-	}
-
 	switch formType {
-	case "profile":
-		dir = path.Join(dir, "profile")
-	case "asset":
-		dir = path.Join(dir, "asset")
+	case "profile", "asset":
 	default:
 		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, "Invalid form type"))
 		return
 	}
 
-	if _, err := os.Stat(dir); os.IsNotExist(err) {
-		err = os.MkdirAll(dir, 0755)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
-			return
-		}
-	}
-
-	newFilename, err := utils.RandomString(10) // Generate a 10-character long random string
+	fullPath, status, err := saveImage(c, file, formType)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, "Failed to generate a random string for the filename"))
+		c.JSON(status, errors.NewResponseError(status, err.Error()))
 		return
 	}
-	newFilename = fmt.Sprintf("%s_%s", newFilename, time.Now().Format("20060102"))
-	ext := filepath.Ext(file.Filename)
-	newFilenameWithExt := newFilename + ext
-	newFilenameWithExtDomain := path.Join("images", formType, newFilenameWithExt)
-	dst := filepath.Join(dir, newFilenameWithExt)
-
-	if err := c.SaveUploadedFile(file, dst); err != nil {
-		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
-		return
-	}
-
-	if err := os.Chmod(dst, 0644); err != nil {
-		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
-		return
-	}
-
-	domain := os.Getenv("WEB_DOMAIN")
-	if domain == "" {
-		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, "WEB_DOMAIN environment variable is not set"))
-		return
-	}
-
-	fullPath := path.Join(domain, newFilenameWithExtDomain)
 
 	response := errors.NewResponseData(http.StatusCreated, "Upload Image Success ", fullPath)
 	c.JSON(http.StatusCreated, response)
@@ -253,10 +322,15 @@ func (h *StudentHandler) uploadImage(c *gin.Context) {
 
 func (h *StudentHandler) ActivateStudnetAll(c *gin.Context) {
 
-	if err := h.service.ActivateStudnetAll(); err != nil {
+	activated, err := h.service.ActivateStudnetAll()
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
 		return
 	}
+	recordAudit(c, h.audit, models.AuditActionUpdate, "tb_students", "", nil, nil, gin.H{"is_active": true})
+	for i := range activated {
+		h.recordHistory(c, models.AuditActionUpdate, &activated[i])
+	}
 
 	response := errors.NewResponseData(http.StatusOK, "Activate All Student Successfully", nil)
 	c.JSON(http.StatusOK, response)
@@ -286,8 +360,184 @@ func (h *StudentHandler) UploadAndImportDataStudent(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
 		return
 	}
+	recordAudit(c, h.audit, models.AuditActionImport, "tb_students", "", nil, nil, gin.H{"file": file.Filename, "result": stat})
+	for _, id := range stat.CreatedIDs {
+		h.history.Record(id.Hex(), middleware.CurrentActor(c), models.AuditActionImport, c.FullPath(), nil, h.snapshot(id.Hex()))
+	}
+	for i := range stat.Updated {
+		h.recordHistory(c, models.AuditActionImport, &stat.Updated[i])
+	}
 
 	// Respond to the client
 	response := errors.NewResponseData(http.StatusOK, "Data imported successfully", stat)
 	c.JSON(http.StatusOK, response)
 }
+
+func (h *StudentHandler) GetDeletedStudents(c *gin.Context) {
+	students, err := h.service.ListDeleted()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	response := errors.NewResponseData(http.StatusOK, "Deleted students fetched successfully", students)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *StudentHandler) RestoreStudent(c *gin.Context) {
+	var request RequestWithID
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if err := h.service.Restore(request.ID); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+	recordAudit(c, h.audit, models.AuditActionRestore, "tb_students", request.ID, nil, h.snapshot(request.ID), nil)
+
+	response := errors.NewResponseData(http.StatusOK, "Student restored successfully", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *StudentHandler) FindDuplicateStudents(c *gin.Context) {
+	var request DuplicateStudentsRequest
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	candidates, err := h.service.FindDuplicates(request.MinNameSimilarity, request.Limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	response := errors.NewResponseData(http.StatusOK, "Duplicate students fetched successfully", candidates)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *StudentHandler) CompareStudents(c *gin.Context) {
+	var request CompareStudentsRequest
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	comparison, err := h.service.Compare(request.FirstID, request.SecondID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	response := errors.NewResponseData(http.StatusOK, "Students compared successfully", comparison)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *StudentHandler) MergeStudents(c *gin.Context) {
+	var request MergeRequest
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	before := h.snapshot(request.WinnerID)
+	losers := make([]*models.Student, 0, len(request.LoserIDs))
+	for _, loserID := range request.LoserIDs {
+		losers = append(losers, h.snapshot(loserID))
+	}
+
+	result, err := h.service.Merge(request.WinnerID, request.LoserIDs, middleware.CurrentActor(c).ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+	h.recordChange(c, models.AuditActionMerge, request.WinnerID, before, gin.H{
+		"merged": losers,
+		"result": result,
+	})
+
+	response := errors.NewResponseData(http.StatusOK, "Students merged successfully", result)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *StudentHandler) GetStudentHistory(c *gin.Context) {
+	var filter models.HistoryFilter
+	if err := c.ShouldBind(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	history, err := h.history.Timeline(&filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	response := errors.NewResponseData(http.StatusOK, "Student history fetched successfully", history)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *StudentHandler) RevertStudentChange(c *gin.Context) {
+	var request RevertChangeRequest
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	revert, err := h.history.Revert(request.ID, request.Fields, middleware.CurrentActor(c), c.FullPath())
+	if err != nil {
+		if _, ok := err.(*services.HistoryConflictError); ok {
+			c.JSON(http.StatusConflict, errors.NewResponseError(http.StatusConflict, err.Error()))
+			return
+		}
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+	recordAudit(c, h.audit, models.AuditActionUpdate, "tb_students", revert.StudentID.Hex(), nil, nil, gin.H{
+		"revert_of": request.ID,
+		"changes":   revert.Changes,
+	})
+
+	response := errors.NewResponseData(http.StatusOK, "Change reverted successfully", revert)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *StudentHandler) AssignCounselor(c *gin.Context) {
+	var request AssignCounselorRequest
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	before := make(map[string]*models.Student, len(request.IDs))
+	for _, studentID := range request.IDs {
+		before[studentID] = h.snapshot(studentID)
+	}
+
+	result, err := h.service.AssignCounselor(request.IDs, request.CounselorID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+	for studentID, student := range before {
+		if student != nil {
+			h.recordChange(c, models.AuditActionUpdate, studentID, student, nil)
+		}
+	}
+
+	response := errors.NewResponseData(http.StatusOK, "Students assigned successfully", result)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *StudentHandler) GetCaseloads(c *gin.Context) {
+	caseloads, err := h.service.Caseloads()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	response := errors.NewResponseData(http.StatusOK, "Caseloads fetched successfully", caseloads)
+	c.JSON(http.StatusOK, response)
+}
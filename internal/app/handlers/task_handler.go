@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"net/http"
+
+	"elible/internal/app/middleware"
+	"elible/internal/app/models"
+	"elible/internal/app/services"
+	errors "elible/internal/pkg"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TaskHandler struct {
+	service *services.TaskService
+	audit   *services.AuditService
+}
+
+func NewTaskHandler(service *services.TaskService, audit *services.AuditService) *TaskHandler {
+	return &TaskHandler{
+		service: service,
+		audit:   audit,
+	}
+}
+
+// snapshot loads a task for the audit log, ignoring lookup errors.
+func (h *TaskHandler) snapshot(taskID string) *models.Task {
+	task, _ := h.service.GetByID(taskID)
+	return task
+}
+
+func (h *TaskHandler) CreateTask(c *gin.Context) {
+	var draft models.TaskDraft
+	if err := c.ShouldBind(&draft); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	task, err := h.service.Create(&draft, middleware.CurrentActor(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+	recordAudit(c, h.audit, models.AuditActionCreate, "tb_tasks", task.ID.Hex(), nil, task, nil)
+
+	response := errors.NewResponseData(http.StatusCreated, "Task created successfully", task)
+	c.JSON(http.StatusCreated, response)
+}
+
+func (h *TaskHandler) GetTasks(c *gin.Context) {
+	var filter models.TaskFilter
+	if err := c.ShouldBind(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+	h.listTasks(c, &filter)
+}
+
+func (h *TaskHandler) GetOverdueTasks(c *gin.Context) {
+	var filter models.TaskFilter
+	if err := c.ShouldBind(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+	overdue := true
+	filter.Overdue = &overdue
+	h.listTasks(c, &filter)
+}
+
+func (h *TaskHandler) listTasks(c *gin.Context, filter *models.TaskFilter) {
+	// "My tasks" only makes sense for an admin, API keys have no tasks
+	if filter.Mine != nil && *filter.Mine {
+		actor := middleware.CurrentActor(c)
+		if actor.Type != models.ActorTypeAdmin {
+			c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, "mine can only be used by an admin"))
+			return
+		}
+		filter.AssigneeID = &actor.ID
+	}
+
+	tasks, err := h.service.List(filter)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	response := errors.NewResponseData(http.StatusOK, "Tasks fetched successfully", tasks)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *TaskHandler) UpdateTask(c *gin.Context) {
+	var update models.TaskUpdate
+	if err := c.ShouldBind(&update); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	before := h.snapshot(update.ID)
+	if err := h.service.Update(&update); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+	after := h.snapshot(update.ID)
+	recordAudit(c, h.audit, models.AuditActionUpdate, "tb_tasks", update.ID, before, after, nil)
+
+	response := errors.NewResponseData(http.StatusOK, "Task updated successfully", after)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *TaskHandler) CompleteTask(c *gin.Context) {
+	var request RequestWithID
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	before := h.snapshot(request.ID)
+	if err := h.service.Complete(request.ID); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+	after := h.snapshot(request.ID)
+	recordAudit(c, h.audit, models.AuditActionUpdate, "tb_tasks", request.ID, before, after, nil)
+
+	response := errors.NewResponseData(http.StatusOK, "Task completed successfully", after)
+	c.JSON(http.StatusOK, response)
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"elible/internal/app/middleware"
 	"elible/internal/app/models"
 	"elible/internal/app/services"
 	errors "elible/internal/pkg"
@@ -12,10 +13,20 @@ import (
 
 type UniversityHandler struct {
 	service *services.UniversityService
+	audit   *services.AuditService
 }
 
-func NewUniversityHandler(service *services.UniversityService) *UniversityHandler {
-	return &UniversityHandler{service: service}
+func NewUniversityHandler(service *services.UniversityService, audit *services.AuditService) *UniversityHandler {
+	return &UniversityHandler{service: service, audit: audit}
+}
+
+// snapshot loads a university for the audit log, ignoring lookup errors.
+func (h *UniversityHandler) snapshot(id primitive.ObjectID) *models.University {
+	u, err := h.service.GetUniversity(id)
+	if err != nil {
+		return nil
+	}
+	return &u
 }
 
 func (h *UniversityHandler) CreateUniversity(c *gin.Context) {
@@ -30,6 +41,7 @@ func (h *UniversityHandler) CreateUniversity(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, "Failed to create university"))
 		return
 	}
+	recordAudit(c, h.audit, models.AuditActionCreate, "tb_universities", id.Hex(), nil, h.snapshot(id), nil)
 
 	response := errors.NewResponseData(http.StatusOK, "University created successfully", gin.H{"id": id})
 	c.JSON(http.StatusOK, response)
@@ -49,10 +61,12 @@ func (h *UniversityHandler) UpdateUniversity(c *gin.Context) {
 		return
 	}
 
+	before := h.snapshot(objectId)
 	if err := h.service.UpdateUniversity(objectId, request.University); err != nil {
-		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, "Failed to update university"))
+		errors.WriteStoreError(c, err, "University not found")
 		return
 	}
+	recordAudit(c, h.audit, models.AuditActionUpdate, "tb_universities", objectId.Hex(), before, h.snapshot(objectId), nil)
 
 	response := errors.NewResponseData(http.StatusOK, "University updated successfully", request.University)
 	c.JSON(http.StatusOK, response)
@@ -72,10 +86,12 @@ func (h *UniversityHandler) DeleteUniversity(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.DeleteUniversity(objectId); err != nil {
-		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, "Failed to delete university"))
+	before := h.snapshot(objectId)
+	if err := h.service.DeleteUniversity(objectId, middleware.CurrentActor(c).ID); err != nil {
+		errors.WriteStoreError(c, err, "University not found")
 		return
 	}
+	recordAudit(c, h.audit, models.AuditActionDelete, "tb_universities", objectId.Hex(), before, nil, nil)
 
 	response := errors.NewResponseData(http.StatusOK, "University deleted successfully", nil)
 	c.JSON(http.StatusOK, response)
@@ -128,3 +144,38 @@ func (h *UniversityHandler) GetUniversityByName(c *gin.Context) {
 	response := errors.NewResponseData(http.StatusOK, "University fetched successfully", u)
 	c.JSON(http.StatusOK, response)
 }
+
+func (h *UniversityHandler) GetDeletedUniversities(c *gin.Context) {
+	us, err := h.service.GetDeletedUniversities()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, "Failed to retrieve deleted universities"))
+		return
+	}
+
+	response := errors.NewResponseData(http.StatusOK, "Deleted universities fetched successfully", us)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *UniversityHandler) RestoreUniversity(c *gin.Context) {
+	var request RequestWithID
+
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	objectId, err := primitive.ObjectIDFromHex(request.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	if err := h.service.RestoreUniversity(objectId); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+	recordAudit(c, h.audit, models.AuditActionRestore, "tb_universities", objectId.Hex(), nil, h.snapshot(objectId), nil)
+
+	response := errors.NewResponseData(http.StatusOK, "University restored successfully", nil)
+	c.JSON(http.StatusOK, response)
+}
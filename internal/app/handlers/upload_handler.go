@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	utils "elible/internal/app/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxAttachmentSize is the largest attachment accepted by saveAttachment.
+const maxAttachmentSize = 10 << 20
+
+// saveImage stores an uploaded image in IMAGE_DIR/<folder> under a random name
+// and returns its public URL on WEB_DOMAIN. On failure it also returns the
+// status code to answer with.
+func saveImage(c *gin.Context, file *multipart.FileHeader, folder string) (string, int, error) {
+	if valid := utils.IsImage(file); !valid {
+		return "", http.StatusBadRequest, fmt.Errorf("File is not an image")
+	}
+	return saveUpload(c, file, folder)
+}
+
+// saveAttachment is saveImage for documents such as PDFs and office files.
+func saveAttachment(c *gin.Context, file *multipart.FileHeader, folder string) (string, int, error) {
+	if valid := utils.IsAttachment(file); !valid {
+		return "", http.StatusBadRequest, fmt.Errorf("File type is not allowed as an attachment")
+	}
+	if file.Size > maxAttachmentSize {
+		return "", http.StatusBadRequest, fmt.Errorf("File is larger than %d MB", maxAttachmentSize>>20)
+	}
+	return saveUpload(c, file, folder)
+}
+
+// isUploadURL reports whether url points to a file that saveUpload stored in
+// folder, so a client cannot pass an arbitrary link off as an upload.
+func isUploadURL(url, folder string) bool {
+	domain := os.Getenv("WEB_DOMAIN")
+	if domain == "" {
+		return false
+	}
+
+	prefix := path.Join(domain, "images", folder) + "/"
+	name := strings.TrimPrefix(url, prefix)
+	if name == url || name == "" || name == "." || name == ".." {
+		return false
+	}
+	return !strings.ContainsAny(name, "/\\?#")
+}
+
+func saveUpload(c *gin.Context, file *multipart.FileHeader, folder string) (string, int, error) {
+	dir := os.Getenv("IMAGE_DIR")
+	if dir == "" {
+		return "", http.StatusInternalServerError, fmt.Errorf("IMAGE_DIR environment variable is not set")
+	}
+	dir = path.Join(dir, folder)
+
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		err = os.MkdirAll(dir, 0755)
+		if err != nil {
+			return "", http.StatusInternalServerError, err
+		}
+	}
+
+	newFilename, err := utils.RandomString(10) // Generate a 10-character long random string
+	if err != nil {
+		return "", http.StatusInternalServerError, fmt.Errorf("Failed to generate a random string for the filename")
+	}
+	newFilename = fmt.Sprintf("%s_%s", newFilename, time.Now().Format("20060102"))
+	ext := filepath.Ext(file.Filename)
+	newFilenameWithExt := newFilename + ext
+	newFilenameWithExtDomain := path.Join("images", folder, newFilenameWithExt)
+	dst := filepath.Join(dir, newFilenameWithExt)
+
+	if err := c.SaveUploadedFile(file, dst); err != nil {
+		return "", http.StatusInternalServerError, err
+	}
+
+	if err := os.Chmod(dst, 0644); err != nil {
+		return "", http.StatusInternalServerError, err
+	}
+
+	domain := os.Getenv("WEB_DOMAIN")
+	if domain == "" {
+		return "", http.StatusInternalServerError, fmt.Errorf("WEB_DOMAIN environment variable is not set")
+	}
+
+	return path.Join(domain, newFilenameWithExtDomain), 0, nil
+}
@@ -1 +1,150 @@
-package handlers
\ No newline at end of file
+package handlers
+
+import (
+	"net/http"
+
+	"elible/internal/app/middleware"
+	"elible/internal/app/models"
+	"elible/internal/app/services"
+	errors "elible/internal/pkg"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UserHandler struct {
+	service *services.UserService
+	history *services.HistoryService
+}
+
+func NewUserHandler(service *services.UserService, history *services.HistoryService) *UserHandler {
+	return &UserHandler{
+		service: service,
+		history: history,
+	}
+}
+
+func (h *UserHandler) RequestMagicLink(c *gin.Context) {
+	var request MagicLinkRequest
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if err := h.service.RequestMagicLink(request.Email); err != nil {
+		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	// Same answer whether or not the email belongs to a student
+	response := errors.NewResponseData(http.StatusOK, "If the email is registered, a login link has been sent", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *UserHandler) VerifyMagicLink(c *gin.Context) {
+	var request MagicLinkVerifyRequest
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	user, token, err := h.service.VerifyMagicLink(request.Token)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, errors.NewResponseError(http.StatusUnauthorized, err.Error()))
+		return
+	}
+
+	writeUserLogin(c, user, token)
+}
+
+func (h *UserHandler) Login(c *gin.Context) {
+	var request UserLoginRequest
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	user, token, err := h.service.Login(request.Email, request.Password)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, errors.NewResponseError(http.StatusUnauthorized, err.Error()))
+		return
+	}
+
+	writeUserLogin(c, user, token)
+}
+
+func writeUserLogin(c *gin.Context, user *models.User, token *models.UserToken) {
+	response := errors.NewResponseData(http.StatusOK, "Login successful", gin.H{
+		"user":         user,
+		"access_token": token.AccessToken,
+		"expires_at":   token.ExpiresAt,
+		"has_password": user.Password != "",
+	})
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *UserHandler) Logout(c *gin.Context) {
+	token, ok := bearerToken(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, errors.NewResponseError(http.StatusUnauthorized, "Invalid Authorization header format"))
+		return
+	}
+
+	if err := h.service.Logout(token); err != nil {
+		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	response := errors.NewResponseData(http.StatusOK, "Logged out successfully", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *UserHandler) SetPassword(c *gin.Context) {
+	var request SetPasswordRequest
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if err := h.service.SetPassword(middleware.CurrentUser(c), request.OldPassword, request.NewPassword); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	response := errors.NewResponseData(http.StatusOK, "Password updated successfully", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *UserHandler) GetMe(c *gin.Context) {
+	student, err := h.service.GetStudent(middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(http.StatusNotFound, errors.NewResponseError(http.StatusNotFound, err.Error()))
+		return
+	}
+
+	response := errors.NewResponseData(http.StatusOK, "Student fetched successfully", student)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *UserHandler) UpdateMe(c *gin.Context) {
+	var update models.StudentSelfUpdate
+	if err := c.ShouldBind(&update); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	user := middleware.CurrentUser(c)
+	before, _ := h.service.GetStudent(user)
+	student, err := h.service.UpdateStudent(user, update)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errors.NewResponseError(http.StatusInternalServerError, err.Error()))
+		return
+	}
+	h.history.Record(user.StudentID.Hex(), models.Actor{
+		Type: models.ActorTypeStudent,
+		ID:   user.ID.Hex(),
+		Name: user.Email,
+	}, models.AuditActionUpdate, c.FullPath(), before, student)
+
+	response := errors.NewResponseData(http.StatusOK, "Student updated successfully", student)
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"elible/internal/app/models"
+	"elible/internal/app/services"
+	"elible/internal/config"
+	errors "elible/internal/pkg"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	APIKeyHeader     = "X-API-Key"
+	APIKeyContextKey = "apiKey"
+)
+
+// APIKeyMiddleware accepts either an X-API-Key header carrying the permission
+// as one of its scopes, or a regular admin token with that permission.
+func APIKeyMiddleware(cfg *config.Config, adminService *services.AdminService, apiKeyService *services.APIKeyService, permission models.Permission, next gin.HandlerFunc) gin.HandlerFunc {
+	adminAuth := AdminMiddleware(cfg, adminService, false, true, RequirePermission(permission, next))
+
+	return func(c *gin.Context) {
+		plainKey := c.GetHeader(APIKeyHeader)
+		if plainKey == "" {
+			adminAuth(c)
+			return
+		}
+
+		key, err := apiKeyService.Authenticate(plainKey)
+		if err != nil || key == nil {
+			errors.WriteErrorResponse(c.Writer, 401, "Invalid API key")
+			c.Abort()
+			return
+		}
+
+		if !key.HasScope(permission) {
+			errors.WriteErrorResponse(c.Writer, 403, "API key is not allowed to perform this action")
+			c.Abort()
+			return
+		}
+		c.Set(APIKeyContextKey, key)
+
+		next(c)
+		c.Next()
+	}
+}
+
+func CurrentAPIKey(c *gin.Context) *models.APIKey {
+	value, exists := c.Get(APIKeyContextKey)
+	if !exists {
+		return nil
+	}
+	key, _ := value.(*models.APIKey)
+	return key
+}
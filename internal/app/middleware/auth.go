@@ -3,6 +3,7 @@ package middleware
 import (
 	"strings"
 
+	"elible/internal/app/models"
 	"elible/internal/app/services"
 	"elible/internal/app/utils"
 	"elible/internal/config"
@@ -11,6 +12,8 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+const AdminContextKey = "admin"
+
 func AdminMiddleware(cfg *config.Config, tokenService *services.AdminService, useLocalValidation bool, useDatabaseValidation bool, next gin.HandlerFunc) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
@@ -45,9 +48,58 @@ func AdminMiddleware(cfg *config.Config, tokenService *services.AdminService, us
 				c.Abort()
 				return
 			}
+			c.Set(AdminContextKey, dbToken)
 		}
 
 		next(c)
 		c.Next()
 	}
 }
+
+// RequirePermission must be wrapped by AdminMiddleware with database validation,
+// which puts the authenticated admin into the context.
+func RequirePermission(permission models.Permission, next gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		admin := CurrentAdmin(c)
+		if admin == nil {
+			errors.WriteErrorResponse(c.Writer, 401, "Authenticated admin required")
+			c.Abort()
+			return
+		}
+
+		// Superadmins can only use their permissions after enrolling in two-factor authentication
+		if admin.RequiresTOTPEnrollment() {
+			errors.WriteErrorResponse(c.Writer, 403, "Two-factor authentication must be enabled for this account")
+			c.Abort()
+			return
+		}
+
+		if !admin.EffectiveRole().HasPermission(permission) {
+			errors.WriteErrorResponse(c.Writer, 403, "You do not have permission to perform this action")
+			c.Abort()
+			return
+		}
+
+		next(c)
+	}
+}
+
+func CurrentAdmin(c *gin.Context) *models.Admin {
+	value, exists := c.Get(AdminContextKey)
+	if !exists {
+		return nil
+	}
+	admin, _ := value.(*models.Admin)
+	return admin
+}
+
+// CurrentActor describes whoever authenticated the request, for audit logs.
+func CurrentActor(c *gin.Context) models.Actor {
+	if admin := CurrentAdmin(c); admin != nil {
+		return models.Actor{Type: models.ActorTypeAdmin, ID: admin.ID.Hex(), Name: admin.Username}
+	}
+	if key := CurrentAPIKey(c); key != nil {
+		return models.Actor{Type: models.ActorTypeAPIKey, ID: key.ID.Hex(), Name: key.Name}
+	}
+	return models.Actor{}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"strings"
+
+	"elible/internal/app/models"
+	"elible/internal/app/services"
+	errors "elible/internal/pkg"
+
+	"github.com/gin-gonic/gin"
+)
+
+const UserContextKey = "user"
+
+// StudentMiddleware authenticates student portal tokens. Admin tokens are
+// rejected because they are signed without the student purpose claim.
+func StudentMiddleware(userService *services.UserService, next gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenParts := strings.Split(c.GetHeader("Authorization"), " ")
+		if len(tokenParts) != 2 || strings.ToLower(tokenParts[0]) != "bearer" {
+			errors.WriteErrorResponse(c.Writer, 401, "Invalid Authorization header format")
+			c.Abort()
+			return
+		}
+
+		user, err := userService.GetUserByToken(tokenParts[1])
+		if err != nil || user == nil {
+			errors.WriteErrorResponse(c.Writer, 401, "Invalid Authorization token")
+			c.Abort()
+			return
+		}
+		c.Set(UserContextKey, user)
+
+		next(c)
+		c.Next()
+	}
+}
+
+func CurrentUser(c *gin.Context) *models.User {
+	value, exists := c.Get(UserContextKey)
+	if !exists {
+		return nil
+	}
+	user, _ := value.(*models.User)
+	return user
+}
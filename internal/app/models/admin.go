@@ -1,13 +1,59 @@
 // internal/app/models/admin.go
 package models
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
 
 type Admin struct {
-	ID       primitive.ObjectID `bson:"_id,omitempty"`
-	Username string             `bson:"username,omitempty"`
-	Password string             `bson:"password,omitempty"`
-	Email    string             `bson:"email,omitempty"`
-	FullName string             `bson:"fullName,omitempty"`
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	Username  string             `bson:"username,omitempty"`
+	Password  string             `bson:"password,omitempty"`
+	Email     string             `bson:"email,omitempty"`
+	FullName  string             `bson:"fullName,omitempty"`
+	Role      Role               `bson:"role,omitempty"`
+	Disabled  bool               `bson:"disabled,omitempty"`
+	CreatedAt time.Time          `bson:"createdAt,omitempty"`
+	UpdatedAt time.Time          `bson:"updatedAt,omitempty"`
+
+	// TOTPSecret is set during enrollment and only used for login once TOTPEnabled is true.
+	TOTPSecret    string   `bson:"totpSecret,omitempty"`
+	TOTPEnabled   bool     `bson:"totpEnabled,omitempty"`
+	TOTPLastStep  int64    `bson:"totpLastStep,omitempty"`
+	RecoveryCodes []string `bson:"recoveryCodes,omitempty"`
+}
+
+// EffectiveRole treats admins created before roles existed as viewers.
+func (a *Admin) EffectiveRole() Role {
+	if a.Role == "" {
+		return RoleViewer
+	}
+	return a.Role
+}
+
+// RequiresTOTPEnrollment is true for superadmins who have not set up two-factor authentication yet.
+func (a *Admin) RequiresTOTPEnrollment() bool {
+	return a.EffectiveRole() == RoleSuperAdmin && !a.TOTPEnabled
 }
 
+type AdminProfile struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	FullName string `json:"fullName"`
+}
+
+// LoginResult carries either a new session or, for admins with two-factor
+// authentication, the token needed to complete the second step.
+type LoginResult struct {
+	Admin       *Admin
+	Token       *Token
+	MFARequired bool
+	MFAToken    string
+}
+
+type TOTPSetup struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
@@ -0,0 +1,94 @@
+package models
+
+// Revenue groupings accepted by the revenue reports.
+const (
+	RevenueByService   = "service"
+	RevenueByMonth     = "month"
+	RevenueBySchool    = "school"
+	RevenueByCounselor = "counselor"
+)
+
+// AnalyticsFilter narrows the reports to track records created between
+// DateFrom and DateTo (inclusive days in YYYY-MM-DD, Jakarta time) and,
+// optionally, with the given status.
+type AnalyticsFilter struct {
+	DateFrom *string `json:"date_from,omitempty"`
+	DateTo   *string `json:"date_to,omitempty"`
+	Status   *string `json:"status,omitempty"`
+}
+
+// RevenueRow is the revenue of one group of track records. Key is the service
+// name, month (YYYY-MM), school or counselor ID; Label is the counselor's name.
+// Records without a numeric amount are counted in Unpriced and add nothing to Revenue.
+type RevenueRow struct {
+	Key      string `bson:"_id" json:"key"`
+	Label    string `bson:"label,omitempty" json:"label,omitempty"`
+	Services int    `bson:"services" json:"services"`
+	Unpriced int    `bson:"unpriced" json:"unpriced"`
+	Revenue  int64  `bson:"revenue" json:"revenue"`
+}
+
+type StatusCount struct {
+	Status string `bson:"_id" json:"status"`
+	Count  int    `bson:"count" json:"count"`
+}
+
+// OutstandingSummary totals the open invoice balances and what was collected on them.
+type OutstandingSummary struct {
+	OpenInvoices    int   `bson:"open_invoices" json:"open_invoices"`
+	Outstanding     int64 `bson:"outstanding" json:"outstanding"`
+	OverdueInvoices int   `bson:"overdue_invoices" json:"overdue_invoices"`
+	Overdue         int64 `bson:"overdue" json:"overdue"`
+	Invoiced        int64 `bson:"invoiced" json:"invoiced"`
+	Collected       int64 `bson:"collected" json:"collected"`
+}
+
+// Drop-off groupings accepted by the funnel reports.
+const (
+	DropOffBySchool   = "school"
+	DropOffByProvince = "province"
+	DropOffByInterest = "interest"
+)
+
+// FunnelFilter narrows the funnel reports to students created between DateFrom
+// and DateTo (inclusive days in YYYY-MM-DD, Jakarta time).
+type FunnelFilter struct {
+	DateFrom *string `json:"date_from,omitempty"`
+	DateTo   *string `json:"date_to,omitempty"`
+	School   *string `json:"school,omitempty"`
+	Interest *string `json:"interest,omitempty"`
+}
+
+// FunnelStage reports how many students reached a pipeline stage and how long
+// they stayed there before moving on. Rates are fractions between 0 and 1.
+type FunnelStage struct {
+	Key                    string   `json:"key"`
+	Name                   string   `json:"name"`
+	Reached                int      `json:"reached"`
+	ConversionFromPrevious float64  `json:"conversion_from_previous"`
+	ConversionFromFirst    float64  `json:"conversion_from_first"`
+	MedianHoursInStage     *float64 `json:"median_hours_in_stage"`
+	TimedStudents          int      `json:"timed_students"`
+}
+
+// DropOffRow compares how the students of one school, province or interest
+// progress: Enrolled reached the enrolled_service stage and Paid have at least
+// one service with an amount.
+type DropOffRow struct {
+	Key         string  `json:"key"`
+	Students    int     `json:"students"`
+	Enrolled    int     `json:"enrolled"`
+	Paid        int     `json:"paid"`
+	Rejected    int     `json:"rejected"`
+	DropOffRate float64 `json:"drop_off_rate"`
+	PaidRate    float64 `json:"paid_rate"`
+}
+
+// CohortRow follows the students created in one month (YYYY-MM).
+type CohortRow struct {
+	Month    string         `json:"month"`
+	Students int            `json:"students"`
+	Reached  map[string]int `json:"reached"`
+	Paid     int            `json:"paid"`
+	PaidRate float64        `json:"paid_rate"`
+}
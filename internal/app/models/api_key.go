@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// APIKey lets an integration call the API without an admin login. Only the
+// SHA-256 hash of the key is stored; Prefix is kept to tell keys apart.
+type APIKey struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name       string             `bson:"name,omitempty" json:"name"`
+	Prefix     string             `bson:"prefix,omitempty" json:"prefix"`
+	KeyHash    string             `bson:"keyHash,omitempty" json:"-"`
+	Scopes     []Permission       `bson:"scopes,omitempty" json:"scopes"`
+	CreatedBy  primitive.ObjectID `bson:"createdBy,omitempty" json:"created_by"`
+	CreatedAt  time.Time          `bson:"createdAt,omitempty" json:"created_at"`
+	LastUsedAt *time.Time         `bson:"lastUsedAt,omitempty" json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time         `bson:"revokedAt,omitempty" json:"revoked_at,omitempty"`
+}
+
+func (k *APIKey) HasScope(permission Permission) bool {
+	for _, scope := range k.Scopes {
+		if scope == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKeyScopes are the permissions that can be granted to an API key. Admin
+// management, deleting and bulk imports are never available to integrations.
+var APIKeyScopes = []Permission{
+	PermissionStudentRead,
+	PermissionStudentWrite,
+	PermissionCatalogRead,
+	PermissionCatalogWrite,
+}
+
+func IsValidAPIKeyScope(permission Permission) bool {
+	for _, scope := range APIKeyScopes {
+		if scope == permission {
+			return true
+		}
+	}
+	return false
+}
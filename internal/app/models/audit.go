@@ -0,0 +1,69 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	AuditActionCreate  = "create"
+	AuditActionUpdate  = "update"
+	AuditActionDelete  = "delete"
+	AuditActionRestore = "restore"
+	AuditActionImport  = "import"
+	AuditActionMerge   = "merge"
+)
+
+const (
+	ActorTypeAdmin  = "admin"
+	ActorTypeAPIKey = "api_key"
+	ActorTypeSystem = "system"
+)
+
+// Actor identifies who performed a request, either an admin or an API key.
+type Actor struct {
+	Type string `bson:"type,omitempty" json:"type"`
+	ID   string `bson:"id,omitempty" json:"id"`
+	Name string `bson:"name,omitempty" json:"name"`
+}
+
+type FieldChange struct {
+	Field  string      `bson:"field" json:"field"`
+	Before interface{} `bson:"before,omitempty" json:"before,omitempty"`
+	After  interface{} `bson:"after,omitempty" json:"after,omitempty"`
+}
+
+// AuditLog records a single write. Changes holds the top level fields that
+// differ between the document before and after the write; Details carries
+// extra context such as import statistics.
+type AuditLog struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Actor      Actor              `bson:"actor" json:"actor"`
+	Action     string             `bson:"action" json:"action"`
+	Method     string             `bson:"method,omitempty" json:"method,omitempty"`
+	Route      string             `bson:"route,omitempty" json:"route,omitempty"`
+	Collection string             `bson:"collection" json:"collection"`
+	DocumentID string             `bson:"document_id,omitempty" json:"document_id,omitempty"`
+	Changes    []FieldChange      `bson:"changes,omitempty" json:"changes,omitempty"`
+	Details    interface{}        `bson:"details,omitempty" json:"details,omitempty"`
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+}
+
+type AuditFilter struct {
+	ActorID    *string `json:"actor_id,omitempty"`
+	Action     *string `json:"action,omitempty"`
+	Collection *string `json:"collection,omitempty"`
+	DocumentID *string `json:"document_id,omitempty"`
+	DateFrom   *string `json:"date_from,omitempty"`
+	DateTo     *string `json:"date_to,omitempty"`
+	Page       *int    `json:"page,omitempty"`
+	PageSize   *int    `json:"pageSize,omitempty"`
+}
+
+type PagedAuditLogs struct {
+	CurrentPage  int
+	TotalRecords int64
+	TotalPages   int
+	Records      []AuditLog
+}
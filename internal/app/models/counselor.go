@@ -0,0 +1,25 @@
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CounselorUnassigned filters students without a counselor.
+const CounselorUnassigned = "unassigned"
+
+// CounselorCaseload is the number of students assigned to a counselor. The row
+// for students without a counselor has a zero CounselorID.
+type CounselorCaseload struct {
+	CounselorID primitive.ObjectID `bson:"_id" json:"counselor_id"`
+	Username    string             `bson:"username,omitempty" json:"username,omitempty"`
+	FullName    string             `bson:"full_name,omitempty" json:"full_name,omitempty"`
+	Disabled    bool               `bson:"disabled,omitempty" json:"disabled,omitempty"`
+	Active      int64              `bson:"active" json:"active"`
+	Inactive    int64              `bson:"inactive" json:"inactive"`
+	Total       int64              `bson:"total" json:"total"`
+}
+
+type AssignResult struct {
+	CounselorID primitive.ObjectID `json:"counselor_id"`
+	Assigned    int64              `json:"assigned"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ActorTypeStudent marks changes students make to their own record through the portal.
+const ActorTypeStudent = "student"
+
+const HistoryActionRevert = "revert"
+
+// StudentChangeSet is one versioned entry in a student's history. Version
+// counts up per student from 1. RevertOf points at the change set a revert
+// undid.
+type StudentChangeSet struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	StudentID primitive.ObjectID `bson:"student_id" json:"student_id"`
+	Version   int64              `bson:"version" json:"version"`
+	Action    string             `bson:"action" json:"action"`
+	Route     string             `bson:"route,omitempty" json:"route,omitempty"`
+	Actor     Actor              `bson:"actor" json:"actor"`
+	Changes   []FieldChange      `bson:"changes" json:"changes"`
+	RevertOf  primitive.ObjectID `bson:"revert_of,omitempty" json:"revert_of,omitempty"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+}
+
+type HistoryFilter struct {
+	StudentID string  `json:"id" binding:"required"`
+	Field     *string `json:"field,omitempty"`
+	Page      *int    `json:"page,omitempty"`
+	PageSize  *int    `json:"pageSize,omitempty"`
+}
+
+type PagedStudentHistory struct {
+	CurrentPage  int
+	TotalRecords int64
+	TotalPages   int
+	Records      []StudentChangeSet
+}
@@ -1,13 +1,21 @@
 package models
 
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
 type ImportResult struct {
 	UniversityStats OperationStats `json:"university_stats"`
 	ProgramStats    OperationStats `json:"program_stats"`
 }
 
+// ImportResultStudent reports an import. CreatedIDs are the students the
+// import inserted, so they can be given a counselor afterwards, and Updated
+// holds the students it changed as they were before, for their history.
 type ImportResultStudent struct {
-	SchoolStats  OperationStats `json:"school_stats"`
-	StudentStats OperationStats `json:"student_stats"`
+	SchoolStats        OperationStats       `json:"school_stats"`
+	StudentStats       OperationStats       `json:"student_stats"`
+	CounselorsAssigned int64                `json:"counselors_assigned"`
+	CreatedIDs         []primitive.ObjectID `json:"-"`
+	Updated            []Student            `json:"-"`
 }
 
 type OperationStats struct {
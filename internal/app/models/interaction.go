@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	InteractionCall     = "call"
+	InteractionWhatsApp = "whatsapp"
+	InteractionMeeting  = "meeting"
+	InteractionEmail    = "email"
+	InteractionNote     = "note"
+)
+
+// Interaction is one entry in a student's interaction log. OccurredAt is when
+// the conversation took place, which may be earlier than when it was logged.
+// Attachment is a URL returned by /interaction/upload.
+type Interaction struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	StudentID   primitive.ObjectID `bson:"student_id" json:"student_id"`
+	StudentName string             `bson:"student_name,omitempty" json:"student_name,omitempty"`
+	Type        string             `bson:"type" json:"type"`
+	Body        string             `bson:"body" json:"body"`
+	Attachment  string             `bson:"attachment,omitempty" json:"attachment,omitempty"`
+	Author      Actor              `bson:"author" json:"author"`
+	OccurredAt  time.Time          `bson:"occurred_at" json:"occurred_at"`
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// InteractionDraft is the input for a new interaction. OccurredAt is RFC 3339
+// and defaults to now.
+type InteractionDraft struct {
+	StudentID  string `json:"student_id" binding:"required"`
+	Type       string `json:"type" binding:"required"`
+	Body       string `json:"body" binding:"required"`
+	Attachment string `json:"attachment"`
+	OccurredAt string `json:"occurred_at"`
+}
+
+// InteractionFilter searches the log of all students. Query is matched
+// against the body with the text index; the other fields narrow it down.
+type InteractionFilter struct {
+	Query     *string `json:"query,omitempty"`
+	StudentID *string `json:"student_id,omitempty"`
+	Type      *string `json:"type,omitempty"`
+	AuthorID  *string `json:"author_id,omitempty"`
+	DateFrom  *string `json:"date_from,omitempty"`
+	DateTo    *string `json:"date_to,omitempty"`
+	Page      *int    `json:"page,omitempty"`
+	PageSize  *int    `json:"pageSize,omitempty"`
+}
+
+type PagedInteractions struct {
+	CurrentPage  int
+	TotalRecords int64
+	TotalPages   int
+	Records      []Interaction
+}
+
+// StudentDetail is a student with the latest entries of their interaction log.
+type StudentDetail struct {
+	*Student
+	Interactions     []Interaction `json:"interactions"`
+	InteractionCount int64         `json:"interaction_count"`
+}
@@ -0,0 +1,112 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	InvoiceStatusUnpaid  = "unpaid"
+	InvoiceStatusPartial = "partial"
+	InvoiceStatusPaid    = "paid"
+	InvoiceStatusVoid    = "void"
+)
+
+// InvoiceItem is a billed track record. Amounts are whole rupiah.
+type InvoiceItem struct {
+	ServiceName string `bson:"service_name" json:"service_name"`
+	ServiceDate string `bson:"service_date,omitempty" json:"service_date,omitempty"`
+	Amount      int64  `bson:"amount" json:"amount"`
+}
+
+type Payment struct {
+	ID         primitive.ObjectID `bson:"_id" json:"id"`
+	Amount     int64              `bson:"amount" json:"amount"`
+	Method     string             `bson:"method,omitempty" json:"method,omitempty"`
+	Reference  string             `bson:"reference,omitempty" json:"reference,omitempty"`
+	Note       string             `bson:"note,omitempty" json:"note,omitempty"`
+	PaidAt     time.Time          `bson:"paid_at" json:"paid_at"`
+	RecordedBy string             `bson:"recorded_by,omitempty" json:"recorded_by,omitempty"`
+}
+
+// Invoice bills a student for some of their track records. Total is the
+// subtotal minus the discount, and Status follows AmountPaid.
+type Invoice struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	Number      string             `bson:"number" json:"number"`
+	StudentID   primitive.ObjectID `bson:"student_id" json:"student_id"`
+	StudentName string             `bson:"student_name" json:"student_name"`
+	Items       []InvoiceItem      `bson:"items" json:"items"`
+	Subtotal    int64              `bson:"subtotal" json:"subtotal"`
+	Discount    int64              `bson:"discount" json:"discount"`
+	Total       int64              `bson:"total" json:"total"`
+	AmountPaid  int64              `bson:"amount_paid" json:"amount_paid"`
+	Status      string             `bson:"status" json:"status"`
+	DueDate     time.Time          `bson:"due_date" json:"due_date"`
+	Notes       string             `bson:"notes,omitempty" json:"notes,omitempty"`
+	Payments    []Payment          `bson:"payments,omitempty" json:"payments,omitempty"`
+	CreatedBy   string             `bson:"created_by,omitempty" json:"created_by,omitempty"`
+	CreatedAt   time.Time          `bson:"created_at,omitempty" json:"created_at,omitempty"`
+	UpdatedAt   time.Time          `bson:"updated_at,omitempty" json:"updated_at,omitempty"`
+	VoidedAt    *time.Time         `bson:"voided_at,omitempty" json:"voided_at,omitempty"`
+	VoidReason  string             `bson:"void_reason,omitempty" json:"void_reason,omitempty"`
+}
+
+func (i *Invoice) Balance() int64 {
+	return i.Total - i.AmountPaid
+}
+
+// PaymentStatus derives the status from the amount paid so far.
+func PaymentStatus(total, amountPaid int64) string {
+	switch {
+	case amountPaid >= total:
+		return InvoiceStatusPaid
+	case amountPaid <= 0:
+		return InvoiceStatusUnpaid
+	default:
+		return InvoiceStatusPartial
+	}
+}
+
+type InvoiceFilter struct {
+	StudentID *string `json:"student_id,omitempty"`
+	Status    *string `json:"status,omitempty"`
+	// Overdue limits the result to unpaid and partially paid invoices past their due date.
+	Overdue  *bool `json:"overdue,omitempty"`
+	Page     *int  `json:"page,omitempty"`
+	PageSize *int  `json:"pageSize,omitempty"`
+}
+
+type PagedInvoices struct {
+	CurrentPage  int
+	TotalRecords int64
+	TotalPages   int
+	Records      []Invoice
+}
+
+// InvoiceDraft describes a new invoice. Without ServiceNames every service of
+// the student that is not on an invoice yet is billed.
+type InvoiceDraft struct {
+	StudentID    string   `json:"student_id" binding:"required"`
+	ServiceNames []string `json:"service_names"`
+	Discount     int64    `json:"discount"`
+	DueDate      string   `json:"due_date"`
+	Notes        string   `json:"notes"`
+}
+
+type PaymentDraft struct {
+	InvoiceID string `json:"invoice_id" binding:"required"`
+	Amount    int64  `json:"amount" binding:"required"`
+	Method    string `json:"method"`
+	Reference string `json:"reference"`
+	Note      string `json:"note"`
+	PaidAt    string `json:"paid_at"`
+}
+
+type InvoiceTermsUpdate struct {
+	ID       string  `json:"id" binding:"required"`
+	Discount *int64  `json:"discount"`
+	DueDate  *string `json:"due_date"`
+	Notes    *string `json:"notes"`
+}
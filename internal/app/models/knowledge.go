@@ -12,6 +12,8 @@ type KnowledgeBase struct {
 	Programs  []KnowledgeProgram `bson:"programs,omitempty" json:"programs,omitempty"`
 	CreatedAt time.Time          `bson:"created_at,omitempty" json:"created_at,omitempty"`
 	UpdatedAt time.Time          `bson:"updated_at,omitempty" json:"updated_at,omitempty"`
+	DeletedAt *time.Time         `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+	DeletedBy string             `bson:"deleted_by,omitempty" json:"deleted_by,omitempty"`
 }
 
 type KnowledgeProgram struct {
@@ -26,6 +28,16 @@ type StudyProgram struct {
 	ProgramDetails Program            `bson:"program_details,omitempty" json:"program_details,omitempty"`
 	CreatedAt      time.Time          `bson:"created_at,omitempty" json:"created_at,omitempty"`
 	UpdatedAt      time.Time          `bson:"updated_at,omitempty" json:"updated_at,omitempty"`
+	DeletedAt      *time.Time         `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+	DeletedBy      string             `bson:"deleted_by,omitempty" json:"deleted_by,omitempty"`
+	// DeletedLinks remembers the knowledge programs a deleted study program was
+	// removed from, so restoring it can add it back.
+	DeletedLinks []KnowledgeLink `bson:"deleted_links,omitempty" json:"deleted_links,omitempty"`
+}
+
+type KnowledgeLink struct {
+	KnowledgeBaseID primitive.ObjectID `bson:"knowledge_base_id" json:"knowledge_base_id"`
+	ProgramName     string             `bson:"program_name" json:"program_name"`
 }
 
 type Program struct {
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// LoginAttempt counts recent failed logins for one username or one IP address.
+type LoginAttempt struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	Key          string             `bson:"key,omitempty" json:"key,omitempty"`
+	FailedCount  int                `bson:"failedCount" json:"failed_count"`
+	LastFailedAt time.Time          `bson:"lastFailedAt,omitempty" json:"last_failed_at,omitempty"`
+	LockedUntil  time.Time          `bson:"lockedUntil,omitempty" json:"locked_until,omitempty"`
+}
+
+const (
+	LoginStatusFailed = "failed"
+	LoginStatusLocked = "locked"
+)
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PasswordReset is a single-use reset token. Only the SHA-256 hash of the token is stored.
+type PasswordReset struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	AdminID   primitive.ObjectID `bson:"adminID,omitempty"`
+	TokenHash string             `bson:"tokenHash,omitempty"`
+	ExpiresAt time.Time          `bson:"expiresAt,omitempty"`
+	UsedAt    *time.Time         `bson:"usedAt,omitempty"`
+	CreatedAt time.Time          `bson:"createdAt,omitempty"`
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	StageLead            = "lead"
+	StageConsultation    = "consultation"
+	StageEnrolledService = "enrolled_service"
+	StageRegistered      = "registered"
+	StageExam            = "exam"
+	StageAccepted        = "accepted"
+	StageRejected        = "rejected"
+)
+
+// PipelineStage is one column of the student pipeline. Transitions lists the
+// stage keys a student in this stage may move to next. When FollowUp is set, a
+// task is created for the student's counselor on entering the stage.
+type PipelineStage struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	Key         string             `bson:"key" json:"key" binding:"required"`
+	Name        string             `bson:"name" json:"name" binding:"required"`
+	Order       int                `bson:"order" json:"order"`
+	Terminal    bool               `bson:"terminal,omitempty" json:"terminal"`
+	Transitions []string           `bson:"transitions" json:"transitions"`
+	FollowUp    *StageFollowUp     `bson:"follow_up,omitempty" json:"follow_up,omitempty"`
+	UpdatedAt   time.Time          `bson:"updated_at,omitempty" json:"updated_at,omitempty"`
+}
+
+// StageFollowUp is the task created when a student enters a stage, due DueInDays later.
+type StageFollowUp struct {
+	Title     string `bson:"title" json:"title"`
+	DueInDays int    `bson:"due_in_days" json:"due_in_days"`
+}
+
+func (s *PipelineStage) CanMoveTo(key string) bool {
+	for _, next := range s.Transitions {
+		if next == key {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultPipelineStages seed tb_pipeline_stages the first time the API starts.
+var DefaultPipelineStages = []PipelineStage{
+	{Key: StageLead, Name: "Lead", Order: 1, Transitions: []string{StageConsultation, StageRejected},
+		FollowUp: &StageFollowUp{Title: "Call to schedule a consultation", DueInDays: 2}},
+	{Key: StageConsultation, Name: "Consultation", Order: 2, Transitions: []string{StageEnrolledService, StageLead, StageRejected},
+		FollowUp: &StageFollowUp{Title: "Follow up on the consultation", DueInDays: 3}},
+	{Key: StageEnrolledService, Name: "Enrolled in Service", Order: 3, Transitions: []string{StageRegistered, StageRejected},
+		FollowUp: &StageFollowUp{Title: "Check the registration documents", DueInDays: 7}},
+	{Key: StageRegistered, Name: "Registered", Order: 4, Transitions: []string{StageExam, StageRejected},
+		FollowUp: &StageFollowUp{Title: "Confirm the exam schedule", DueInDays: 7}},
+	{Key: StageExam, Name: "Exam", Order: 5, Transitions: []string{StageAccepted, StageRejected},
+		FollowUp: &StageFollowUp{Title: "Ask for the exam result", DueInDays: 14}},
+	{Key: StageAccepted, Name: "Accepted", Order: 6, Terminal: true, Transitions: []string{}},
+	{Key: StageRejected, Name: "Rejected", Order: 7, Terminal: true, Transitions: []string{StageLead}},
+}
@@ -1 +1,90 @@
 package models
+
+type Role string
+
+const (
+	RoleSuperAdmin Role = "superadmin"
+	RoleCounselor  Role = "counselor"
+	RoleDataEntry  Role = "data-entry"
+	RoleFinance    Role = "finance"
+	RoleViewer     Role = "viewer"
+)
+
+type Permission string
+
+const (
+	PermissionAdminManage   Permission = "admin:manage"
+	PermissionAPIKeyManage  Permission = "apikey:manage"
+	PermissionAuditRead     Permission = "audit:read"
+	PermissionStudentRead   Permission = "student:read"
+	PermissionStudentWrite  Permission = "student:write"
+	PermissionStudentDelete Permission = "student:delete"
+	PermissionStudentImport Permission = "student:import"
+	PermissionStudentAssign Permission = "student:assign"
+	PermissionCatalogRead   Permission = "catalog:read"
+	PermissionCatalogWrite  Permission = "catalog:write"
+	PermissionCatalogDelete Permission = "catalog:delete"
+	PermissionCatalogImport Permission = "catalog:import"
+	PermissionBillingRead   Permission = "billing:read"
+	PermissionBillingWrite  Permission = "billing:write"
+	PermissionAnalyticsRead Permission = "analytics:read"
+)
+
+// RolePermissions is the permission matrix checked by middleware.RequirePermission.
+// Catalog covers universities, study programs and the knowledge base; billing covers invoices and payments.
+var RolePermissions = map[Role][]Permission{
+	RoleSuperAdmin: {
+		PermissionAdminManage,
+		PermissionAPIKeyManage,
+		PermissionAuditRead,
+		PermissionStudentRead,
+		PermissionStudentWrite,
+		PermissionStudentDelete,
+		PermissionStudentImport,
+		PermissionStudentAssign,
+		PermissionCatalogRead,
+		PermissionCatalogWrite,
+		PermissionCatalogDelete,
+		PermissionCatalogImport,
+		PermissionBillingRead,
+		PermissionBillingWrite,
+		PermissionAnalyticsRead,
+	},
+	RoleCounselor: {
+		PermissionStudentRead,
+		PermissionStudentWrite,
+		PermissionCatalogRead,
+	},
+	RoleDataEntry: {
+		PermissionStudentRead,
+		PermissionStudentWrite,
+		PermissionStudentImport,
+		PermissionCatalogRead,
+		PermissionCatalogWrite,
+		PermissionCatalogImport,
+	},
+	RoleFinance: {
+		PermissionStudentRead,
+		PermissionBillingRead,
+		PermissionBillingWrite,
+		PermissionAnalyticsRead,
+	},
+	RoleViewer: {
+		PermissionStudentRead,
+		PermissionCatalogRead,
+	},
+}
+
+func (r Role) IsValid() bool {
+	_, ok := RolePermissions[r]
+	return ok
+}
+
+func (r Role) HasPermission(permission Permission) bool {
+	for _, p := range RolePermissions[r] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -6,15 +6,55 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// School is matched by NormalizedName, which is utils.NormalizeSchoolName(Name),
+// so that spelling variants of a name resolve to the same school.
 type School struct {
-	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
-	Name        string             `bson:"name,omitempty" json:"name,omitempty"`
-	Address     string             `bson:"address,omitempty" json:"address,omitempty"`
-	Province    string             `bson:"province,omitempty" json:"province,omitempty"`
-	City        string             `bson:"city,omitempty" json:"city,omitempty"`
-	SchoolLogo  string             `bson:"school_logo,omitempty" json:"school_logo,omitempty"`
-	SchoolImage string             `bson:"school_image,omitempty" json:"school_image,omitempty"`
-	Phone       string             `bson:"phone,omitempty" json:"phone,omitempty"`
-	CreatedAt   time.Time          `bson:"created_at,omitempty" json:"created_at,omitempty"`
-	UpdatedAt   time.Time          `bson:"updated_at,omitempty" json:"updated_at,omitempty"`
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	Name           string             `bson:"name,omitempty" json:"name,omitempty"`
+	NormalizedName string             `bson:"normalized_name,omitempty" json:"normalized_name,omitempty"`
+	Address        string             `bson:"address,omitempty" json:"address,omitempty"`
+	Province       string             `bson:"province,omitempty" json:"province,omitempty"`
+	City           string             `bson:"city,omitempty" json:"city,omitempty"`
+	SchoolLogo     string             `bson:"school_logo,omitempty" json:"school_logo,omitempty"`
+	SchoolImage    string             `bson:"school_image,omitempty" json:"school_image,omitempty"`
+	Phone          string             `bson:"phone,omitempty" json:"phone,omitempty"`
+	CreatedAt      time.Time          `bson:"created_at,omitempty" json:"created_at,omitempty"`
+	UpdatedAt      time.Time          `bson:"updated_at,omitempty" json:"updated_at,omitempty"`
+}
+
+// SchoolSummary is a school together with the number of students linked to it.
+type SchoolSummary struct {
+	School       `bson:",inline"`
+	StudentCount int64 `bson:"student_count" json:"student_count"`
+}
+
+type SchoolFilter struct {
+	Name     *string `json:"name,omitempty"`
+	Province *string `json:"province,omitempty"`
+	City     *string `json:"city,omitempty"`
+	Page     *int    `json:"page,omitempty"`
+	PageSize *int    `json:"pageSize,omitempty"`
+}
+
+type PagedSchools struct {
+	CurrentPage  int
+	TotalRecords int64
+	TotalPages   int
+	Records      []SchoolSummary
+}
+
+// SchoolDuplicateGroup is a set of schools whose normalized names are similar
+// enough to be the same school. SuggestedWinnerID is the school with the most
+// students, which the others would be merged into.
+type SchoolDuplicateGroup struct {
+	Normalized        string             `json:"normalized"`
+	Similarity        float64            `json:"similarity"`
+	SuggestedWinnerID primitive.ObjectID `json:"suggested_winner_id"`
+	Schools           []SchoolSummary    `json:"schools"`
+}
+
+type SchoolMergeResult struct {
+	WinnerID       primitive.ObjectID `json:"winner_id"`
+	StudentsMoved  int64              `json:"students_moved"`
+	SchoolsDeleted int64              `json:"schools_deleted"`
 }
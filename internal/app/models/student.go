@@ -7,30 +7,38 @@ import (
 )
 
 type Student struct {
-	ID               primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
-	Name             string             `bson:"name,omitempty" json:"name,omitempty"`
-	Email            string             `bson:"email,omitempty" json:"email,omitempty"`
-	School           string             `bson:"school,omitempty" json:"school,omitempty"`
-	SchoolID         primitive.ObjectID `bson:"school_id,omitempty" json:"school_id,omitempty"`
-	Interest         string             `bson:"interest,omitempty" json:"interest,omitempty"`
-	Gender           string             `bson:"gender,omitempty" json:"gender,omitempty"`
-	Phone            string             `bson:"phone,omitempty" json:"phone,omitempty"`
-	FinancialAbility string             `bson:"financial_ability,omitempty" json:"financial_ability,omitempty"`
-	Progress         string             `bson:"progress,omitempty" json:"progress,omitempty"`
-	DetailSiswaLink  string             `bson:"detailsiswa_link,omitempty" json:"detailsiswa_link,omitempty"`
-	Image            string             `bson:"image,omitempty" json:"image,omitempty"`
-	Category         string             `bson:"category,omitempty" json:"category,omitempty"`
-	Birthdate        string             `bson:"birthdate,omitempty" json:"birthdate,omitempty"`
-	TrackRecords     []TrackRecord      `bson:"track_records,omitempty" json:"track_records,omitempty"`
-	TrackLobby       []TrackLobby       `bson:"track_lobby,omitempty" json:"track_lobby,omitempty"`
-	IsActive         bool               `bson:"is_active,omitempty" json:"is_active"`
-	CreatedAt        time.Time          `bson:"created_at,omitempty" json:"created_at,omitempty"`
-	UpdatedAt        time.Time          `bson:"updated_at,omitempty" json:"updated_at,omitempty"`
+	ID               primitive.ObjectID   `bson:"_id,omitempty" json:"id,omitempty"`
+	Name             string               `bson:"name,omitempty" json:"name,omitempty"`
+	Email            string               `bson:"email,omitempty" json:"email,omitempty"`
+	School           string               `bson:"school,omitempty" json:"school,omitempty"`
+	SchoolID         primitive.ObjectID   `bson:"school_id,omitempty" json:"school_id,omitempty"`
+	Interest         string               `bson:"interest,omitempty" json:"interest,omitempty"`
+	Gender           string               `bson:"gender,omitempty" json:"gender,omitempty"`
+	Phone            string               `bson:"phone,omitempty" json:"phone,omitempty"`
+	FinancialAbility string               `bson:"financial_ability,omitempty" json:"financial_ability,omitempty"`
+	Progress         string               `bson:"progress,omitempty" json:"progress,omitempty"`
+	DetailSiswaLink  string               `bson:"detailsiswa_link,omitempty" json:"detailsiswa_link,omitempty"`
+	Image            string               `bson:"image,omitempty" json:"image,omitempty"`
+	Category         string               `bson:"category,omitempty" json:"category,omitempty"`
+	Birthdate        string               `bson:"birthdate,omitempty" json:"birthdate,omitempty"`
+	TrackRecords     []TrackRecord        `bson:"track_records,omitempty" json:"track_records,omitempty"`
+	TrackLobby       []TrackLobby         `bson:"track_lobby,omitempty" json:"track_lobby,omitempty"`
+	StageTimestamps  map[string]time.Time `bson:"stage_timestamps,omitempty" json:"stage_timestamps,omitempty"`
+	IsActive         bool                 `bson:"is_active,omitempty" json:"is_active"`
+	CreatedAt        time.Time            `bson:"created_at,omitempty" json:"created_at,omitempty"`
+	UpdatedAt        time.Time            `bson:"updated_at,omitempty" json:"updated_at,omitempty"`
+	DeletedAt        *time.Time           `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+	DeletedBy        string               `bson:"deleted_by,omitempty" json:"deleted_by,omitempty"`
+	MergedInto       primitive.ObjectID   `bson:"merged_into,omitempty" json:"merged_into,omitempty"`
+	CounselorID      primitive.ObjectID   `bson:"counselor_id,omitempty" json:"counselor_id,omitempty"`
 }
 
+// StudentFilter narrows GetAll. CounselorID is an admin id or "unassigned";
+// Mine is resolved by the handler to the calling admin's id.
 type StudentFilter struct {
 	Name             *string `bson:"name,omitempty" json:"name,omitempty"`
 	School           *string `bson:"school,omitempty" json:"school,omitempty"`
+	SchoolID         *string `bson:"school_id,omitempty" json:"school_id,omitempty"`
 	Interest         *string `bson:"interest,omitempty" json:"interest,omitempty"`
 	Gender           *string `bson:"gender,omitempty" json:"gender,omitempty"`
 	Phone            *string `bson:"phone,omitempty" json:"phone,omitempty"`
@@ -39,6 +47,8 @@ type StudentFilter struct {
 	Progress         *string `bson:"progress,omitempty" json:"progress,omitempty"`
 	Category         *string `bson:"category,omitempty" json:"category,omitempty"`
 	IsActive         *bool   `bson:"is_active,omitempty" json:"is_active,omitempty"`
+	CounselorID      *string `bson:"counselor_id,omitempty" json:"counselor_id,omitempty"`
+	Mine             *bool   `bson:"mine,omitempty" json:"mine,omitempty"`
 	Page             *int    `bson:"page,omitempty" json:"page,omitempty"`
 	PageSize         *int    `bson:"pageSize,omitempty" json:"pageSize,omitempty"`
 }
@@ -49,3 +59,36 @@ type PagedStudents struct {
 	TotalPages   int
 	Records      []Student
 }
+
+// DuplicateCandidate is a pair of students that are probably the same person.
+// Reasons lists what matched: phone, email, name, birthdate and school.
+type DuplicateCandidate struct {
+	Students       [2]Student `json:"students"`
+	Reasons        []string   `json:"reasons"`
+	NameSimilarity float64    `json:"name_similarity"`
+}
+
+// StudentComparison shows two students side by side with the fields that differ.
+type StudentComparison struct {
+	Students    [2]Student    `json:"students"`
+	Differences []FieldChange `json:"differences"`
+}
+
+// StudentMergeResult reports a merge. DroppedServices are the track records of
+// merged students for services the winner already had; the winner's record was
+// kept and these were not copied, so they need to be checked by hand.
+// RemovedAccounts counts the portal accounts of merged students that were
+// deleted because the winner already had one.
+type StudentMergeResult struct {
+	WinnerID        primitive.ObjectID `json:"winner_id"`
+	Merged          int                `json:"merged"`
+	ServicesAdded   int                `json:"services_added"`
+	DroppedServices []DroppedService   `json:"dropped_services,omitempty"`
+	RemovedAccounts int64              `json:"removed_accounts,omitempty"`
+}
+
+// DroppedService is a track record of a merged student that was not kept.
+type DroppedService struct {
+	StudentID primitive.ObjectID `json:"student_id"`
+	TrackRecord
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	TaskStatusOpen      = "open"
+	TaskStatusDone      = "done"
+	TaskStatusCancelled = "cancelled"
+)
+
+// Task sources: created by an admin or automatically when a student enters a pipeline stage.
+const (
+	TaskSourceManual = "manual"
+	TaskSourceStage  = "stage"
+)
+
+// Task is a follow-up on a student, such as "call back on Thursday". Tasks
+// created on entering a stage have Source stage and carry the stage key. An
+// auto follow-up for a student without a counselor has no assignee.
+type Task struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	StudentID   primitive.ObjectID `bson:"student_id" json:"student_id"`
+	StudentName string             `bson:"student_name,omitempty" json:"student_name,omitempty"`
+	AssigneeID  primitive.ObjectID `bson:"assignee_id,omitempty" json:"assignee_id,omitempty"`
+	Title       string             `bson:"title" json:"title"`
+	Notes       string             `bson:"notes,omitempty" json:"notes,omitempty"`
+	DueAt       time.Time          `bson:"due_at" json:"due_at"`
+	Status      string             `bson:"status" json:"status"`
+	Source      string             `bson:"source" json:"source"`
+	Stage       string             `bson:"stage,omitempty" json:"stage,omitempty"`
+	CreatedBy   Actor              `bson:"created_by" json:"created_by"`
+	CompletedAt *time.Time         `bson:"completed_at,omitempty" json:"completed_at,omitempty"`
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+func (t *Task) IsOverdue(now time.Time) bool {
+	return t.Status == TaskStatusOpen && t.DueAt.Before(now)
+}
+
+// TaskDraft is the input for a new task. DueDate is YYYY-MM-DD or RFC 3339;
+// without an assignee the task goes to the student's counselor.
+type TaskDraft struct {
+	StudentID  string `json:"student_id" binding:"required"`
+	AssigneeID string `json:"assignee_id"`
+	Title      string `json:"title" binding:"required"`
+	Notes      string `json:"notes"`
+	DueDate    string `json:"due_date" binding:"required"`
+}
+
+// TaskUpdate changes the given fields of a task only.
+type TaskUpdate struct {
+	ID         string  `json:"id" binding:"required"`
+	AssigneeID *string `json:"assignee_id,omitempty"`
+	Title      *string `json:"title,omitempty"`
+	Notes      *string `json:"notes,omitempty"`
+	DueDate    *string `json:"due_date,omitempty"`
+	Status     *string `json:"status,omitempty"`
+}
+
+// TaskFilter narrows task listings. Mine is resolved by the handler to the
+// calling admin; Overdue keeps open tasks that are past their due date.
+type TaskFilter struct {
+	StudentID  *string `json:"student_id,omitempty"`
+	AssigneeID *string `json:"assignee_id,omitempty"`
+	Status     *string `json:"status,omitempty"`
+	Mine       *bool   `json:"mine,omitempty"`
+	Overdue    *bool   `json:"overdue,omitempty"`
+	DueFrom    *string `json:"due_from,omitempty"`
+	DueTo      *string `json:"due_to,omitempty"`
+	Page       *int    `json:"page,omitempty"`
+	PageSize   *int    `json:"pageSize,omitempty"`
+}
+
+type PagedTasks struct {
+	CurrentPage  int
+	TotalRecords int64
+	TotalPages   int
+	Records      []Task
+}
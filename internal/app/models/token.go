@@ -1,10 +1,55 @@
 package models
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"time"
 
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Token is a session in tb_tokens. ExpiresAt mirrors RtExpires as a date, so
+// the TTL index can remove the session once it can no longer be refreshed.
 type Token struct {
-	ID          primitive.ObjectID `bson:"_id,omitempty"`
-	AccessToken string             `bson:"accessToken,omitempty"`
-	AccessUUID  string             `bson:"accessUUID,omitempty"`
-	AtExpires   int64              `bson:"atExpires,omitempty"`
+	ID           primitive.ObjectID `bson:"_id,omitempty"`
+	AccessToken  string             `bson:"accessToken,omitempty"`
+	AccessUUID   string             `bson:"accessUUID,omitempty"`
+	AtExpires    int64              `bson:"atExpires,omitempty"`
+	RefreshToken string             `bson:"refreshToken,omitempty"`
+	RtExpires    int64              `bson:"rtExpires,omitempty"`
+	UserAgent    string             `bson:"userAgent,omitempty"`
+	IPAddress    string             `bson:"ipAddress,omitempty"`
+	CreatedAt    time.Time          `bson:"createdAt,omitempty"`
+	LastSeenAt   time.Time          `bson:"lastSeenAt,omitempty"`
+	ExpiresAt    time.Time          `bson:"expiresAt,omitempty"`
+}
+
+// SessionInfo describes the device a login comes from.
+type SessionInfo struct {
+	UserAgent string
+	IPAddress string
+}
+
+// Session is the public view of a tb_tokens entry, without the token values.
+type Session struct {
+	ID         primitive.ObjectID `json:"id"`
+	UserAgent  string             `json:"user_agent"`
+	IPAddress  string             `json:"ip_address"`
+	CreatedAt  time.Time          `json:"created_at"`
+	LastSeenAt time.Time          `json:"last_seen_at"`
+	ExpiresAt  int64              `json:"expires_at"`
+	Current    bool               `json:"current"`
+}
+
+// LoginHistory is an entry of tb_hitory_tokens. Entries written before sessions
+// were tracked only carry accessUUID and the token itself.
+type LoginHistory struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	AccessUUID string             `bson:"accessUUID,omitempty" json:"admin_id,omitempty"`
+	Username   string             `bson:"username,omitempty" json:"username,omitempty"`
+	UserAgent  string             `bson:"userAgent,omitempty" json:"user_agent,omitempty"`
+	IPAddress  string             `bson:"ipAddress,omitempty" json:"ip_address,omitempty"`
+	Status     string             `bson:"status,omitempty" json:"status,omitempty"`
+	Reason     string             `bson:"reason,omitempty" json:"reason,omitempty"`
+	CreatedAt  time.Time          `bson:"createdAt,omitempty" json:"created_at,omitempty"`
 }
+
+const LoginStatusSuccess = "success"
@@ -2,11 +2,16 @@ package models
 
 import "time"
 
+// TrackRecord is a service taken by a student. Amount is ServiceCost in whole
+// rupiah, used for invoicing and revenue reports, and CounselorID is the admin
+// credited with the service.
 type TrackRecord struct {
 	ServiceName string    `bson:"service_name,omitempty" json:"service_name,omitempty"`
 	ServiceDate string    `bson:"service_date,omitempty" json:"service_date,omitempty"`
 	ServiceCost string    `bson:"service_cost,omitempty" json:"service_cost,omitempty"`
+	Amount      int64     `bson:"amount,omitempty" json:"amount,omitempty"`
 	Status      string    `bson:"status,omitempty" json:"status,omitempty"`
+	CounselorID string    `bson:"counselor_id,omitempty" json:"counselor_id,omitempty"`
 	CreatedAt   time.Time `bson:"created_at,omitempty" json:"created_at,omitempty"`
 	UpdatedAt   time.Time `bson:"updated_at,omitempty" json:"updated_at,omitempty"`
 }
@@ -18,8 +23,29 @@ type TrackLobby struct {
 }
 
 type ServiceFilter struct {
+	// Query is matched against the SearchIndex text index of tb_service_student.
+	Query       *string `json:"query,omitempty"`
 	Name        *string `json:"name,omitempty"`
 	ServiceName *string `json:"service_name,omitempty"`
 	ServiceDate *string `json:"service_date,omitempty"`
 	Status      *string `json:"status,omitempty"`
 }
+
+const (
+	ServiceDriftMissing    = "missing"
+	ServiceDriftMismatched = "mismatched"
+	ServiceDriftOrphaned   = "orphaned"
+)
+
+// ServiceDrift is a tb_service_student record that does not match the track records in tb_students.
+type ServiceDrift struct {
+	StudentID string `json:"student_id"`
+	Name      string `json:"name"`
+	Issue     string `json:"issue"`
+}
+
+type ServiceReconcileReport struct {
+	Checked  int            `json:"checked"`
+	Repaired int            `json:"repaired"`
+	Drifts   []ServiceDrift `json:"drifts"`
+}
@@ -18,6 +18,8 @@ type University struct {
 	UpdatedAt   time.Time          `bson:"updated_at,omitempty" json:"updated_at,omitempty"`
 	Contact     Contact            `bson:"contact,omitempty" json:"contact,omitempty"`
 	SocialMedia []SocialMedia      `bson:"social_media,omitempty" json:"social_media,omitempty"`
+	DeletedAt   *time.Time         `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+	DeletedBy   string             `bson:"deleted_by,omitempty" json:"deleted_by,omitempty"`
 }
 
 type Contact struct {
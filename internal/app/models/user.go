@@ -1 +1,45 @@
 package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// User is the portal account of a student. It is separate from Admin and
+// always points to exactly one Student record.
+type User struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	StudentID   primitive.ObjectID `bson:"student_id,omitempty" json:"student_id,omitempty"`
+	Email       string             `bson:"email,omitempty" json:"email,omitempty"`
+	Password    string             `bson:"password,omitempty" json:"-"`
+	LastLoginAt time.Time          `bson:"last_login_at,omitempty" json:"last_login_at,omitempty"`
+	CreatedAt   time.Time          `bson:"created_at,omitempty" json:"created_at,omitempty"`
+	UpdatedAt   time.Time          `bson:"updated_at,omitempty" json:"updated_at,omitempty"`
+}
+
+type UserToken struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty"`
+	UserID      primitive.ObjectID `bson:"user_id,omitempty"`
+	AccessToken string             `bson:"access_token,omitempty"`
+	ExpiresAt   int64              `bson:"expires_at,omitempty"`
+	CreatedAt   time.Time          `bson:"created_at,omitempty"`
+}
+
+// MagicLink is a single-use login link. Only the SHA-256 hash of the token is stored.
+type MagicLink struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	UserID    primitive.ObjectID `bson:"user_id,omitempty"`
+	TokenHash string             `bson:"token_hash,omitempty"`
+	ExpiresAt time.Time          `bson:"expires_at,omitempty"`
+	UsedAt    *time.Time         `bson:"used_at,omitempty"`
+	CreatedAt time.Time          `bson:"created_at,omitempty"`
+}
+
+// StudentSelfUpdate lists the fields a student may change on their own record.
+type StudentSelfUpdate struct {
+	Phone     string `json:"phone,omitempty"`
+	Interest  string `json:"interest,omitempty"`
+	Image     string `json:"image,omitempty"`
+	Birthdate string `json:"birthdate,omitempty"`
+}
@@ -2,7 +2,7 @@ package repository
 
 import (
 	"context"
-	"log"
+	"time"
 
 	"elible/internal/app/models"
 	"elible/internal/config"
@@ -10,6 +10,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type AdminRepository struct {
@@ -54,39 +55,155 @@ func (r *AdminRepository) FindByUsername(username string) (*models.Admin, error)
 	return &admin, nil
 }
 
+// EnsureTokenIndexes creates the TTL index that removes sessions once their
+// refresh token expired, and gives sessions stored before it an expiry.
+func (r *AdminRepository) EnsureTokenIndexes() error {
+	TokenCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_tokens")
+	ctx := context.Background()
+
+	_, err := TokenCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+		{
+			Keys: bson.D{{Key: "accessUUID", Value: 1}, {Key: "userAgent", Value: 1}, {Key: "ipAddress", Value: 1}},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = TokenCollection.UpdateMany(
+		ctx,
+		bson.M{"expiresAt": bson.M{"$exists": false}, "rtExpires": bson.M{"$exists": true}},
+		mongo.Pipeline{{{Key: "$set", Value: bson.M{"expiresAt": bson.M{"$toDate": bson.M{"$multiply": bson.A{"$rtExpires", 1000}}}}}}},
+	)
+	return err
+}
+
+// EnsureLoginAttemptIndexes removes failed login counters once their last
+// failure is older than window. A lockout always ends within the window, so
+// nothing that still blocks a login is removed.
+func (r *AdminRepository) EnsureLoginAttemptIndexes(window time.Duration) error {
+	AttemptCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_login_attempts")
+	ctx := context.Background()
+
+	_, err := AttemptCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "lastFailedAt", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(int32(window.Seconds())),
+	})
+	return err
+}
+
+// SaveToken stores a new session. Every device gets its own entry, so logging
+// in elsewhere does not end existing sessions, but logging in again from the
+// same device, i.e. the same user agent and IP address, replaces its session.
 func (r *AdminRepository) SaveToken(td *models.Token) error {
 	TokenCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_tokens")
+	ctx := context.Background()
+
+	// Use Jakarta's time zone
+	location, _ := time.LoadLocation("Asia/Jakarta")
+
+	// set createdAt and lastSeenAt fields
+	td.ID = primitive.NilObjectID
+	td.CreatedAt = time.Now().In(location)
+	td.LastSeenAt = time.Now().In(location)
+	td.ExpiresAt = time.Unix(td.RtExpires, 0)
+
+	// Empty values are not stored, and a nil filter value also matches a missing field
+	device := bson.M{"accessUUID": td.AccessUUID, "userAgent": nil, "ipAddress": nil}
+	if td.UserAgent != "" {
+		device["userAgent"] = td.UserAgent
+	}
+	if td.IPAddress != "" {
+		device["ipAddress"] = td.IPAddress
+	}
+
+	var saved models.Token
+	err := TokenCollection.FindOneAndReplace(
+		ctx,
+		device,
+		td,
+		options.FindOneAndReplace().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&saved)
+	if err != nil {
+		return err
+	}
+	td.ID = saved.ID
+
+	return nil
+}
+
+func (r *AdminRepository) RecordLogin(entry *models.LoginHistory) error {
+	HistoryCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_hitory_tokens")
+	ctx := context.Background()
+
+	location, _ := time.LoadLocation("Asia/Jakarta")
+	entry.CreatedAt = time.Now().In(location)
+
+	_, err := HistoryCollection.InsertOne(ctx, entry)
+	return err
+}
+
+func (r *AdminRepository) ListLoginHistory(accessUUID string, limit int64) ([]models.LoginHistory, error) {
 	HistoryCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_hitory_tokens")
 	ctx := context.Background()
 
-	// Delete any existing token associated with the same access_uuid
-	_, err := TokenCollection.DeleteOne(ctx, bson.M{"accessUUID": td.AccessUUID})
+	findOptions := options.Find().SetSort(bson.M{"_id": -1}).SetLimit(limit)
+	cursor, err := HistoryCollection.Find(ctx, bson.M{"accessUUID": accessUUID}, findOptions)
 	if err != nil {
-		log.Printf("Error while deleting old token from db, Reason: %v\n", err)
-		// You might want to handle this error, instead of just logging
+		return nil, err
 	}
+	defer cursor.Close(ctx)
 
-	// location, _ := time.LoadLocation("Asia/Jakarta")
+	var history []models.LoginHistory
+	if err := cursor.All(ctx, &history); err != nil {
+		return nil, err
+	}
 
-	// // set created_at and updated_at fields
-	// td.CreatedAt = time.Now().In(location)
-	// td.UpdatedAt = time.Now().In(location)
+	return history, nil
+}
 
-	// Insert the new token
-	_, err = TokenCollection.InsertOne(ctx, td)
+// ListSessions returns the sessions of an admin that can still be used or refreshed.
+func (r *AdminRepository) ListSessions(accessUUID string) ([]models.Token, error) {
+	TokenCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_tokens")
+	ctx := context.Background()
 
+	now := time.Now().Unix()
+	filter := bson.M{
+		"accessUUID": accessUUID,
+		"$or": []bson.M{
+			{"atExpires": bson.M{"$gt": now}},
+			{"rtExpires": bson.M{"$gt": now}},
+		},
+	}
+
+	cursor, err := TokenCollection.Find(ctx, filter, options.Find().SetSort(bson.M{"lastSeenAt": -1}))
 	if err != nil {
-		return err
+		return nil, err
 	}
+	defer cursor.Close(ctx)
 
-	// Insert the new token into history collection
-	_, err = HistoryCollection.InsertOne(ctx, td)
+	var tokens []models.Token
+	if err := cursor.All(ctx, &tokens); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
 
+func (r *AdminRepository) DeleteSession(accessUUID string, id primitive.ObjectID) (bool, error) {
+	TokenCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_tokens")
+	ctx := context.Background()
+
+	res, err := TokenCollection.DeleteOne(ctx, bson.M{"_id": id, "accessUUID": accessUUID})
 	if err != nil {
-		return err
+		return false, err
 	}
 
-	return nil
+	return res.DeletedCount > 0, nil
 }
 
 func (r *AdminRepository) DeleteToken(accessUUID string) error {
@@ -102,6 +219,64 @@ func (r *AdminRepository) DeleteToken(accessUUID string) error {
 	return nil
 }
 
+func (r *AdminRepository) DeleteTokenByValue(accessToken string) error {
+	TokenCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_tokens")
+	ctx := context.Background()
+
+	_, err := TokenCollection.DeleteOne(ctx, bson.M{"accessToken": accessToken})
+	return err
+}
+
+func (r *AdminRepository) DeleteAllTokens(accessUUID string) error {
+	TokenCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_tokens")
+	ctx := context.Background()
+
+	_, err := TokenCollection.DeleteMany(ctx, bson.M{"accessUUID": accessUUID})
+	return err
+}
+
+func (r *AdminRepository) FindByRefreshToken(refreshToken string) (*models.Token, error) {
+	TokenCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_tokens")
+	ctx := context.Background()
+
+	var token models.Token
+	err := TokenCollection.FindOne(ctx, bson.M{"refreshToken": refreshToken}).Decode(&token)
+
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// RotateToken replaces the access and refresh token of an existing entry, so a
+// refresh token can only ever be used once. It reports false when the entry no
+// longer holds oldRefreshToken, i.e. another request already rotated it.
+func (r *AdminRepository) RotateToken(id primitive.ObjectID, oldRefreshToken string, td *models.Token) (bool, error) {
+	TokenCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_tokens")
+	ctx := context.Background()
+
+	update := bson.M{
+		"$set": bson.M{
+			"accessToken":  td.AccessToken,
+			"atExpires":    td.AtExpires,
+			"refreshToken": td.RefreshToken,
+			"rtExpires":    td.RtExpires,
+			"expiresAt":    time.Unix(td.RtExpires, 0),
+		},
+	}
+
+	res, err := TokenCollection.UpdateOne(ctx, bson.M{"_id": id, "refreshToken": oldRefreshToken}, update)
+	if err != nil {
+		return false, err
+	}
+
+	return res.MatchedCount > 0, nil
+}
+
 func (r *AdminRepository) FetchToken(accessUUID string) (*models.Token, error) {
 	TokenCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_tokens")
 	ctx := context.Background()
@@ -122,8 +297,13 @@ func (r *AdminRepository) GetAdminByToken(tokens string) (*models.Admin, error)
 	TokenCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_tokens")
 	ctx := context.Background()
 
+	// Finding the token also records when the session was last used
 	var token models.Token
-	err := TokenCollection.FindOne(ctx, bson.M{"accessToken": tokens}).Decode(&token)
+	err := TokenCollection.FindOneAndUpdate(
+		ctx,
+		bson.M{"accessToken": tokens, "atExpires": bson.M{"$gt": time.Now().Unix()}},
+		bson.M{"$set": bson.M{"lastSeenAt": time.Now()}},
+	).Decode(&token)
 
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -138,7 +318,7 @@ func (r *AdminRepository) GetAdminByToken(tokens string) (*models.Admin, error)
 	}
 
 	var admin models.Admin
-	err = AdminCollection.FindOne(ctx, bson.M{"_id": objectId}).Decode(&admin)
+	err = AdminCollection.FindOne(ctx, bson.M{"_id": objectId, "disabled": bson.M{"$ne": true}}).Decode(&admin)
 
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -166,3 +346,308 @@ func (r *AdminRepository) GetTokenByValue(tokenValue string) (*models.Token, err
 
 	return &token, nil
 }
+
+func (r *AdminRepository) FindByID(id primitive.ObjectID) (*models.Admin, error) {
+	AdminCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_admins")
+	ctx := context.Background()
+
+	var admin models.Admin
+	err := AdminCollection.FindOne(ctx, bson.M{"_id": id}).Decode(&admin)
+
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &admin, nil
+}
+
+func (r *AdminRepository) UpdateRole(id primitive.ObjectID, role models.Role) error {
+	AdminCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_admins")
+	ctx := context.Background()
+
+	_, err := AdminCollection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"role": role}})
+	return err
+}
+
+func (r *AdminRepository) CountByRole(role models.Role) (int64, error) {
+	AdminCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_admins")
+	ctx := context.Background()
+
+	return AdminCollection.CountDocuments(ctx, bson.M{"role": role, "disabled": bson.M{"$ne": true}})
+}
+
+// FindOldest returns the first admin ever created, or nil when there are none.
+func (r *AdminRepository) FindOldest() (*models.Admin, error) {
+	AdminCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_admins")
+	ctx := context.Background()
+
+	var admin models.Admin
+	err := AdminCollection.FindOne(ctx, bson.M{}, options.FindOne().SetSort(bson.M{"_id": 1})).Decode(&admin)
+
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &admin, nil
+}
+
+func (r *AdminRepository) List() ([]models.Admin, error) {
+	AdminCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_admins")
+	ctx := context.Background()
+
+	cursor, err := AdminCollection.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"username": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var admins []models.Admin
+	if err := cursor.All(ctx, &admins); err != nil {
+		return nil, err
+	}
+
+	return admins, nil
+}
+
+// ListActiveByRole returns the enabled admins with the given role, oldest first.
+func (r *AdminRepository) ListActiveByRole(role models.Role) ([]models.Admin, error) {
+	AdminCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_admins")
+	ctx := context.Background()
+
+	cursor, err := AdminCollection.Find(ctx, bson.M{"role": role, "disabled": bson.M{"$ne": true}}, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var admins []models.Admin
+	if err := cursor.All(ctx, &admins); err != nil {
+		return nil, err
+	}
+
+	return admins, nil
+}
+
+func (r *AdminRepository) Update(id primitive.ObjectID, fields bson.M) error {
+	AdminCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_admins")
+	ctx := context.Background()
+
+	fields["updatedAt"] = time.Now()
+
+	_, err := AdminCollection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": fields})
+	return err
+}
+
+func (r *AdminRepository) Delete(id primitive.ObjectID) error {
+	AdminCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_admins")
+	ctx := context.Background()
+
+	_, err := AdminCollection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+// DeleteOtherTokens ends every session of the admin except the one using keepToken.
+func (r *AdminRepository) DeleteOtherTokens(accessUUID string, keepToken string) error {
+	TokenCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_tokens")
+	ctx := context.Background()
+
+	_, err := TokenCollection.DeleteMany(ctx, bson.M{"accessUUID": accessUUID, "accessToken": bson.M{"$ne": keepToken}})
+	return err
+}
+
+func (r *AdminRepository) FindByEmail(email string) (*models.Admin, error) {
+	AdminCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_admins")
+	ctx := context.Background()
+
+	var admin models.Admin
+	err := AdminCollection.FindOne(ctx, bson.M{"email": email}).Decode(&admin)
+
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &admin, nil
+}
+
+// SavePasswordReset stores a new reset token and invalidates older unused ones of the same admin.
+func (r *AdminRepository) SavePasswordReset(reset *models.PasswordReset) error {
+	ResetCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_password_resets")
+	ctx := context.Background()
+
+	now := time.Now()
+	_, err := ResetCollection.UpdateMany(ctx, bson.M{"adminID": reset.AdminID, "usedAt": bson.M{"$exists": false}}, bson.M{"$set": bson.M{"usedAt": now}})
+	if err != nil {
+		return err
+	}
+
+	reset.CreatedAt = now
+	_, err = ResetCollection.InsertOne(ctx, reset)
+	return err
+}
+
+// UsePasswordReset atomically marks a valid reset token as used and returns it.
+func (r *AdminRepository) UsePasswordReset(tokenHash string) (*models.PasswordReset, error) {
+	ResetCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_password_resets")
+	ctx := context.Background()
+
+	now := time.Now()
+	filter := bson.M{
+		"tokenHash": tokenHash,
+		"usedAt":    bson.M{"$exists": false},
+		"expiresAt": bson.M{"$gt": now},
+	}
+
+	var reset models.PasswordReset
+	err := ResetCollection.FindOneAndUpdate(ctx, filter, bson.M{"$set": bson.M{"usedAt": now}}).Decode(&reset)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &reset, nil
+}
+
+// UseTOTPStep records the time step of an accepted code; it fails when the step
+// was already used, so a code cannot be replayed.
+func (r *AdminRepository) UseTOTPStep(id primitive.ObjectID, step int64) (bool, error) {
+	AdminCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_admins")
+	ctx := context.Background()
+
+	filter := bson.M{
+		"_id": id,
+		"$or": []bson.M{
+			{"totpLastStep": bson.M{"$exists": false}},
+			{"totpLastStep": bson.M{"$lt": step}},
+		},
+	}
+
+	res, err := AdminCollection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"totpLastStep": step}})
+	if err != nil {
+		return false, err
+	}
+
+	return res.ModifiedCount > 0, nil
+}
+
+func (r *AdminRepository) UseRecoveryCode(id primitive.ObjectID, codeHash string) (bool, error) {
+	AdminCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_admins")
+	ctx := context.Background()
+
+	res, err := AdminCollection.UpdateOne(ctx, bson.M{"_id": id, "recoveryCodes": codeHash}, bson.M{"$pull": bson.M{"recoveryCodes": codeHash}})
+	if err != nil {
+		return false, err
+	}
+
+	return res.ModifiedCount > 0, nil
+}
+
+func (r *AdminRepository) ClearTOTP(id primitive.ObjectID) error {
+	AdminCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_admins")
+	ctx := context.Background()
+
+	update := bson.M{
+		"$unset": bson.M{"totpSecret": "", "totpEnabled": "", "totpLastStep": "", "recoveryCodes": ""},
+		"$set":   bson.M{"updatedAt": time.Now()},
+	}
+
+	_, err := AdminCollection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	return err
+}
+
+func (r *AdminRepository) FindLoginAttempt(key string) (*models.LoginAttempt, error) {
+	AttemptCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_login_attempts")
+	ctx := context.Background()
+
+	var attempt models.LoginAttempt
+	err := AttemptCollection.FindOne(ctx, bson.M{"key": key}).Decode(&attempt)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &attempt, nil
+}
+
+// IncrementLoginAttempt counts one more failed login for the key in a single
+// atomic update, so parallel failures cannot overwrite each other. The count
+// starts again at one when the last failure is older than windowStart, and
+// the key is locked until lockedUntil once the count reaches lockoutAfter.
+func (r *AdminRepository) IncrementLoginAttempt(key string, now, windowStart time.Time, lockoutAfter int, lockedUntil time.Time) (*models.LoginAttempt, error) {
+	AttemptCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_login_attempts")
+	ctx := context.Background()
+
+	inWindow := bson.M{"$gt": bson.A{"$lastFailedAt", windowStart}}
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"failedCount": bson.M{"$cond": bson.A{
+				inWindow,
+				bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$failedCount", 0}}, 1}},
+				1,
+			}},
+			"lastFailedAt": now,
+		}}},
+		{{Key: "$set", Value: bson.M{
+			"lockedUntil": bson.M{"$cond": bson.A{
+				bson.M{"$gte": bson.A{"$failedCount", lockoutAfter}},
+				lockedUntil,
+				"$lockedUntil",
+			}},
+		}}},
+	}
+
+	var attempt models.LoginAttempt
+	err := AttemptCollection.FindOneAndUpdate(
+		ctx,
+		bson.M{"key": key},
+		update,
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&attempt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &attempt, nil
+}
+
+func (r *AdminRepository) DeleteLoginAttempt(key string) (bool, error) {
+	AttemptCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_login_attempts")
+	ctx := context.Background()
+
+	res, err := AttemptCollection.DeleteOne(ctx, bson.M{"key": key})
+	if err != nil {
+		return false, err
+	}
+
+	return res.DeletedCount > 0, nil
+}
+
+func (r *AdminRepository) ListLockedAttempts() ([]models.LoginAttempt, error) {
+	AttemptCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_login_attempts")
+	ctx := context.Background()
+
+	cursor, err := AttemptCollection.Find(ctx, bson.M{"lockedUntil": bson.M{"$gt": time.Now()}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var attempts []models.LoginAttempt
+	if err := cursor.All(ctx, &attempts); err != nil {
+		return nil, err
+	}
+
+	return attempts, nil
+}
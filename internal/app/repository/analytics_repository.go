@@ -0,0 +1,314 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"elible/internal/app/models"
+	"elible/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// revenueKeys maps each grouping to the expression used as the $group key
+// once track_records has been unwound.
+var revenueKeys = map[string]interface{}{
+	models.RevenueByService: "$track_records.service_name",
+	models.RevenueByMonth: bson.M{"$dateToString": bson.M{
+		"format":   "%Y-%m",
+		"date":     "$track_records.created_at",
+		"timezone": "Asia/Jakarta",
+	}},
+	models.RevenueBySchool:    bson.M{"$ifNull": bson.A{"$school", ""}},
+	models.RevenueByCounselor: bson.M{"$ifNull": bson.A{"$track_records.counselor_id", ""}},
+}
+
+// AnalyticsRepository runs the reporting aggregations. Track records are read
+// from tb_students, which is the source of truth for services.
+type AnalyticsRepository struct {
+	MongoClient *mongo.Client
+	cfg         *config.Config
+}
+
+func NewAnalyticsRepository(cfg *config.Config, mongoClient *mongo.Client) *AnalyticsRepository {
+	return &AnalyticsRepository{
+		cfg:         cfg,
+		MongoClient: mongoClient,
+	}
+}
+
+// RevenueBy sums the amounts of the track records per service, month, school or counselor.
+func (r *AnalyticsRepository) RevenueBy(grouping string, filter *models.AnalyticsFilter) ([]models.RevenueRow, error) {
+	collection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_students")
+	ctx := context.Background()
+
+	pipeline, err := trackRecordStages(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	amount := bson.M{"$ifNull": bson.A{"$track_records.amount", 0}}
+	pipeline = append(pipeline,
+		bson.D{{Key: "$group", Value: bson.M{
+			"_id":      revenueKeys[grouping],
+			"services": bson.M{"$sum": 1},
+			"unpriced": bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$gt": bson.A{amount, 0}}, 0, 1}}},
+			"revenue":  bson.M{"$sum": amount},
+		}}},
+	)
+
+	// Months read best in order, the other groupings by how much they bring in
+	if grouping == models.RevenueByMonth {
+		pipeline = append(pipeline, bson.D{{Key: "$sort", Value: bson.M{"_id": 1}}})
+	} else {
+		pipeline = append(pipeline, bson.D{{Key: "$sort", Value: bson.D{{Key: "revenue", Value: -1}, {Key: "_id", Value: 1}}}})
+	}
+
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rows []models.RevenueRow
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+
+	if grouping == models.RevenueByCounselor {
+		if err := r.labelCounselors(ctx, rows); err != nil {
+			return nil, err
+		}
+	}
+
+	return rows, nil
+}
+
+// CountByStatus counts the track records per status.
+func (r *AnalyticsRepository) CountByStatus(filter *models.AnalyticsFilter) ([]models.StatusCount, error) {
+	collection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_students")
+	ctx := context.Background()
+
+	pipeline, err := trackRecordStages(filter)
+	if err != nil {
+		return nil, err
+	}
+	pipeline = append(pipeline,
+		bson.D{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"$ifNull": bson.A{"$track_records.status", ""}},
+			"count": bson.M{"$sum": 1},
+		}}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}}},
+	)
+
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var counts []models.StatusCount
+	if err := cursor.All(ctx, &counts); err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// Outstanding totals the invoices created in the filtered period that were not voided.
+func (r *AnalyticsRepository) Outstanding(filter *models.AnalyticsFilter) (*models.OutstandingSummary, error) {
+	collection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_invoices")
+	ctx := context.Background()
+
+	match := bson.M{"status": bson.M{"$ne": models.InvoiceStatusVoid}}
+	createdAt, err := dateRange(filter.DateFrom, filter.DateTo)
+	if err != nil {
+		return nil, err
+	}
+	if len(createdAt) > 0 {
+		match["created_at"] = createdAt
+	}
+
+	balance := bson.M{"$subtract": bson.A{"$total", "$amount_paid"}}
+	open := bson.M{"$in": bson.A{"$status", bson.A{models.InvoiceStatusUnpaid, models.InvoiceStatusPartial}}}
+	overdue := bson.M{"$and": bson.A{open, bson.M{"$lt": bson.A{"$due_date", time.Now()}}}}
+
+	pipeline := mongo.Pipeline{
+		bson.D{{Key: "$match", Value: match}},
+		bson.D{{Key: "$group", Value: bson.M{
+			"_id":              nil,
+			"open_invoices":    bson.M{"$sum": bson.M{"$cond": bson.A{open, 1, 0}}},
+			"outstanding":      bson.M{"$sum": bson.M{"$cond": bson.A{open, balance, 0}}},
+			"overdue_invoices": bson.M{"$sum": bson.M{"$cond": bson.A{overdue, 1, 0}}},
+			"overdue":          bson.M{"$sum": bson.M{"$cond": bson.A{overdue, balance, 0}}},
+			"invoiced":         bson.M{"$sum": "$total"},
+			"collected":        bson.M{"$sum": "$amount_paid"},
+		}}},
+	}
+
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	summary := &models.OutstandingSummary{}
+	if cursor.Next(ctx) {
+		if err := cursor.Decode(summary); err != nil {
+			return nil, err
+		}
+	}
+
+	return summary, cursor.Err()
+}
+
+// trackRecordStages unwinds the track records of the students that are not
+// deleted and keeps the ones matching the filter.
+func trackRecordStages(filter *models.AnalyticsFilter) (mongo.Pipeline, error) {
+	match := bson.M{}
+	createdAt, err := dateRange(filter.DateFrom, filter.DateTo)
+	if err != nil {
+		return nil, err
+	}
+	if len(createdAt) > 0 {
+		match["track_records.created_at"] = createdAt
+	}
+	if filter.Status != nil && *filter.Status != "" {
+		match["track_records.status"] = *filter.Status
+	}
+
+	return mongo.Pipeline{
+		bson.D{{Key: "$match", Value: bson.M{"deleted_at": notDeleted}}},
+		bson.D{{Key: "$unwind", Value: "$track_records"}},
+		bson.D{{Key: "$match", Value: match}},
+	}, nil
+}
+
+// dateRange turns inclusive YYYY-MM-DD days in Jakarta time into a range
+// condition. It is empty when neither day is given.
+func dateRange(from, to *string) (bson.M, error) {
+	location, _ := time.LoadLocation("Asia/Jakarta")
+	condition := bson.M{}
+	if from != nil && *from != "" {
+		day, err := time.ParseInLocation("2006-01-02", *from, location)
+		if err != nil {
+			return nil, err
+		}
+		condition["$gte"] = day
+	}
+	if to != nil && *to != "" {
+		day, err := time.ParseInLocation("2006-01-02", *to, location)
+		if err != nil {
+			return nil, err
+		}
+		condition["$lt"] = day.AddDate(0, 0, 1)
+	}
+	return condition, nil
+}
+
+// labelCounselors fills in the admin names of the counselor rows.
+func (r *AnalyticsRepository) labelCounselors(ctx context.Context, rows []models.RevenueRow) error {
+	var ids []primitive.ObjectID
+	for _, row := range rows {
+		if id, err := primitive.ObjectIDFromHex(row.Key); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	collection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_admins")
+	cursor, err := collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var admins []models.Admin
+	if err := cursor.All(ctx, &admins); err != nil {
+		return err
+	}
+
+	names := make(map[string]string, len(admins))
+	for _, admin := range admins {
+		names[admin.ID.Hex()] = admin.FullName
+		if admin.FullName == "" {
+			names[admin.ID.Hex()] = admin.Username
+		}
+	}
+	for i := range rows {
+		rows[i].Label = names[rows[i].Key]
+	}
+	return nil
+}
+
+// FunnelStudents loads the pipeline history of the students that are not deleted.
+func (r *AnalyticsRepository) FunnelStudents(filter *models.FunnelFilter) ([]models.Student, error) {
+	collection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_students")
+	ctx := context.Background()
+
+	bsonFilter := bson.M{"deleted_at": notDeleted}
+	createdAt, err := dateRange(filter.DateFrom, filter.DateTo)
+	if err != nil {
+		return nil, err
+	}
+	if len(createdAt) > 0 {
+		bsonFilter["created_at"] = createdAt
+	}
+	if filter.School != nil && *filter.School != "" {
+		bsonFilter["school"] = *filter.School
+	}
+	if filter.Interest != nil && *filter.Interest != "" {
+		bsonFilter["interest"] = *filter.Interest
+	}
+
+	projection := bson.M{
+		"school":               1,
+		"school_id":            1,
+		"interest":             1,
+		"progress":             1,
+		"track_lobby":          1,
+		"stage_timestamps":     1,
+		"track_records.amount": 1,
+		"created_at":           1,
+	}
+	cursor, err := collection.Find(ctx, bsonFilter, options.Find().SetProjection(projection))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var students []models.Student
+	if err := cursor.All(ctx, &students); err != nil {
+		return nil, err
+	}
+
+	return students, nil
+}
+
+// SchoolProvinces maps school IDs to the province of the school.
+func (r *AnalyticsRepository) SchoolProvinces() (map[primitive.ObjectID]string, error) {
+	collection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_schools")
+	ctx := context.Background()
+
+	cursor, err := collection.Find(ctx, bson.M{}, options.Find().SetProjection(bson.M{"province": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var schools []models.School
+	if err := cursor.All(ctx, &schools); err != nil {
+		return nil, err
+	}
+
+	provinces := make(map[primitive.ObjectID]string, len(schools))
+	for _, school := range schools {
+		provinces[school.ID] = school.Province
+	}
+	return provinces, nil
+}
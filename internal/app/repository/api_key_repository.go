@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"elible/internal/app/models"
+	"elible/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type APIKeyRepository struct {
+	MongoClient *mongo.Client
+	cfg         *config.Config
+}
+
+func NewAPIKeyRepository(cfg *config.Config, mongoClient *mongo.Client) *APIKeyRepository {
+	return &APIKeyRepository{
+		cfg:         cfg,
+		MongoClient: mongoClient,
+	}
+}
+
+func (r *APIKeyRepository) Create(key *models.APIKey) error {
+	collection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_api_keys")
+	ctx := context.Background()
+
+	key.CreatedAt = time.Now()
+	result, err := collection.InsertOne(ctx, key)
+	if err != nil {
+		return err
+	}
+	key.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// FindActiveByHash returns the unrevoked key with the given hash and records that it was used.
+func (r *APIKeyRepository) FindActiveByHash(keyHash string) (*models.APIKey, error) {
+	collection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_api_keys")
+	ctx := context.Background()
+
+	var key models.APIKey
+	err := collection.FindOneAndUpdate(
+		ctx,
+		bson.M{"keyHash": keyHash, "revokedAt": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"lastUsedAt": time.Now()}},
+	).Decode(&key)
+
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+func (r *APIKeyRepository) List() ([]models.APIKey, error) {
+	collection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_api_keys")
+	ctx := context.Background()
+
+	cursor, err := collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"createdAt": -1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var keys []models.APIKey
+	if err := cursor.All(ctx, &keys); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// Revoke reports false when the key does not exist or was already revoked.
+func (r *APIKeyRepository) Revoke(id primitive.ObjectID) (bool, error) {
+	collection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_api_keys")
+	ctx := context.Background()
+
+	result, err := collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id, "revokedAt": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revokedAt": time.Now()}},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount > 0, nil
+}
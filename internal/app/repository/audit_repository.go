@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"elible/internal/app/models"
+	"elible/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type AuditRepository struct {
+	MongoClient *mongo.Client
+	cfg         *config.Config
+}
+
+func NewAuditRepository(cfg *config.Config, mongoClient *mongo.Client) *AuditRepository {
+	return &AuditRepository{
+		cfg:         cfg,
+		MongoClient: mongoClient,
+	}
+}
+
+func (r *AuditRepository) Create(entry *models.AuditLog) error {
+	auditCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_audit_logs")
+	ctx := context.Background()
+
+	location, _ := time.LoadLocation("Asia/Jakarta")
+	entry.CreatedAt = time.Now().In(location)
+
+	_, err := auditCollection.InsertOne(ctx, entry)
+	return err
+}
+
+func (r *AuditRepository) List(filter *models.AuditFilter) (*models.PagedAuditLogs, error) {
+	auditCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_audit_logs")
+	ctx := context.Background()
+
+	bsonFilter := make(bson.M)
+	if filter.ActorID != nil && *filter.ActorID != "" {
+		bsonFilter["actor.id"] = *filter.ActorID
+	}
+	if filter.Action != nil && *filter.Action != "" {
+		bsonFilter["action"] = *filter.Action
+	}
+	if filter.Collection != nil && *filter.Collection != "" {
+		bsonFilter["collection"] = *filter.Collection
+	}
+	if filter.DocumentID != nil && *filter.DocumentID != "" {
+		bsonFilter["document_id"] = *filter.DocumentID
+	}
+
+	// Dates are inclusive days in YYYY-MM-DD, Jakarta time
+	location, _ := time.LoadLocation("Asia/Jakarta")
+	createdAt := bson.M{}
+	if filter.DateFrom != nil && *filter.DateFrom != "" {
+		from, err := time.ParseInLocation("2006-01-02", *filter.DateFrom, location)
+		if err != nil {
+			return nil, err
+		}
+		createdAt["$gte"] = from
+	}
+	if filter.DateTo != nil && *filter.DateTo != "" {
+		to, err := time.ParseInLocation("2006-01-02", *filter.DateTo, location)
+		if err != nil {
+			return nil, err
+		}
+		createdAt["$lt"] = to.AddDate(0, 0, 1)
+	}
+	if len(createdAt) > 0 {
+		bsonFilter["created_at"] = createdAt
+	}
+
+	page, pageSize := 1, 20
+	if filter.Page != nil && *filter.Page > 0 {
+		page = *filter.Page
+	}
+	if filter.PageSize != nil && *filter.PageSize > 0 {
+		pageSize = *filter.PageSize
+	}
+
+	findOptions := options.Find().
+		SetSort(bson.M{"created_at": -1}).
+		SetSkip(int64((page - 1) * pageSize)).
+		SetLimit(int64(pageSize))
+
+	cursor, err := auditCollection.Find(ctx, bsonFilter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var entries []models.AuditLog
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+
+	total, err := auditCollection.CountDocuments(ctx, bsonFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.PagedAuditLogs{
+		CurrentPage:  page,
+		TotalRecords: total,
+		TotalPages:   int(math.Ceil(float64(total) / float64(pageSize))),
+		Records:      entries,
+	}, nil
+}
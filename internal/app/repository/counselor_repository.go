@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"elible/internal/app/models"
+	"elible/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type CounselorRepository struct {
+	MongoClient *mongo.Client
+	cfg         *config.Config
+}
+
+func NewCounselorRepository(cfg *config.Config, mongoClient *mongo.Client) *CounselorRepository {
+	return &CounselorRepository{
+		cfg:         cfg,
+		MongoClient: mongoClient,
+	}
+}
+
+// NextRoundRobin reserves n turns of the round robin and returns the first one.
+// The turn is shared by all instances through tb_counters.
+func (r *CounselorRepository) NextRoundRobin(n int) (int64, error) {
+	collection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_counters")
+	ctx := context.Background()
+
+	var counter struct {
+		Seq int64 `bson:"seq"`
+	}
+	err := collection.FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": "counselor-round-robin"},
+		bson.M{"$inc": bson.M{"seq": n}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&counter)
+	if err != nil {
+		return 0, err
+	}
+
+	return counter.Seq - int64(n), nil
+}
+
+// Caseloads counts the students that are not in the trash per counselor.
+// Students without a counselor are grouped under a zero id.
+func (r *CounselorRepository) Caseloads() ([]models.CounselorCaseload, error) {
+	studentCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_students")
+	ctx := context.Background()
+
+	activeCount := bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$is_active", true}}, 1, 0}}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"deleted_at": notDeleted}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":    bson.M{"$ifNull": bson.A{"$counselor_id", primitive.NilObjectID}},
+			"active": bson.M{"$sum": activeCount},
+			"total":  bson.M{"$sum": 1},
+		}}},
+		{{Key: "$addFields", Value: bson.M{"inactive": bson.M{"$subtract": bson.A{"$total", "$active"}}}}},
+	}
+
+	cursor, err := studentCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var caseloads []models.CounselorCaseload
+	if err := cursor.All(ctx, &caseloads); err != nil {
+		return nil, err
+	}
+
+	return caseloads, nil
+}
+
+// Assign gives the students to a counselor. Students in the trash are skipped.
+func (r *CounselorRepository) Assign(studentIDs []primitive.ObjectID, counselorID primitive.ObjectID) (int64, error) {
+	studentCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_students")
+	ctx := context.Background()
+
+	location, _ := time.LoadLocation("Asia/Jakarta")
+	result, err := studentCollection.UpdateMany(
+		ctx,
+		bson.M{"_id": bson.M{"$in": studentIDs}, "deleted_at": notDeleted},
+		bson.M{"$set": bson.M{"counselor_id": counselorID, "updated_at": time.Now().In(location)}},
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"elible/internal/app/models"
+	"elible/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type HistoryRepository struct {
+	MongoClient *mongo.Client
+	cfg         *config.Config
+}
+
+func NewHistoryRepository(cfg *config.Config, mongoClient *mongo.Client) *HistoryRepository {
+	return &HistoryRepository{
+		cfg:         cfg,
+		MongoClient: mongoClient,
+	}
+}
+
+// nextVersion hands out the next history version of a student from tb_counters.
+func (r *HistoryRepository) nextVersion(studentID primitive.ObjectID) (int64, error) {
+	collection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_counters")
+	ctx := context.Background()
+
+	var counter struct {
+		Seq int64 `bson:"seq"`
+	}
+	err := collection.FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": "student-history-" + studentID.Hex()},
+		bson.M{"$inc": bson.M{"seq": 1}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&counter)
+	if err != nil {
+		return 0, err
+	}
+
+	return counter.Seq, nil
+}
+
+func (r *HistoryRepository) Create(changeSet *models.StudentChangeSet) error {
+	historyCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_student_history")
+	ctx := context.Background()
+
+	version, err := r.nextVersion(changeSet.StudentID)
+	if err != nil {
+		return err
+	}
+	changeSet.Version = version
+
+	location, _ := time.LoadLocation("Asia/Jakarta")
+	changeSet.CreatedAt = time.Now().In(location)
+
+	result, err := historyCollection.InsertOne(ctx, changeSet)
+	if err != nil {
+		return err
+	}
+	changeSet.ID = result.InsertedID.(primitive.ObjectID)
+
+	return nil
+}
+
+func (r *HistoryRepository) FindByID(id primitive.ObjectID) (*models.StudentChangeSet, error) {
+	historyCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_student_history")
+	ctx := context.Background()
+
+	var changeSet models.StudentChangeSet
+	if err := historyCollection.FindOne(ctx, bson.M{"_id": id}).Decode(&changeSet); err != nil {
+		return nil, err
+	}
+
+	return &changeSet, nil
+}
+
+// ListByStudent returns a student's change sets, newest version first.
+func (r *HistoryRepository) ListByStudent(studentID primitive.ObjectID, filter *models.HistoryFilter) (*models.PagedStudentHistory, error) {
+	historyCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_student_history")
+	ctx := context.Background()
+
+	bsonFilter := bson.M{"student_id": studentID}
+	if filter.Field != nil && *filter.Field != "" {
+		bsonFilter["changes.field"] = *filter.Field
+	}
+
+	page, pageSize := 1, 20
+	if filter.Page != nil && *filter.Page > 0 {
+		page = *filter.Page
+	}
+	if filter.PageSize != nil && *filter.PageSize > 0 {
+		pageSize = *filter.PageSize
+	}
+
+	findOptions := options.Find().
+		SetSort(bson.M{"version": -1}).
+		SetSkip(int64((page - 1) * pageSize)).
+		SetLimit(int64(pageSize))
+
+	cursor, err := historyCollection.Find(ctx, bsonFilter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var changeSets []models.StudentChangeSet
+	if err := cursor.All(ctx, &changeSets); err != nil {
+		return nil, err
+	}
+
+	total, err := historyCollection.CountDocuments(ctx, bsonFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.PagedStudentHistory{
+		CurrentPage:  page,
+		TotalRecords: total,
+		TotalPages:   int(math.Ceil(float64(total) / float64(pageSize))),
+		Records:      changeSets,
+	}, nil
+}
@@ -0,0 +1,220 @@
+package repository
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"elible/internal/app/models"
+	"elible/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type InteractionRepository struct {
+	MongoClient *mongo.Client
+	cfg         *config.Config
+}
+
+func NewInteractionRepository(cfg *config.Config, mongoClient *mongo.Client) *InteractionRepository {
+	return &InteractionRepository{
+		cfg:         cfg,
+		MongoClient: mongoClient,
+	}
+}
+
+// EnsureIndexes creates the text index used by Search and the index behind a
+// student's log. Creating an index that already exists is a no-op.
+func (r *InteractionRepository) EnsureIndexes() error {
+	interactionCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_interactions")
+	ctx := context.Background()
+
+	_, err := interactionCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "body", Value: "text"}},
+			Options: options.Index().SetName("InteractionSearchIndex"),
+		},
+		{
+			Keys: bson.D{{Key: "student_id", Value: 1}, {Key: "occurred_at", Value: -1}},
+		},
+	})
+	return err
+}
+
+func (r *InteractionRepository) Create(interaction *models.Interaction) error {
+	interactionCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_interactions")
+	ctx := context.Background()
+
+	location, _ := time.LoadLocation("Asia/Jakarta")
+	interaction.CreatedAt = time.Now().In(location)
+	if interaction.OccurredAt.IsZero() {
+		interaction.OccurredAt = interaction.CreatedAt
+	}
+
+	result, err := interactionCollection.InsertOne(ctx, interaction)
+	if err != nil {
+		return err
+	}
+	interaction.ID = result.InsertedID.(primitive.ObjectID)
+
+	return nil
+}
+
+func (r *InteractionRepository) FindByID(id primitive.ObjectID) (*models.Interaction, error) {
+	interactionCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_interactions")
+	ctx := context.Background()
+
+	var interaction models.Interaction
+	err := interactionCollection.FindOne(ctx, bson.M{"_id": id}).Decode(&interaction)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &interaction, nil
+}
+
+func (r *InteractionRepository) Delete(id primitive.ObjectID) error {
+	interactionCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_interactions")
+	ctx := context.Background()
+
+	result, err := interactionCollection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// Latest returns the newest interactions of a student and how many there are in total.
+func (r *InteractionRepository) Latest(studentID primitive.ObjectID, limit int64) ([]models.Interaction, int64, error) {
+	interactionCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_interactions")
+	ctx := context.Background()
+
+	filter := bson.M{"student_id": studentID}
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "occurred_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(limit)
+
+	cursor, err := interactionCollection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	interactions := []models.Interaction{}
+	if err := cursor.All(ctx, &interactions); err != nil {
+		return nil, 0, err
+	}
+
+	total, err := interactionCollection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return interactions, total, nil
+}
+
+// Search returns interactions across all students, newest first. Interactions
+// of students in the trash are left out.
+func (r *InteractionRepository) Search(filter *models.InteractionFilter) (*models.PagedInteractions, error) {
+	interactionCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_interactions")
+	studentCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_students")
+	ctx := context.Background()
+
+	deletedIDs, err := studentCollection.Distinct(ctx, "_id", bson.M{"deleted_at": isDeleted})
+	if err != nil {
+		return nil, err
+	}
+
+	studentFilter := bson.M{}
+	if len(deletedIDs) > 0 {
+		studentFilter["$nin"] = deletedIDs
+	}
+
+	bsonFilter := bson.M{}
+	if filter.Query != nil && *filter.Query != "" {
+		bsonFilter["$text"] = bson.M{"$search": *filter.Query}
+	}
+	if filter.StudentID != nil && *filter.StudentID != "" {
+		studentID, err := primitive.ObjectIDFromHex(*filter.StudentID)
+		if err != nil {
+			return nil, err
+		}
+		studentFilter["$eq"] = studentID
+	}
+	if len(studentFilter) > 0 {
+		bsonFilter["student_id"] = studentFilter
+	}
+	if filter.Type != nil && *filter.Type != "" {
+		bsonFilter["type"] = *filter.Type
+	}
+	if filter.AuthorID != nil && *filter.AuthorID != "" {
+		bsonFilter["author.id"] = *filter.AuthorID
+	}
+
+	// Dates are inclusive days in YYYY-MM-DD, Jakarta time
+	location, _ := time.LoadLocation("Asia/Jakarta")
+	occurredAt := bson.M{}
+	if filter.DateFrom != nil && *filter.DateFrom != "" {
+		from, err := time.ParseInLocation("2006-01-02", *filter.DateFrom, location)
+		if err != nil {
+			return nil, err
+		}
+		occurredAt["$gte"] = from
+	}
+	if filter.DateTo != nil && *filter.DateTo != "" {
+		to, err := time.ParseInLocation("2006-01-02", *filter.DateTo, location)
+		if err != nil {
+			return nil, err
+		}
+		occurredAt["$lt"] = to.AddDate(0, 0, 1)
+	}
+	if len(occurredAt) > 0 {
+		bsonFilter["occurred_at"] = occurredAt
+	}
+
+	page, pageSize := 1, 20
+	if filter.Page != nil && *filter.Page > 0 {
+		page = *filter.Page
+	}
+	if filter.PageSize != nil && *filter.PageSize > 0 {
+		pageSize = *filter.PageSize
+	}
+
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "occurred_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(int64((page - 1) * pageSize)).
+		SetLimit(int64(pageSize))
+
+	cursor, err := interactionCollection.Find(ctx, bsonFilter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var interactions []models.Interaction
+	if err := cursor.All(ctx, &interactions); err != nil {
+		return nil, err
+	}
+
+	total, err := interactionCollection.CountDocuments(ctx, bsonFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.PagedInteractions{
+		CurrentPage:  page,
+		TotalRecords: total,
+		TotalPages:   int(math.Ceil(float64(total) / float64(pageSize))),
+		Records:      interactions,
+	}, nil
+}
@@ -0,0 +1,325 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"elible/internal/app/models"
+	"elible/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type InvoiceRepository struct {
+	MongoClient *mongo.Client
+	cfg         *config.Config
+}
+
+func NewInvoiceRepository(cfg *config.Config, mongoClient *mongo.Client) *InvoiceRepository {
+	return &InvoiceRepository{
+		cfg:         cfg,
+		MongoClient: mongoClient,
+	}
+}
+
+// NextNumber hands out invoice numbers from tb_counters, starting again every year, e.g. INV/2024/00001.
+func (r *InvoiceRepository) NextNumber(year int) (string, error) {
+	collection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_counters")
+	ctx := context.Background()
+
+	var counter struct {
+		Seq int64 `bson:"seq"`
+	}
+	err := collection.FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": fmt.Sprintf("invoice-%d", year)},
+		bson.M{"$inc": bson.M{"seq": 1}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&counter)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("INV/%d/%05d", year, counter.Seq), nil
+}
+
+// EnsureIndexes makes a service billable on one open invoice only: every line
+// of an invoice that is not void is claimed in tb_invoice_lines, which has a
+// unique index on student and service. Lines of invoices created before the
+// collection existed are backfilled once.
+func (r *InvoiceRepository) EnsureIndexes() error {
+	lineCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_invoice_lines")
+	ctx := context.Background()
+
+	_, err := lineCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "student_id", Value: 1}, {Key: "service_name", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "invoice_id", Value: 1}},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	count, err := lineCollection.CountDocuments(ctx, bson.M{})
+	if err != nil || count > 0 {
+		return err
+	}
+
+	cursor, err := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_invoices").Find(ctx, bson.M{"status": bson.M{"$ne": models.InvoiceStatusVoid}})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var invoice models.Invoice
+		if err := cursor.Decode(&invoice); err != nil {
+			return err
+		}
+		for _, item := range invoice.Items {
+			// Services that were already billed twice keep the first invoice
+			_, err := lineCollection.UpdateOne(
+				ctx,
+				bson.M{"student_id": invoice.StudentID, "service_name": item.ServiceName},
+				bson.M{"$setOnInsert": bson.M{"invoice_id": invoice.ID}},
+				options.Update().SetUpsert(true),
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return cursor.Err()
+}
+
+// Create saves the invoice and claims its services in one transaction. It
+// reports false when one of the services is already on an open invoice.
+func (r *InvoiceRepository) Create(invoice *models.Invoice) (bool, error) {
+	collection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_invoices")
+	lineCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_invoice_lines")
+	ctx := context.Background()
+
+	location, _ := time.LoadLocation("Asia/Jakarta")
+	invoice.ID = primitive.NewObjectID()
+	invoice.CreatedAt = time.Now().In(location)
+	invoice.UpdatedAt = invoice.CreatedAt
+
+	lines := make([]interface{}, 0, len(invoice.Items))
+	for _, item := range invoice.Items {
+		lines = append(lines, bson.M{"student_id": invoice.StudentID, "service_name": item.ServiceName, "invoice_id": invoice.ID})
+	}
+
+	err := withTransaction(ctx, r.MongoClient, func(sessCtx mongo.SessionContext) error {
+		if _, err := lineCollection.InsertMany(sessCtx, lines); err != nil {
+			return err
+		}
+		_, err := collection.InsertOne(sessCtx, invoice)
+		return err
+	})
+	if err != nil {
+		invoice.ID = primitive.NilObjectID
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *InvoiceRepository) FindByID(id primitive.ObjectID) (*models.Invoice, error) {
+	collection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_invoices")
+	ctx := context.Background()
+
+	var invoice models.Invoice
+	if err := collection.FindOne(ctx, bson.M{"_id": id}).Decode(&invoice); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &invoice, nil
+}
+
+func (r *InvoiceRepository) List(filter *models.InvoiceFilter) (*models.PagedInvoices, error) {
+	collection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_invoices")
+	ctx := context.Background()
+
+	bsonFilter := make(bson.M)
+	if filter.StudentID != nil && *filter.StudentID != "" {
+		studentID, err := primitive.ObjectIDFromHex(*filter.StudentID)
+		if err != nil {
+			return nil, err
+		}
+		bsonFilter["student_id"] = studentID
+	}
+	if filter.Status != nil && *filter.Status != "" {
+		bsonFilter["status"] = *filter.Status
+	}
+	if filter.Overdue != nil && *filter.Overdue {
+		bsonFilter["status"] = bson.M{"$in": []string{models.InvoiceStatusUnpaid, models.InvoiceStatusPartial}}
+		bsonFilter["due_date"] = bson.M{"$lt": time.Now()}
+	}
+
+	page, pageSize := 1, 20
+	if filter.Page != nil && *filter.Page > 0 {
+		page = *filter.Page
+	}
+	if filter.PageSize != nil && *filter.PageSize > 0 {
+		pageSize = *filter.PageSize
+	}
+
+	findOptions := options.Find().
+		SetSort(bson.M{"created_at": -1}).
+		SetSkip(int64((page - 1) * pageSize)).
+		SetLimit(int64(pageSize))
+
+	cursor, err := collection.Find(ctx, bsonFilter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var invoices []models.Invoice
+	if err := cursor.All(ctx, &invoices); err != nil {
+		return nil, err
+	}
+
+	total, err := collection.CountDocuments(ctx, bsonFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.PagedInvoices{
+		CurrentPage:  page,
+		TotalRecords: total,
+		TotalPages:   int(math.Ceil(float64(total) / float64(pageSize))),
+		Records:      invoices,
+	}, nil
+}
+
+// InvoicedServices returns the names of the student's services that are already on an invoice that was not voided.
+func (r *InvoiceRepository) InvoicedServices(studentID primitive.ObjectID) (map[string]bool, error) {
+	collection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_invoices")
+	ctx := context.Background()
+
+	names, err := collection.Distinct(ctx, "items.service_name", bson.M{
+		"student_id": studentID,
+		"status":     bson.M{"$ne": models.InvoiceStatusVoid},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	invoiced := make(map[string]bool, len(names))
+	for _, name := range names {
+		if s, ok := name.(string); ok {
+			invoiced[s] = true
+		}
+	}
+	return invoiced, nil
+}
+
+// AddPayment appends a payment as long as nobody changed the amount paid since
+// the invoice was read. It reports false when the invoice changed in between.
+func (r *InvoiceRepository) AddPayment(invoice *models.Invoice, payment models.Payment) (bool, error) {
+	collection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_invoices")
+	ctx := context.Background()
+
+	location, _ := time.LoadLocation("Asia/Jakarta")
+	amountPaid := invoice.AmountPaid + payment.Amount
+	result, err := collection.UpdateOne(
+		ctx,
+		bson.M{"_id": invoice.ID, "amount_paid": invoice.AmountPaid, "total": invoice.Total, "status": invoice.Status},
+		bson.M{
+			"$push": bson.M{"payments": payment},
+			"$set": bson.M{
+				"amount_paid": amountPaid,
+				"status":      models.PaymentStatus(invoice.Total, amountPaid),
+				"updated_at":  time.Now().In(location),
+			},
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount > 0, nil
+}
+
+// UpdateTerms saves the discount, total, status, due date and notes of an open
+// invoice. Like AddPayment it reports false when the invoice changed in between.
+func (r *InvoiceRepository) UpdateTerms(invoice *models.Invoice, previousTotal int64) (bool, error) {
+	collection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_invoices")
+	ctx := context.Background()
+
+	location, _ := time.LoadLocation("Asia/Jakarta")
+	invoice.UpdatedAt = time.Now().In(location)
+	result, err := collection.UpdateOne(
+		ctx,
+		bson.M{"_id": invoice.ID, "amount_paid": invoice.AmountPaid, "total": previousTotal, "status": bson.M{"$ne": models.InvoiceStatusVoid}},
+		bson.M{"$set": bson.M{
+			"discount":   invoice.Discount,
+			"total":      invoice.Total,
+			"status":     invoice.Status,
+			"due_date":   invoice.DueDate,
+			"notes":      invoice.Notes,
+			"updated_at": invoice.UpdatedAt,
+		}},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount > 0, nil
+}
+
+// Void cancels an invoice that has no payments, which frees its services to be invoiced again.
+func (r *InvoiceRepository) Void(id primitive.ObjectID, reason string) (bool, error) {
+	collection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_invoices")
+	ctx := context.Background()
+
+	lineCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_invoice_lines")
+
+	location, _ := time.LoadLocation("Asia/Jakarta")
+	now := time.Now().In(location)
+	voided := false
+	err := withTransaction(ctx, r.MongoClient, func(sessCtx mongo.SessionContext) error {
+		result, err := collection.UpdateOne(
+			sessCtx,
+			bson.M{"_id": id, "status": models.InvoiceStatusUnpaid, "amount_paid": 0},
+			bson.M{"$set": bson.M{
+				"status":      models.InvoiceStatusVoid,
+				"void_reason": reason,
+				"voided_at":   now,
+				"updated_at":  now,
+			}},
+		)
+		if err != nil {
+			return err
+		}
+		voided = result.ModifiedCount > 0
+		if !voided {
+			return nil
+		}
+
+		_, err = lineCollection.DeleteMany(sessCtx, bson.M{"invoice_id": id})
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return voided, nil
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"elible/internal/app/models"
 	"elible/internal/config"
@@ -29,35 +30,63 @@ func (r *KnowledgeBaseRepository) CreateKnowledgeBase(knowledgeBase *models.Know
 	KnowledgeBaseCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_knowledge_bases")
 	ctx := context.Background()
 
-	_, err := KnowledgeBaseCollection.InsertOne(ctx, knowledgeBase)
+	result, err := KnowledgeBaseCollection.InsertOne(ctx, knowledgeBase)
 	if err != nil {
 		return err
 	}
+	knowledgeBase.ID = result.InsertedID.(primitive.ObjectID)
 	return nil
 }
 
-func (r *KnowledgeBaseRepository) DeleteKnowledgeBase(id primitive.ObjectID) error {
+func (r *KnowledgeBaseRepository) DeleteKnowledgeBase(id primitive.ObjectID, deletedBy string) error {
 	KnowledgeBaseCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_knowledge_bases")
 	ctx := context.Background()
 
-	_, err := KnowledgeBaseCollection.DeleteOne(ctx, bson.M{"_id": id})
+	return softDelete(ctx, KnowledgeBaseCollection, id, deletedBy, nil)
+}
 
-	if err != nil {
-		return err
+func (r *KnowledgeBaseRepository) RestoreKnowledgeBase(id primitive.ObjectID) error {
+	KnowledgeBaseCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_knowledge_bases")
+	ctx := context.Background()
+
+	return restoreDeleted(ctx, KnowledgeBaseCollection, id)
+}
+
+func (r *KnowledgeBaseRepository) ListDeletedKnowledgeBase() ([]models.KnowledgeBase, error) {
+	KnowledgeBaseCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_knowledge_bases")
+	ctx := context.Background()
+
+	var knowledgeBases []models.KnowledgeBase
+	if err := findDeleted(ctx, KnowledgeBaseCollection, &knowledgeBases); err != nil {
+		return nil, err
 	}
 
-	return nil
+	return knowledgeBases, nil
+}
+
+// PurgeDeleted permanently removes knowledge bases deleted before the given time.
+func (r *KnowledgeBaseRepository) PurgeDeleted(before time.Time) (int64, error) {
+	KnowledgeBaseCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_knowledge_bases")
+	ctx := context.Background()
+
+	return purgeDeleted(ctx, KnowledgeBaseCollection, before)
 }
 
 func (r *KnowledgeBaseRepository) UpdateKnowledgeBase(knowledgeBase *models.KnowledgeBase) error {
 	KnowledgeBaseCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_knowledge_bases")
 	ctx := context.Background()
 
-	_, err := KnowledgeBaseCollection.UpdateOne(ctx, bson.M{"_id": knowledgeBase.ID}, bson.M{"$set": knowledgeBase})
+	// Deletion is only changed through DeleteKnowledgeBase and RestoreKnowledgeBase
+	knowledgeBase.DeletedAt = nil
+	knowledgeBase.DeletedBy = ""
 
+	result, err := KnowledgeBaseCollection.UpdateOne(ctx, bson.M{"_id": knowledgeBase.ID, "deleted_at": notDeleted}, bson.M{"$set": knowledgeBase})
 	if err != nil {
 		return err
 	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
 
 	return nil
 }
@@ -66,7 +95,7 @@ func (r *KnowledgeBaseRepository) ListKnowledgeBase() ([]models.KnowledgeBase, e
 	KnowledgeBaseCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_knowledge_bases")
 	ctx := context.Background()
 
-	cursor, err := KnowledgeBaseCollection.Find(ctx, bson.M{})
+	cursor, err := KnowledgeBaseCollection.Find(ctx, bson.M{"deleted_at": notDeleted})
 
 	if err != nil {
 		return nil, err
@@ -114,7 +143,8 @@ func (r *KnowledgeBaseRepository) UpdateKnowledgeProgram(id primitive.ObjectID,
 	ctx := context.Background()
 
 	filter := bson.M{
-		"_id": id,
+		"_id":        id,
+		"deleted_at": notDeleted,
 	}
 
 	update := bson.M{
@@ -129,11 +159,13 @@ func (r *KnowledgeBaseRepository) UpdateKnowledgeProgram(id primitive.ObjectID,
 		},
 	})
 
-	_, err := KnowledgeBaseCollection.UpdateOne(ctx, filter, update, arrayFilter)
-
+	result, err := KnowledgeBaseCollection.UpdateOne(ctx, filter, update, arrayFilter)
 	if err != nil {
 		return err
 	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
 
 	return nil
 }
@@ -144,7 +176,7 @@ func (r *KnowledgeBaseRepository) ListKnowledgePrograms(id primitive.ObjectID) (
 
 	var knowledgeBase models.KnowledgeBase
 
-	err := KnowledgeBaseCollection.FindOne(ctx, bson.M{"_id": id}).Decode(&knowledgeBase)
+	err := KnowledgeBaseCollection.FindOne(ctx, bson.M{"_id": id, "deleted_at": notDeleted}).Decode(&knowledgeBase)
 
 	if err != nil {
 		return nil, err
@@ -152,3 +184,19 @@ func (r *KnowledgeBaseRepository) ListKnowledgePrograms(id primitive.ObjectID) (
 
 	return knowledgeBase.Programs, nil
 }
+
+func (r *KnowledgeBaseRepository) GetKnowledgeBase(id primitive.ObjectID) (*models.KnowledgeBase, error) {
+	KnowledgeBaseCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_knowledge_bases")
+	ctx := context.Background()
+
+	var knowledgeBase models.KnowledgeBase
+	err := KnowledgeBaseCollection.FindOne(ctx, bson.M{"_id": id, "deleted_at": notDeleted}).Decode(&knowledgeBase)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &knowledgeBase, nil
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"elible/internal/app/models"
+	"elible/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type PipelineRepository struct {
+	MongoClient *mongo.Client
+	cfg         *config.Config
+}
+
+func NewPipelineRepository(cfg *config.Config, mongoClient *mongo.Client) *PipelineRepository {
+	return &PipelineRepository{
+		cfg:         cfg,
+		MongoClient: mongoClient,
+	}
+}
+
+func (r *PipelineRepository) ListStages() ([]models.PipelineStage, error) {
+	stageCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_pipeline_stages")
+	ctx := context.Background()
+
+	cursor, err := stageCollection.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"order": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var stages []models.PipelineStage
+	if err := cursor.All(ctx, &stages); err != nil {
+		return nil, err
+	}
+
+	return stages, nil
+}
+
+func (r *PipelineRepository) CountStages() (int64, error) {
+	stageCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_pipeline_stages")
+	ctx := context.Background()
+
+	return stageCollection.CountDocuments(ctx, bson.M{})
+}
+
+// BackfillFollowUps gives stages that were configured before follow-up tasks
+// existed the follow-up of the default stage with the same key. It runs once,
+// recorded in tb_migrations, so a follow-up an admin removes later stays removed.
+func (r *PipelineRepository) BackfillFollowUps(defaults []models.PipelineStage) error {
+	stageCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_pipeline_stages")
+	migrationCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_migrations")
+	ctx := context.Background()
+
+	const migration = "pipeline-follow-ups"
+	count, err := migrationCollection.CountDocuments(ctx, bson.M{"_id": migration})
+	if err != nil || count > 0 {
+		return err
+	}
+
+	for _, stage := range defaults {
+		if stage.FollowUp == nil {
+			continue
+		}
+		_, err := stageCollection.UpdateOne(
+			ctx,
+			bson.M{"key": stage.Key, "follow_up": bson.M{"$exists": false}},
+			bson.M{"$set": bson.M{"follow_up": stage.FollowUp}},
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	location, _ := time.LoadLocation("Asia/Jakarta")
+	_, err = migrationCollection.InsertOne(ctx, bson.M{"_id": migration, "applied_at": time.Now().In(location)})
+	if mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+	return err
+}
+
+// ReplaceStages swaps the whole pipeline definition for the given stages in
+// one transaction, so readers never see an empty or half written pipeline.
+func (r *PipelineRepository) ReplaceStages(stages []models.PipelineStage) error {
+	stageCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_pipeline_stages")
+	ctx := context.Background()
+
+	location, _ := time.LoadLocation("Asia/Jakarta")
+	documents := make([]interface{}, 0, len(stages))
+	for _, stage := range stages {
+		stage.UpdatedAt = time.Now().In(location)
+		documents = append(documents, stage)
+	}
+
+	return withTransaction(ctx, r.MongoClient, func(sessCtx mongo.SessionContext) error {
+		if _, err := stageCollection.DeleteMany(sessCtx, bson.M{}); err != nil {
+			return err
+		}
+		_, err := stageCollection.InsertMany(sessCtx, documents)
+		return err
+	})
+}
@@ -67,28 +67,58 @@ func (r *StudyProgramRepository) UpdateStudyProgram(id primitive.ObjectID, sp mo
 	ctx := context.Background()
 
 	sp.UpdatedAt = time.Now()
+	// Deletion is only changed through DeleteStudyProgram and RestoreStudyProgram
+	sp.DeletedAt = nil
+	sp.DeletedBy = ""
+	sp.DeletedLinks = nil
 
 	StudyProgramCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_study_programs")
-	_, err := StudyProgramCollection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": sp})
+	result, err := StudyProgramCollection.UpdateOne(ctx, bson.M{"_id": id, "deleted_at": notDeleted}, bson.M{"$set": sp})
 	if err != nil {
 		return err
 	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
 
-	return err
+	return nil
 }
 
-// DeleteStudyProgram deletes a study program and removes it from all KnowledgeBases it belonged to
-func (r *StudyProgramRepository) DeleteStudyProgram(id primitive.ObjectID) error {
+// DeleteStudyProgram moves a study program to the trash and removes it from all KnowledgeBases it belonged to
+func (r *StudyProgramRepository) DeleteStudyProgram(id primitive.ObjectID, deletedBy string) error {
 	ctx := context.Background()
 
 	StudyProgramCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_study_programs")
-	_, err := StudyProgramCollection.DeleteOne(ctx, bson.M{"_id": id})
+	KnowledgeBaseCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_knowledge_bases")
+
+	// Remember the knowledge programs it is linked to so a restore can link it again
+	cursor, err := KnowledgeBaseCollection.Find(ctx, bson.M{"programs.study_programs": id})
+	if err != nil {
+		return err
+	}
+	var knowledgeBases []models.KnowledgeBase
+	if err := cursor.All(ctx, &knowledgeBases); err != nil {
+		return err
+	}
+
+	var links []models.KnowledgeLink
+	for _, kb := range knowledgeBases {
+		for _, program := range kb.Programs {
+			for _, spID := range program.StudyPrograms {
+				if spID == id {
+					links = append(links, models.KnowledgeLink{KnowledgeBaseID: kb.ID, ProgramName: program.Name})
+					break
+				}
+			}
+		}
+	}
+
+	err = softDelete(ctx, StudyProgramCollection, id, deletedBy, bson.M{"deleted_links": links})
 	if err != nil {
 		return err
 	}
 
 	// Removing the deleted study program from all KnowledgeBases it belonged to
-	KnowledgeBaseCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_knowledge_bases")
 	_, err = KnowledgeBaseCollection.UpdateMany(
 		ctx,
 		bson.M{"programs.study_programs": id},
@@ -98,6 +128,81 @@ func (r *StudyProgramRepository) DeleteStudyProgram(id primitive.ObjectID) error
 	return err
 }
 
+// RestoreStudyProgram takes a study program out of the trash and adds it back to the knowledge programs it was removed from
+func (r *StudyProgramRepository) RestoreStudyProgram(id primitive.ObjectID) error {
+	ctx := context.Background()
+
+	StudyProgramCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_study_programs")
+	KnowledgeBaseCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_knowledge_bases")
+
+	var sp models.StudyProgram
+	err := StudyProgramCollection.FindOne(ctx, bson.M{"_id": id, "deleted_at": isDeleted}).Decode(&sp)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return ErrNotInTrash
+		}
+		return err
+	}
+
+	if err := restoreDeleted(ctx, StudyProgramCollection, id, "deleted_links"); err != nil {
+		return err
+	}
+
+	// Knowledge programs that were removed in the meantime are skipped
+	for _, link := range sp.DeletedLinks {
+		_, err = KnowledgeBaseCollection.UpdateOne(
+			ctx,
+			bson.M{"_id": link.KnowledgeBaseID, "programs.name": link.ProgramName},
+			bson.M{"$addToSet": bson.M{"programs.$.study_programs": id}},
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *StudyProgramRepository) GetDeletedStudyPrograms() ([]models.StudyProgram, error) {
+	ctx := context.Background()
+
+	StudyProgramCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_study_programs")
+
+	var programs []models.StudyProgram
+	if err := findDeleted(ctx, StudyProgramCollection, &programs); err != nil {
+		return nil, err
+	}
+
+	return programs, nil
+}
+
+// PurgeDeleted permanently removes study programs deleted before the given time
+func (r *StudyProgramRepository) PurgeDeleted(before time.Time) (int64, error) {
+	ctx := context.Background()
+
+	StudyProgramCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_study_programs")
+
+	return purgeDeleted(ctx, StudyProgramCollection, before)
+}
+
+// FindStudyProgram returns the stored study program document without its university, or nil when it does not exist
+func (r *StudyProgramRepository) FindStudyProgram(id primitive.ObjectID) (*models.StudyProgram, error) {
+	ctx := context.Background()
+
+	StudyProgramCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_study_programs")
+
+	var sp models.StudyProgram
+	err := StudyProgramCollection.FindOne(ctx, bson.M{"_id": id, "deleted_at": notDeleted}).Decode(&sp)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &sp, nil
+}
+
 // GetStudyProgram retrieves a study program by its ID
 func (r *StudyProgramRepository) GetStudyProgram(id primitive.ObjectID) (models.StudyProgramWithUniversity, error) {
 	ctx := context.Background()
@@ -105,7 +210,7 @@ func (r *StudyProgramRepository) GetStudyProgram(id primitive.ObjectID) (models.
 	StudyProgramCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_study_programs")
 
 	pipeline := []bson.M{
-		{"$match": bson.M{"_id": id}},
+		{"$match": bson.M{"_id": id, "deleted_at": notDeleted}},
 		{"$lookup": bson.M{
 			"from":         "tb_universities", // adjust this to the actual university collection name
 			"localField":   "program_details.university",
@@ -141,7 +246,7 @@ func (r *StudyProgramRepository) GetStudyPrograms(dataFilter *models.GetStudyPro
 	UniversityCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_universities")
 
 	var kb models.KnowledgeBase
-	err := KnowledgeBaseCollection.FindOne(ctx, bson.M{"year": dataFilter.KbYear}).Decode(&kb)
+	err := KnowledgeBaseCollection.FindOne(ctx, bson.M{"year": dataFilter.KbYear, "deleted_at": notDeleted}).Decode(&kb)
 	if err != nil {
 		return nil, err
 	}
@@ -161,6 +266,7 @@ func (r *StudyProgramRepository) GetStudyPrograms(dataFilter *models.GetStudyPro
 		"_id": bson.M{
 			"$in": kp.StudyPrograms,
 		},
+		"deleted_at": notDeleted,
 	}
 
 	if dataFilter.SearchQuery != "" {
@@ -0,0 +1,379 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+
+	"elible/internal/app/models"
+	"elible/internal/app/utils"
+	"elible/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type SchoolRepository struct {
+	MongoClient *mongo.Client
+	cfg         *config.Config
+}
+
+func NewSchoolRepository(cfg *config.Config, mongoClient *mongo.Client) *SchoolRepository {
+	return &SchoolRepository{
+		cfg:         cfg,
+		MongoClient: mongoClient,
+	}
+}
+
+// equalFold matches a value exactly, ignoring case.
+func equalFold(name string) bson.M {
+	return bson.M{"$regex": primitive.Regex{Pattern: "^" + regexp.QuoteMeta(strings.TrimSpace(name)) + "$", Options: "i"}}
+}
+
+// schoolNameMatch finds a school by its exact name or by its normalized name.
+func schoolNameMatch(name string) bson.M {
+	return bson.M{"$or": bson.A{
+		bson.M{"name": name},
+		bson.M{"normalized_name": utils.NormalizeSchoolName(name)},
+	}}
+}
+
+// EnsureIndexes makes normalized names unique, so two requests cannot create
+// the same school. The index cannot be built while duplicates exist; they are
+// reported then and the index is created on a later start, once they are merged.
+func (r *SchoolRepository) EnsureIndexes() error {
+	schoolCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_schools")
+	ctx := context.Background()
+
+	_, err := schoolCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "normalized_name", Value: 1}},
+		Options: options.Index().
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"normalized_name": bson.M{"$exists": true}}),
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		log.Printf("Schools with the same normalized name exist, merge them with /school/duplicates to enforce unique names: %v\n", err)
+		return nil
+	}
+	return err
+}
+
+func (r *SchoolRepository) Create(school *models.School) error {
+	schoolCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_schools")
+	ctx := context.Background()
+
+	count, err := schoolCollection.CountDocuments(ctx, schoolNameMatch(school.Name))
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("school with the same name already exists")
+	}
+
+	location, _ := time.LoadLocation("Asia/Jakarta")
+	school.NormalizedName = utils.NormalizeSchoolName(school.Name)
+	school.CreatedAt = time.Now().In(location)
+	school.UpdatedAt = school.CreatedAt
+
+	result, err := schoolCollection.InsertOne(ctx, school)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return errors.New("school with the same name already exists")
+		}
+		return err
+	}
+	school.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *SchoolRepository) GetByID(id primitive.ObjectID) (*models.School, error) {
+	schoolCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_schools")
+	ctx := context.Background()
+
+	var school models.School
+	if err := schoolCollection.FindOne(ctx, bson.M{"_id": id}).Decode(&school); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &school, nil
+}
+
+// Update saves the school and, when it was renamed, the school name stored on its students.
+func (r *SchoolRepository) Update(id primitive.ObjectID, school *models.School) error {
+	schoolCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_schools")
+	studentCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_students")
+	ctx := context.Background()
+
+	if school.Name != "" {
+		count, err := schoolCollection.CountDocuments(ctx, bson.M{"$and": bson.A{schoolNameMatch(school.Name), bson.M{"_id": bson.M{"$ne": id}}}})
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return errors.New("school with the same name already exists")
+		}
+	}
+
+	location, _ := time.LoadLocation("Asia/Jakarta")
+	school.ID = primitive.NilObjectID
+	school.NormalizedName = utils.NormalizeSchoolName(school.Name)
+	school.CreatedAt = time.Time{}
+	school.UpdatedAt = time.Now().In(location)
+
+	return withTransaction(ctx, r.MongoClient, func(sessCtx mongo.SessionContext) error {
+		result, err := schoolCollection.UpdateOne(sessCtx, bson.M{"_id": id}, bson.M{"$set": school})
+		if err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				return errors.New("school with the same name already exists")
+			}
+			return err
+		}
+		if result.MatchedCount == 0 {
+			return errors.New("school not found")
+		}
+
+		if school.Name == "" {
+			return nil
+		}
+		_, err = studentCollection.UpdateMany(sessCtx, bson.M{"school_id": id}, bson.M{"$set": bson.M{"school": school.Name}})
+		return err
+	})
+}
+
+// Delete removes a school that no student refers to anymore.
+func (r *SchoolRepository) Delete(id primitive.ObjectID) error {
+	schoolCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_schools")
+	studentCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_students")
+	ctx := context.Background()
+
+	count, err := studentCollection.CountDocuments(ctx, bson.M{"school_id": id})
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("school still has students, move or merge them first")
+	}
+
+	result, err := schoolCollection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return errors.New("school not found")
+	}
+	return nil
+}
+
+// SetImage sets the school_logo or school_image field of a school.
+func (r *SchoolRepository) SetImage(id primitive.ObjectID, field string, url string) error {
+	schoolCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_schools")
+	ctx := context.Background()
+
+	location, _ := time.LoadLocation("Asia/Jakarta")
+	result, err := schoolCollection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{field: url, "updated_at": time.Now().In(location)}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("school not found")
+	}
+	return nil
+}
+
+// List searches schools by name, province and city and counts the students
+// of each school that are not deleted.
+func (r *SchoolRepository) List(filter *models.SchoolFilter) (*models.PagedSchools, error) {
+	schoolCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_schools")
+	ctx := context.Background()
+
+	bsonFilter := bson.M{}
+	if filter.Name != nil && *filter.Name != "" {
+		bsonFilter["name"] = bson.M{"$regex": primitive.Regex{Pattern: regexp.QuoteMeta(*filter.Name), Options: "i"}}
+	}
+	if filter.Province != nil && *filter.Province != "" {
+		bsonFilter["province"] = equalFold(*filter.Province)
+	}
+	if filter.City != nil && *filter.City != "" {
+		bsonFilter["city"] = equalFold(*filter.City)
+	}
+
+	page, pageSize := 1, 20
+	if filter.Page != nil && *filter.Page > 0 {
+		page = *filter.Page
+	}
+	if filter.PageSize != nil && *filter.PageSize > 0 {
+		pageSize = *filter.PageSize
+	}
+
+	pipeline := append(mongo.Pipeline{
+		bson.D{{Key: "$match", Value: bsonFilter}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "name", Value: 1}}}},
+		bson.D{{Key: "$skip", Value: int64((page - 1) * pageSize)}},
+		bson.D{{Key: "$limit", Value: int64(pageSize)}},
+	}, studentCountStages()...)
+
+	cursor, err := schoolCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var schools []models.SchoolSummary
+	if err := cursor.All(ctx, &schools); err != nil {
+		return nil, err
+	}
+
+	total, err := schoolCollection.CountDocuments(ctx, bsonFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.PagedSchools{
+		CurrentPage:  page,
+		TotalRecords: total,
+		TotalPages:   int(math.Ceil(float64(total) / float64(pageSize))),
+		Records:      schools,
+	}, nil
+}
+
+// studentCountStages adds student_count, the number of students of each school that are not deleted.
+func studentCountStages() mongo.Pipeline {
+	return mongo.Pipeline{
+		bson.D{{Key: "$lookup", Value: bson.M{
+			"from": "tb_students",
+			"let":  bson.M{"school_id": "$_id"},
+			"pipeline": bson.A{
+				bson.M{"$match": bson.M{
+					"$expr":      bson.M{"$eq": bson.A{"$school_id", "$$school_id"}},
+					"deleted_at": notDeleted,
+				}},
+				bson.M{"$count": "count"},
+			},
+			"as": "students",
+		}}},
+		bson.D{{Key: "$addFields", Value: bson.M{
+			"student_count": bson.M{"$ifNull": bson.A{bson.M{"$arrayElemAt": bson.A{"$students.count", 0}}, 0}},
+		}}},
+		bson.D{{Key: "$project", Value: bson.M{"students": 0}}},
+	}
+}
+
+// AllWithCounts returns every school with its number of students.
+func (r *SchoolRepository) AllWithCounts() ([]models.SchoolSummary, error) {
+	schoolCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_schools")
+	ctx := context.Background()
+
+	cursor, err := schoolCollection.Aggregate(ctx, studentCountStages())
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var schools []models.SchoolSummary
+	if err := cursor.All(ctx, &schools); err != nil {
+		return nil, err
+	}
+
+	return schools, nil
+}
+
+// SetNormalizedName stores the normalized name of a school. It reports false
+// when another school already has that normalized name.
+func (r *SchoolRepository) SetNormalizedName(id primitive.ObjectID, normalized string) (bool, error) {
+	schoolCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_schools")
+	ctx := context.Background()
+
+	_, err := schoolCollection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"normalized_name": normalized}})
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Merge moves the students of the loser schools to the winner, fills the
+// winner's empty fields from the losers and deletes the losers, all in one
+// transaction.
+func (r *SchoolRepository) Merge(winnerID primitive.ObjectID, loserIDs []primitive.ObjectID) (*models.SchoolMergeResult, error) {
+	schoolCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_schools")
+	studentCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_students")
+	ctx := context.Background()
+
+	result := &models.SchoolMergeResult{WinnerID: winnerID}
+	err := withTransaction(ctx, r.MongoClient, func(sessCtx mongo.SessionContext) error {
+		var winner models.School
+		if err := schoolCollection.FindOne(sessCtx, bson.M{"_id": winnerID}).Decode(&winner); err != nil {
+			if err == mongo.ErrNoDocuments {
+				return errors.New("winning school not found")
+			}
+			return err
+		}
+
+		cursor, err := schoolCollection.Find(sessCtx, bson.M{"_id": bson.M{"$in": loserIDs}})
+		if err != nil {
+			return err
+		}
+		var losers []models.School
+		if err := cursor.All(sessCtx, &losers); err != nil {
+			return err
+		}
+		if len(losers) != len(loserIDs) {
+			return errors.New("one or more schools to merge were not found")
+		}
+
+		// Keep whatever the winner is missing
+		fill := bson.M{}
+		for _, loser := range losers {
+			for field, value := range map[string][2]string{
+				"address":      {winner.Address, loser.Address},
+				"province":     {winner.Province, loser.Province},
+				"city":         {winner.City, loser.City},
+				"school_logo":  {winner.SchoolLogo, loser.SchoolLogo},
+				"school_image": {winner.SchoolImage, loser.SchoolImage},
+				"phone":        {winner.Phone, loser.Phone},
+			} {
+				if _, set := fill[field]; !set && value[0] == "" && value[1] != "" {
+					fill[field] = value[1]
+				}
+			}
+		}
+		location, _ := time.LoadLocation("Asia/Jakarta")
+		fill["updated_at"] = time.Now().In(location)
+		if _, err := schoolCollection.UpdateOne(sessCtx, bson.M{"_id": winnerID}, bson.M{"$set": fill}); err != nil {
+			return err
+		}
+
+		moved, err := studentCollection.UpdateMany(
+			sessCtx,
+			bson.M{"school_id": bson.M{"$in": loserIDs}},
+			bson.M{"$set": bson.M{"school_id": winnerID, "school": winner.Name}},
+		)
+		if err != nil {
+			return err
+		}
+		result.StudentsMoved = moved.ModifiedCount
+
+		deleted, err := schoolCollection.DeleteMany(sessCtx, bson.M{"_id": bson.M{"$in": loserIDs}})
+		if err != nil {
+			return err
+		}
+		result.SchoolsDeleted = deleted.DeletedCount
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Students, universities, study programs and knowledge bases are soft
+// deleted: deleted_at and deleted_by are set and every regular query adds
+// notDeleted to its filter. Documents are only removed by PurgeDeleted once
+// they have been in the trash longer than the retention period.
+
+var (
+	notDeleted = bson.M{"$exists": false}
+	isDeleted  = bson.M{"$exists": true}
+)
+
+var ErrNotInTrash = errors.New("document not found in trash")
+
+// softDelete marks a live document as deleted, also setting any extra fields.
+func softDelete(ctx context.Context, collection *mongo.Collection, id primitive.ObjectID, deletedBy string, extra bson.M) error {
+	location, _ := time.LoadLocation("Asia/Jakarta")
+
+	set := bson.M{"deleted_at": time.Now().In(location), "deleted_by": deletedBy}
+	for key, value := range extra {
+		set[key] = value
+	}
+
+	result, err := collection.UpdateOne(ctx, bson.M{"_id": id, "deleted_at": notDeleted}, bson.M{"$set": set})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// restoreDeleted takes a document out of the trash, removing the deletion fields and any extra fields.
+func restoreDeleted(ctx context.Context, collection *mongo.Collection, id primitive.ObjectID, extra ...string) error {
+	unset := bson.M{"deleted_at": "", "deleted_by": ""}
+	for _, key := range extra {
+		unset[key] = ""
+	}
+
+	result, err := collection.UpdateOne(ctx, bson.M{"_id": id, "deleted_at": isDeleted}, bson.M{"$unset": unset})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotInTrash
+	}
+	return nil
+}
+
+// findDeleted decodes the trashed documents of a collection into results, most recently deleted first.
+func findDeleted(ctx context.Context, collection *mongo.Collection, results interface{}) error {
+	cursor, err := collection.Find(ctx, bson.M{"deleted_at": isDeleted}, options.Find().SetSort(bson.M{"deleted_at": -1}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	return cursor.All(ctx, results)
+}
+
+func purgeDeleted(ctx context.Context, collection *mongo.Collection, before time.Time) (int64, error) {
+	result, err := collection.DeleteMany(ctx, bson.M{"deleted_at": bson.M{"$lt": before}})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
@@ -6,10 +6,12 @@ import (
 	"errors"
 	"log"
 	"math"
+	"sort"
 	"strings"
 	"time"
 
 	"elible/internal/app/models"
+	"elible/internal/app/utils"
 	"elible/internal/config"
 
 	"github.com/xuri/excelize/v2"
@@ -48,10 +50,11 @@ func (r *StudentRepository) Create(student *models.Student) error {
 
 	// New code: Find or create the school based on the student's school name
 	var school models.School
-	err = schoolCollection.FindOne(ctx, bson.M{"name": student.School}).Decode(&school)
+	err = schoolCollection.FindOne(ctx, schoolNameMatch(student.School)).Decode(&school)
 	if err == mongo.ErrNoDocuments {
 		// School not found, create a new one
 		school.Name = student.School
+		school.NormalizedName = utils.NormalizeSchoolName(student.School)
 		school.CreatedAt = time.Now()
 		school.UpdatedAt = time.Now()
 		res, err := schoolCollection.InsertOne(ctx, school)
@@ -64,6 +67,7 @@ func (r *StudentRepository) Create(student *models.Student) error {
 	}
 
 	student.SchoolID = school.ID
+	student.School = school.Name
 
 	// Check if index already exists
 	cursor, err := studentCollection.Indexes().List(ctx)
@@ -121,11 +125,12 @@ func (r *StudentRepository) Create(student *models.Student) error {
 	student.UpdatedAt = time.Now().In(location)
 	student.IsActive = true
 
-	_, err = studentCollection.InsertOne(ctx, student)
+	res, err := studentCollection.InsertOne(ctx, student)
 	if err != nil {
 		// log.Printf("Error while inserting new student into db, Reason: %v\n", err)
 		return err
 	}
+	student.ID = res.InsertedID.(primitive.ObjectID)
 
 	return nil
 }
@@ -146,10 +151,11 @@ func (r *StudentRepository) Update(studentID primitive.ObjectID, student *models
 
 	// New code: Find or create the school based on the student's school name
 	var school models.School
-	err = schoolCollection.FindOne(ctx, bson.M{"name": student.School}).Decode(&school)
+	err = schoolCollection.FindOne(ctx, schoolNameMatch(student.School)).Decode(&school)
 	if err == mongo.ErrNoDocuments {
 		// School not found, create a new one
 		school.Name = student.School
+		school.NormalizedName = utils.NormalizeSchoolName(student.School)
 		school.CreatedAt = time.Now()
 		school.UpdatedAt = time.Now()
 		res, err := schoolCollection.InsertOne(ctx, school)
@@ -161,20 +167,28 @@ func (r *StudentRepository) Update(studentID primitive.ObjectID, student *models
 		return err
 	}
 	student.SchoolID = school.ID
+	student.School = school.Name
 	// Use Jakarta's time zone
 	location, _ := time.LoadLocation("Asia/Jakarta")
 	// update the updated_at field
 	student.UpdatedAt = time.Now().In(location)
+	// Deletion is only changed through Delete and Restore
+	student.DeletedAt = nil
+	student.DeletedBy = ""
+	// The counselor is only changed through the assignment endpoints
+	student.CounselorID = primitive.NilObjectID
 
 	update := bson.M{
 		"$set": student,
 	}
 
-	_, err = studentCollection.UpdateOne(ctx, bson.M{"_id": studentID}, update)
-
+	result, err := studentCollection.UpdateOne(ctx, bson.M{"_id": studentID, "deleted_at": notDeleted}, update)
 	if err != nil {
 		return err
 	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
 
 	return nil
 }
@@ -183,7 +197,7 @@ func (r *StudentRepository) GetAll(filter *models.StudentFilter) (*models.PagedS
 	studentCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_students")
 	ctx := context.Background()
 
-	bsonFilter := make(bson.M)
+	bsonFilter := bson.M{"deleted_at": notDeleted}
 
 	if filter != nil {
 		if filter.Name != nil && *filter.Name != "" {
@@ -194,6 +208,14 @@ func (r *StudentRepository) GetAll(filter *models.StudentFilter) (*models.PagedS
 			// flexible match for school
 			bsonFilter["school"] = bson.M{"$regex": primitive.Regex{Pattern: *filter.School, Options: "i"}}
 		}
+		if filter.SchoolID != nil && *filter.SchoolID != "" {
+			// strict match for school id
+			schoolID, err := primitive.ObjectIDFromHex(*filter.SchoolID)
+			if err != nil {
+				return nil, err
+			}
+			bsonFilter["school_id"] = schoolID
+		}
 		if filter.Interest != nil && *filter.Interest != "" {
 			// strict match for interest
 			bsonFilter["interest"] = *filter.Interest
@@ -226,6 +248,18 @@ func (r *StudentRepository) GetAll(filter *models.StudentFilter) (*models.PagedS
 			// flexible match for birthdate
 			bsonFilter["birthdate"] = bson.M{"$regex": primitive.Regex{Pattern: *filter.Birthdate, Options: "i"}}
 		}
+		if filter.CounselorID != nil && *filter.CounselorID != "" {
+			// strict match for counselor, or students nobody is assigned to
+			if *filter.CounselorID == models.CounselorUnassigned {
+				bsonFilter["counselor_id"] = bson.M{"$exists": false}
+			} else {
+				counselorID, err := primitive.ObjectIDFromHex(*filter.CounselorID)
+				if err != nil {
+					return nil, err
+				}
+				bsonFilter["counselor_id"] = counselorID
+			}
+		}
 	}
 
 	findOptions := options.Find()
@@ -265,7 +299,7 @@ func (r *StudentRepository) GetByID(studentID primitive.ObjectID) (*models.Stude
 	ctx := context.Background()
 
 	var student models.Student
-	err := studentCollection.FindOne(ctx, bson.M{"_id": studentID}).Decode(&student)
+	err := studentCollection.FindOne(ctx, bson.M{"_id": studentID, "deleted_at": notDeleted}).Decode(&student)
 	if err != nil {
 		// Handle error when the student is not found
 		if err == mongo.ErrNoDocuments {
@@ -278,28 +312,101 @@ func (r *StudentRepository) GetByID(studentID primitive.ObjectID) (*models.Stude
 	return &student, nil
 }
 
-func (r *StudentRepository) Delete(studentID primitive.ObjectID) error {
+// Delete moves the student to the trash. The copy in tb_service_student is hidden along with it.
+func (r *StudentRepository) Delete(studentID primitive.ObjectID, deletedBy string) error {
 	studentCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_students")
+	serviceCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_service_student")
 	ctx := context.Background()
 
-	_, err := studentCollection.DeleteOne(ctx, bson.M{"_id": studentID})
+	return withTransaction(ctx, r.MongoClient, func(sessCtx mongo.SessionContext) error {
+		if err := softDelete(sessCtx, studentCollection, studentID, deletedBy, nil); err != nil {
+			return err
+		}
 
-	if err != nil {
-		return err
+		err := softDelete(sessCtx, serviceCollection, studentID, deletedBy, nil)
+		if err != nil && err != mongo.ErrNoDocuments {
+			return err
+		}
+
+		return nil
+	})
+}
+
+func (r *StudentRepository) Restore(studentID primitive.ObjectID) error {
+	studentCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_students")
+	serviceCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_service_student")
+	ctx := context.Background()
+
+	return withTransaction(ctx, r.MongoClient, func(sessCtx mongo.SessionContext) error {
+		if err := restoreDeleted(sessCtx, studentCollection, studentID, "merged_into"); err != nil {
+			return err
+		}
+
+		err := restoreDeleted(sessCtx, serviceCollection, studentID)
+		if err != nil && err != ErrNotInTrash {
+			return err
+		}
+
+		return nil
+	})
+}
+
+func (r *StudentRepository) ListDeleted() ([]models.Student, error) {
+	studentCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_students")
+	ctx := context.Background()
+
+	var students []models.Student
+	if err := findDeleted(ctx, studentCollection, &students); err != nil {
+		return nil, err
 	}
 
-	return nil
+	return students, nil
 }
 
-func (r *StudentRepository) Deactivate(studentID primitive.ObjectID) error {
+// PurgeDeleted permanently removes students deleted before the given time.
+// PurgeDeleted removes the students that were trashed before the given time,
+// together with their service copies and interaction log.
+func (r *StudentRepository) PurgeDeleted(before time.Time) (int64, error) {
 	studentCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_students")
+	serviceCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_service_student")
+	interactionCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_interactions")
 	ctx := context.Background()
 
-	_, err := studentCollection.UpdateOne(ctx, bson.M{"_id": studentID}, bson.M{"$set": bson.M{"is_active": false, "updated_at": time.Now()}})
+	var purged int64
+	err := withTransaction(ctx, r.MongoClient, func(sessCtx mongo.SessionContext) error {
+		studentIDs, err := studentCollection.Distinct(sessCtx, "_id", bson.M{"deleted_at": bson.M{"$lt": before}})
+		if err != nil {
+			return err
+		}
+		if len(studentIDs) > 0 {
+			if _, err := interactionCollection.DeleteMany(sessCtx, bson.M{"student_id": bson.M{"$in": studentIDs}}); err != nil {
+				return err
+			}
+		}
+
+		if _, err := purgeDeleted(sessCtx, serviceCollection, before); err != nil {
+			return err
+		}
+
+		count, err := purgeDeleted(sessCtx, studentCollection, before)
+		purged = count
+		return err
+	})
+
+	return purged, err
+}
+
+func (r *StudentRepository) Deactivate(studentID primitive.ObjectID) error {
+	studentCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_students")
+	ctx := context.Background()
 
+	result, err := studentCollection.UpdateOne(ctx, bson.M{"_id": studentID, "deleted_at": notDeleted}, bson.M{"$set": bson.M{"is_active": false, "updated_at": time.Now()}})
 	if err != nil {
 		return err
 	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
 
 	return nil
 }
@@ -309,7 +416,7 @@ func (r *StudentRepository) FindByUsername(username string) (*models.Student, er
 	ctx := context.Background()
 
 	var student models.Student
-	err := studentCollection.FindOne(ctx, bson.M{"name": username}).Decode(&student)
+	err := studentCollection.FindOne(ctx, bson.M{"name": username, "deleted_at": notDeleted}).Decode(&student)
 	if err != nil {
 		// Handle error when the student is not found
 		if err == mongo.ErrNoDocuments {
@@ -327,58 +434,30 @@ func (r *StudentRepository) AddService(studentID primitive.ObjectID, service *mo
 	serviceCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_service_student")
 	ctx := context.Background()
 
-	// Find the student by ID
-	var student models.Student
-	err := studentCollection.FindOne(ctx, bson.M{"_id": studentID}).Decode(&student)
-	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return errors.New("student not found")
-		}
-		return err
-	}
-
-	for _, existingService := range student.TrackRecords {
-		if existingService.ServiceName == service.ServiceName {
-			return errors.New("service already exists")
-		}
-	}
-
 	// Use Jakarta's time zone
 	location, _ := time.LoadLocation("Asia/Jakarta")
 
 	service.CreatedAt = time.Now().In(location)
 	service.UpdatedAt = time.Now().In(location)
 
-	update := bson.M{
-		"$push": bson.M{
-			"track_records": service,
-		},
-		"$set": bson.M{
-			"updated_at": time.Now().In(location),
-		},
-	}
-
-	_, err = studentCollection.UpdateOne(ctx, bson.M{"_id": studentID}, update)
-
-	if err != nil {
-		return err
-	}
-
-	// Also update this service in tb_service_student collection
-	serviceInStudent := models.Student{
-		ID:   studentID,
-		Name: student.Name,
-		TrackRecords: []models.TrackRecord{
-			*service,
-		},
-	}
+	// tb_students and its copy in tb_service_student are written in one transaction so they cannot drift apart
+	err := withTransaction(ctx, r.MongoClient, func(sessCtx mongo.SessionContext) error {
+		// Find the student by ID
+		var student models.Student
+		err := studentCollection.FindOne(sessCtx, bson.M{"_id": studentID, "deleted_at": notDeleted}).Decode(&student)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				return errors.New("student not found")
+			}
+			return err
+		}
 
-	// Check if student already has a service record
-	var existingService models.Student
-	err = serviceCollection.FindOne(ctx, bson.M{"_id": studentID}).Decode(&existingService)
+		for _, existingService := range student.TrackRecords {
+			if existingService.ServiceName == service.ServiceName {
+				return errors.New("service already exists")
+			}
+		}
 
-	if err == nil {
-		// If record exists, update it
 		update := bson.M{
 			"$push": bson.M{
 				"track_records": service,
@@ -388,20 +467,20 @@ func (r *StudentRepository) AddService(studentID primitive.ObjectID, service *mo
 			},
 		}
 
-		_, err = serviceCollection.UpdateOne(ctx, bson.M{"_id": studentID}, update)
-
+		_, err = studentCollection.UpdateOne(sessCtx, bson.M{"_id": studentID}, update)
 		if err != nil {
 			return err
 		}
-	} else if err == mongo.ErrNoDocuments {
-		// If no record exists, insert a new one
-		_, err = serviceCollection.InsertOne(ctx, serviceInStudent)
 
-		if err != nil {
-			return err
+		// Also add this service in tb_service_student, creating the record for the student's first service
+		update["$set"] = bson.M{
+			"name":       student.Name,
+			"updated_at": time.Now().In(location),
 		}
-	} else {
-		// Handle other errors from FindOne
+		_, err = serviceCollection.UpdateOne(sessCtx, bson.M{"_id": studentID}, update, options.Update().SetUpsert(true))
+		return err
+	})
+	if err != nil {
 		return err
 	}
 
@@ -415,28 +494,69 @@ func (r *StudentRepository) UpdateService(studentID primitive.ObjectID, oldServi
 	studentCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_students")
 	ctx := context.Background()
 
-	filter := bson.M{
-		"_id":                        studentID,
-		"track_records.service_name": oldServiceName,
-	}
+	// Use Jakarta's time zone
+	location, _ := time.LoadLocation("Asia/Jakarta")
 
-	update := bson.M{
-		"$set": bson.M{
-			"track_records.$": newService,
-		},
-	}
+	return withTransaction(ctx, r.MongoClient, func(sessCtx mongo.SessionContext) error {
+		var student models.Student
+		err := studentCollection.FindOne(sessCtx, bson.M{"_id": studentID, "deleted_at": notDeleted}).Decode(&student)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				return errors.New("student not found")
+			}
+			return err
+		}
 
-	_, err := serviceCollection.UpdateOne(ctx, filter, update)
-	if err != nil {
-		return err
-	}
+		var existing *models.TrackRecord
+		for i, record := range student.TrackRecords {
+			if record.ServiceName == oldServiceName {
+				existing = &student.TrackRecords[i]
+			} else if record.ServiceName == newService.ServiceName {
+				return errors.New("service already exists")
+			}
+		}
+		if existing == nil {
+			return errors.New("service not found")
+		}
 
-	_, err = studentCollection.UpdateOne(ctx, filter, update)
-	if err != nil {
-		return err
-	}
+		// Keep the original creation time, the entry is corrected rather than replaced
+		newService.CreatedAt = existing.CreatedAt
+		if newService.CounselorID == "" {
+			newService.CounselorID = existing.CounselorID
+		}
+		newService.UpdatedAt = time.Now().In(location)
 
-	return nil
+		filter := bson.M{
+			"_id":                        studentID,
+			"track_records.service_name": oldServiceName,
+		}
+
+		update := bson.M{
+			"$set": bson.M{
+				"track_records.$": newService,
+				"updated_at":      time.Now().In(location),
+			},
+		}
+
+		// Both copies must hold the service, otherwise they would drift apart
+		result, err := serviceCollection.UpdateOne(sessCtx, filter, update)
+		if err != nil {
+			return err
+		}
+		if result.MatchedCount == 0 {
+			return errors.New("service not found in the service copy of the student")
+		}
+
+		result, err = studentCollection.UpdateOne(sessCtx, filter, update)
+		if err != nil {
+			return err
+		}
+		if result.MatchedCount == 0 {
+			return errors.New("service not found")
+		}
+
+		return nil
+	})
 }
 
 func (r *StudentRepository) DeleteService(studentID primitive.ObjectID, serviceName string) error {
@@ -444,24 +564,31 @@ func (r *StudentRepository) DeleteService(studentID primitive.ObjectID, serviceN
 	studentCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_students")
 	ctx := context.Background()
 
-	filter := bson.M{"_id": studentID}
+	// Use Jakarta's time zone
+	location, _ := time.LoadLocation("Asia/Jakarta")
+
+	filter := bson.M{"_id": studentID, "deleted_at": notDeleted, "track_records.service_name": serviceName}
 	update := bson.M{
 		"$pull": bson.M{
 			"track_records": bson.M{"service_name": serviceName},
 		},
+		"$set": bson.M{
+			"updated_at": time.Now().In(location),
+		},
 	}
 
-	_, err := serviceCollection.UpdateOne(ctx, filter, update)
-	if err != nil {
-		return err
-	}
+	return withTransaction(ctx, r.MongoClient, func(sessCtx mongo.SessionContext) error {
+		result, err := studentCollection.UpdateOne(sessCtx, filter, update)
+		if err != nil {
+			return err
+		}
+		if result.MatchedCount == 0 {
+			return errors.New("service not found")
+		}
 
-	_, err = studentCollection.UpdateOne(ctx, filter, update)
-	if err != nil {
+		_, err = serviceCollection.UpdateOne(sessCtx, filter, update)
 		return err
-	}
-
-	return nil
+	})
 }
 
 func (r *StudentRepository) EnsureServiceIndex() error {
@@ -516,7 +643,14 @@ func (r *StudentRepository) FilterServices(filter models.ServiceFilter) ([]model
 	ctx := context.Background()
 
 	// Build filter query
-	query := bson.M{}
+	query := bson.M{"deleted_at": notDeleted}
+	if filter.Query != nil && *filter.Query != "" {
+		// Full text search needs the SearchIndex text index
+		if err := r.EnsureServiceIndex(); err != nil {
+			return nil, err
+		}
+		query["$text"] = bson.M{"$search": *filter.Query}
+	}
 	if filter.Name != nil {
 		query["name"] = bson.M{"$regex": filter.Name, "$options": "i"} // case insensitive search
 	}
@@ -544,68 +678,90 @@ func (r *StudentRepository) FilterServices(filter models.ServiceFilter) ([]model
 	return services, nil
 }
 
-func (r *StudentRepository) AddLobby(studentID primitive.ObjectID, lobby *models.Student) error {
+// AddLobby moves the student from stage from to lobby.Progress. It reports
+// false when the student is no longer in stage from.
+func (r *StudentRepository) AddLobby(studentID primitive.ObjectID, from string, lobby *models.Student) (bool, error) {
 	studentCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_students")
 	ctx := context.Background()
 
-	// Find the student by ID
-	var student models.Student
-	err := studentCollection.FindOne(ctx, bson.M{"_id": studentID}).Decode(&student)
-	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return errors.New("student not found")
-		}
-		return err
-	}
-
-	// Check if service already exists in track_records
-	// for _, record := range student.TrackLobby {
-	// 	if record.Progress == lobby.Progress {
-	// 		return errors.New("this proggress already exists")
-	// 	}
-	// }
-
 	// Use Jakarta's time zone
 	location, _ := time.LoadLocation("Asia/Jakarta")
 
-	lobby.CreatedAt = time.Now().In(location)
-	lobby.UpdatedAt = time.Now().In(location)
+	now := time.Now().In(location)
+	lobby.CreatedAt = now
+	lobby.UpdatedAt = now
 
 	update := bson.M{
 		"$push": bson.M{
-			"track_lobby": lobby,
+			"track_lobby": models.TrackLobby{
+				Progress:  lobby.Progress,
+				CreatedAt: now,
+				UpdatedAt: now,
+			},
 		},
 		"$set": bson.M{
-			"progress":   lobby.Progress,
-			"updated_at": time.Now().In(location),
+			"progress":                           lobby.Progress,
+			"stage_timestamps." + lobby.Progress: now,
+			"updated_at":                         now,
 		},
 	}
 
-	_, err = studentCollection.UpdateOne(ctx, bson.M{"_id": studentID}, update)
+	// Only move the student from the stage the transition was validated against,
+	// so two concurrent moves cannot both succeed
+	filter := bson.M{"_id": studentID, "progress": from, "deleted_at": notDeleted}
+	if from == "" {
+		filter["progress"] = bson.M{"$in": bson.A{"", nil}}
+	}
 
+	result, err := studentCollection.UpdateOne(ctx, filter, update)
 	if err != nil {
-		return err
+		return false, err
 	}
 
-	return nil
+	return result.MatchedCount > 0, nil
 }
 
-func (r *StudentRepository) ActivateAll() error {
+// ActivateAll activates every inactive student outside the trash and returns
+// them as they were before, so the change can be written to their history.
+func (r *StudentRepository) ActivateAll() ([]models.Student, error) {
 	studentCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_students")
 	ctx := context.Background()
 
-	_, err := studentCollection.UpdateMany(ctx, bson.M{"is_active": bson.M{"$ne": true}}, bson.M{"$set": bson.M{"is_active": true, "updated_at": time.Now()}})
+	cursor, err := studentCollection.Find(ctx, bson.M{"is_active": bson.M{"$ne": true}, "deleted_at": notDeleted})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var students []models.Student
+	if err := cursor.All(ctx, &students); err != nil {
+		return nil, err
+	}
+	if len(students) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(students))
+	for _, student := range students {
+		ids = append(ids, student.ID)
+	}
 
+	_, err = studentCollection.UpdateMany(ctx, bson.M{"_id": bson.M{"$in": ids}, "is_active": bson.M{"$ne": true}, "deleted_at": notDeleted}, bson.M{"$set": bson.M{"is_active": true, "updated_at": time.Now()}})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return students, nil
 }
 
-func (r *StudentRepository) ImportDataFromExcelStudent(filePath string) (*models.ImportResultStudent, error) {
+// ImportDataFromExcelStudent creates or updates the students of an Excel sheet.
+// A row whose progress is not a valid pipeline stage for the student, as
+// decided by validateProgress, counts as failed.
+func (r *StudentRepository) ImportDataFromExcelStudent(filePath string, validateProgress func(from, to string) error) (*models.ImportResultStudent, error) {
 	var schoolCreatedCount, schoolUpdatedCount, schoolFailedCount, studentCreatedCount, studentUpdatedCount, studentFailedCount int
 	var schoolFailedRows, studentFailedRows []int
+	var createdIDs []primitive.ObjectID
+	var updated []models.Student
 
 	studentCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_students")
 	schoolCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_schools")
@@ -632,20 +788,21 @@ func (r *StudentRepository) ImportDataFromExcelStudent(filePath string) (*models
 		schoolFailed := false
 
 		var school models.School
-		err = schoolCollection.FindOne(ctx, bson.M{"name": bson.M{"$regex": primitive.Regex{Pattern: schoolName, Options: "i"}}}).Decode(&school)
+		err = schoolCollection.FindOne(ctx, schoolNameMatch(schoolName)).Decode(&school)
 		if err == mongo.ErrNoDocuments {
 			schoolCreatedCount++
 			school = models.School{
-				ID:          primitive.NewObjectID(),
-				Name:        row[3],
-				Address:     row[4],
-				Province:    strings.ToUpper(row[5]),
-				City:        strings.ToUpper(row[6]),
-				SchoolLogo:  row[7],
-				SchoolImage: row[8],
-				Phone:       row[9],
-				CreatedAt:   time.Now(),
-				UpdatedAt:   time.Now(),
+				ID:             primitive.NewObjectID(),
+				Name:           row[3],
+				NormalizedName: utils.NormalizeSchoolName(row[3]),
+				Address:        row[4],
+				Province:       strings.ToUpper(row[5]),
+				City:           strings.ToUpper(row[6]),
+				SchoolLogo:     row[7],
+				SchoolImage:    row[8],
+				Phone:          row[9],
+				CreatedAt:      time.Now(),
+				UpdatedAt:      time.Now(),
 			}
 			_, err := schoolCollection.InsertOne(ctx, school)
 			if err != nil {
@@ -658,22 +815,28 @@ func (r *StudentRepository) ImportDataFromExcelStudent(filePath string) (*models
 			schoolFailedRows = append(schoolFailedRows, i+1)
 			schoolFailed = true
 		} else {
-			schoolUpdatedCount++
-			updatedSchool := bson.M{
-				"name":        row[3],
-				"address":     row[4],
-				"province":    strings.ToUpper(row[5]),
-				"city":        strings.ToUpper(row[6]),
-				"schoolLogo":  row[7],
-				"schoolImage": row[8],
-				"phone":       row[9],
-				"updatedAt":   time.Now(),
+			// The school keeps its name, the row only fills in what is still missing
+			updatedSchool := bson.M{}
+			fillEmpty := func(key, current, value string) {
+				if current == "" && value != "" {
+					updatedSchool[key] = value
+				}
 			}
-			_, err = schoolCollection.UpdateOne(ctx, bson.M{"_id": school.ID}, bson.M{"$set": updatedSchool})
-			if err != nil {
-				schoolFailedCount++
-				schoolFailedRows = append(schoolFailedRows, i+1)
-				continue
+			fillEmpty("address", school.Address, row[4])
+			fillEmpty("province", school.Province, strings.ToUpper(row[5]))
+			fillEmpty("city", school.City, strings.ToUpper(row[6]))
+			fillEmpty("school_logo", school.SchoolLogo, row[7])
+			fillEmpty("school_image", school.SchoolImage, row[8])
+			fillEmpty("phone", school.Phone, row[9])
+			if len(updatedSchool) > 0 {
+				updatedSchool["updated_at"] = time.Now()
+				_, err = schoolCollection.UpdateOne(ctx, bson.M{"_id": school.ID}, bson.M{"$set": updatedSchool})
+				if err != nil {
+					schoolFailedCount++
+					schoolFailedRows = append(schoolFailedRows, i+1)
+					continue
+				}
+				schoolUpdatedCount++
 			}
 		}
 
@@ -682,59 +845,113 @@ func (r *StudentRepository) ImportDataFromExcelStudent(filePath string) (*models
 		}
 
 		var student models.Student
-		err = studentCollection.FindOne(ctx, bson.M{"name": row[1], "school": row[3], "phone": row[12]}).Decode(&student)
+		err = studentCollection.FindOne(ctx, bson.M{
+			"name":  row[1],
+			"phone": row[12],
+			// Older students only have the school name, in the upper case they were saved with
+			"$or": bson.A{
+				bson.M{"school_id": school.ID},
+				bson.M{"school_id": bson.M{"$exists": false}, "school": strings.ToUpper(row[3])},
+			},
+			"deleted_at": notDeleted,
+		}).Decode(&student)
 		if err == mongo.ErrNoDocuments {
+			// New students start at the first stage unless a valid stage is given
+			progress := row[14]
+			if progress == "" {
+				progress = models.StageLead
+			}
+			if err := validateProgress("", progress); err != nil {
+				studentFailedCount++
+				studentFailedRows = append(studentFailedRows, i+1)
+				log.Printf("Failed to import student on row %d: %v", i+1, err)
+				continue
+			}
+
 			studentCreatedCount++
+			now := time.Now()
 			student = models.Student{
 				ID:               primitive.NewObjectID(),
 				Name:             row[1],
 				Email:            row[2],
-				School:           strings.ToUpper(row[3]),
+				School:           school.Name,
 				SchoolID:         school.ID,
 				Interest:         row[10],
 				Gender:           strings.ToUpper(row[11]),
 				Phone:            row[12],
 				FinancialAbility: row[13],
-				Progress:         row[14],
+				Progress:         progress,
+				StageTimestamps:  map[string]time.Time{progress: now},
 				Image:            row[15],
 				Category:         row[16],
 				Birthdate:        row[17],
 				IsActive:         true,
-				CreatedAt:        time.Now(),
-				UpdatedAt:        time.Now(),
+				CreatedAt:        now,
+				UpdatedAt:        now,
 			}
 			_, err = studentCollection.InsertOne(ctx, student)
 			if err != nil {
 				studentFailedCount++
 				studentFailedRows = append(studentFailedRows, i+1)
+			} else {
+				createdIDs = append(createdIDs, student.ID)
 			}
 		} else if err != nil {
 			studentFailedCount++
 			studentFailedRows = append(studentFailedRows, i+1)
 		} else {
+			// A changed stage is a pipeline move and is recorded like AddLobby does
+			progress := row[14]
+			moved := progress != "" && progress != student.Progress
+			if moved {
+				if err := validateProgress(student.Progress, progress); err != nil {
+					studentFailedCount++
+					studentFailedRows = append(studentFailedRows, i+1)
+					log.Printf("Failed to update student on row %d: %v", i+1, err)
+					continue
+				}
+			}
+
 			studentUpdatedCount++
+			now := time.Now()
 			updatedStudent := bson.M{
-				"name":             row[1],
-				"email":            row[2],
-				"school":           strings.ToUpper(row[3]),
-				"schoolID":         school.ID,
-				"interest":         row[10],
-				"gender":           strings.ToUpper(row[11]),
-				"phone":            row[12],
-				"financialAbility": row[13],
-				"progress":         row[14],
-				"image":            row[15],
-				"category":         row[16],
-				"birthdate":        row[17],
-				"updatedAt":        time.Now(),
+				"name":              row[1],
+				"email":             row[2],
+				"school":            school.Name,
+				"school_id":         school.ID,
+				"interest":          row[10],
+				"gender":            strings.ToUpper(row[11]),
+				"phone":             row[12],
+				"financial_ability": row[13],
+				"image":             row[15],
+				"category":          row[16],
+				"birthdate":         row[17],
+				"updated_at":        now,
 			}
-			_, err = studentCollection.UpdateOne(ctx, bson.M{"_id": student.ID}, bson.M{"$set": updatedStudent})
+			// Earlier imports wrote these under the wrong keys, drop the stray copies
+			update := bson.M{
+				"$set":   updatedStudent,
+				"$unset": bson.M{"schoolID": "", "financialAbility": "", "updatedAt": ""},
+			}
+			if moved {
+				updatedStudent["progress"] = progress
+				updatedStudent["stage_timestamps."+progress] = now
+				update["$push"] = bson.M{
+					"track_lobby": models.TrackLobby{
+						Progress:  progress,
+						CreatedAt: now,
+						UpdatedAt: now,
+					},
+				}
+			}
+			_, err = studentCollection.UpdateOne(ctx, bson.M{"_id": student.ID, "deleted_at": notDeleted}, update)
 			if err != nil {
 				studentFailedCount++
 				studentFailedRows = append(studentFailedRows, i+1) // Append the row number (Excel row numbers start from 1)
 				log.Printf("Failed to update student: %v", err)
 				continue
 			}
+			updated = append(updated, student)
 		}
 	}
 	// After processing all rows...
@@ -751,7 +968,460 @@ func (r *StudentRepository) ImportDataFromExcelStudent(filePath string) (*models
 			FailedCount:  studentFailedCount,
 			FailedRows:   studentFailedRows,
 		},
+		CreatedIDs: createdIDs,
+		Updated:    updated,
+	}
+
+	return result, nil
+}
+
+func (r *StudentRepository) FindByEmail(email string) (*models.Student, error) {
+	studentCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_students")
+	ctx := context.Background()
+
+	var student models.Student
+	err := studentCollection.FindOne(ctx, bson.M{"email": email, "deleted_at": notDeleted}).Decode(&student)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &student, nil
+}
+
+// UpdateFields sets the given fields only, leaving the rest of the student untouched.
+func (r *StudentRepository) UpdateFields(studentID primitive.ObjectID, fields bson.M) error {
+	studentCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_students")
+	ctx := context.Background()
+
+	// Use Jakarta's time zone
+	location, _ := time.LoadLocation("Asia/Jakarta")
+	fields["updated_at"] = time.Now().In(location)
+
+	result, err := studentCollection.UpdateOne(ctx, bson.M{"_id": studentID, "deleted_at": notDeleted}, bson.M{"$set": fields})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// RevertFields writes values back from the change history. Fields in unset had
+// no value before the change and are removed. The student is only updated while
+// it still holds the expected values, and RevertFields reports false when it
+// does not. A reverted name is copied to tb_service_student as well.
+func (r *StudentRepository) RevertFields(studentID primitive.ObjectID, expected bson.M, set bson.M, unset []string) (bool, error) {
+	studentCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_students")
+	serviceCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_service_student")
+	ctx := context.Background()
+
+	// A reverted email must still be unique, like on Create and Update
+	if email, ok := set["email"]; ok {
+		count, err := studentCollection.CountDocuments(ctx, bson.M{"email": email, "_id": bson.M{"$ne": studentID}})
+		if err != nil {
+			return false, err
+		}
+		if count > 0 {
+			return false, errors.New("a user with this email already exists")
+		}
+	}
+
+	location, _ := time.LoadLocation("Asia/Jakarta")
+	now := time.Now().In(location)
+	set["updated_at"] = now
+
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		fields := bson.M{}
+		for _, field := range unset {
+			fields[field] = ""
+		}
+		update["$unset"] = fields
+	}
+
+	filter := bson.M{"_id": studentID, "deleted_at": notDeleted}
+	for field, value := range expected {
+		filter[field] = value
+	}
+
+	reverted := false
+	err := withTransaction(ctx, r.MongoClient, func(sessCtx mongo.SessionContext) error {
+		result, err := studentCollection.UpdateOne(sessCtx, filter, update)
+		if err != nil {
+			return err
+		}
+		reverted = result.MatchedCount > 0
+		if !reverted {
+			return nil
+		}
+
+		if name, ok := set["name"]; ok {
+			_, err = serviceCollection.UpdateOne(sessCtx, bson.M{"_id": studentID}, bson.M{"$set": bson.M{"name": name, "updated_at": now}})
+		}
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return reverted, nil
+}
+
+// ReconcileServices compares tb_service_student with the track records in
+// tb_students, which is the source of truth. With repair set, drifted copies
+// are rewritten from tb_students and copies without a student are removed.
+func (r *StudentRepository) ReconcileServices(repair bool) (*models.ServiceReconcileReport, error) {
+	studentCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_students")
+	serviceCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_service_student")
+	ctx := context.Background()
+
+	serviceCursor, err := serviceCollection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	var copies []models.Student
+	if err := serviceCursor.All(ctx, &copies); err != nil {
+		return nil, err
+	}
+	copiesByID := make(map[primitive.ObjectID]models.Student, len(copies))
+	for _, copy := range copies {
+		copiesByID[copy.ID] = copy
+	}
+
+	studentCursor, err := studentCollection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer studentCursor.Close(ctx)
+
+	report := &models.ServiceReconcileReport{}
+	for studentCursor.Next(ctx) {
+		var student models.Student
+		if err := studentCursor.Decode(&student); err != nil {
+			return nil, err
+		}
+		report.Checked++
+
+		copy, exists := copiesByID[student.ID]
+		delete(copiesByID, student.ID)
+
+		var issue string
+		if !exists {
+			if len(student.TrackRecords) == 0 {
+				continue
+			}
+			issue = models.ServiceDriftMissing
+		} else if !serviceCopyMatches(&student, &copy) {
+			issue = models.ServiceDriftMismatched
+		} else {
+			continue
+		}
+
+		report.Drifts = append(report.Drifts, models.ServiceDrift{StudentID: student.ID.Hex(), Name: student.Name, Issue: issue})
+		if !repair {
+			continue
+		}
+
+		location, _ := time.LoadLocation("Asia/Jakarta")
+		replacement := models.Student{
+			ID:           student.ID,
+			Name:         student.Name,
+			TrackRecords: student.TrackRecords,
+			UpdatedAt:    time.Now().In(location),
+			DeletedAt:    student.DeletedAt,
+			DeletedBy:    student.DeletedBy,
+		}
+		_, err := serviceCollection.ReplaceOne(ctx, bson.M{"_id": student.ID}, replacement, options.Replace().SetUpsert(true))
+		if err != nil {
+			return nil, err
+		}
+		report.Repaired++
+	}
+	if err := studentCursor.Err(); err != nil {
+		return nil, err
+	}
+
+	// Whatever is left has no student at all
+	for id, copy := range copiesByID {
+		report.Drifts = append(report.Drifts, models.ServiceDrift{StudentID: id.Hex(), Name: copy.Name, Issue: models.ServiceDriftOrphaned})
+		if !repair {
+			continue
+		}
+
+		if _, err := serviceCollection.DeleteOne(ctx, bson.M{"_id": id}); err != nil {
+			return nil, err
+		}
+		report.Repaired++
+	}
+
+	return report, nil
+}
+
+func serviceCopyMatches(student, copy *models.Student) bool {
+	if student.Name != copy.Name || (student.DeletedAt == nil) != (copy.DeletedAt == nil) {
+		return false
+	}
+	if len(student.TrackRecords) != len(copy.TrackRecords) {
+		return false
+	}
+
+	for i, record := range student.TrackRecords {
+		other := copy.TrackRecords[i]
+		if record.ServiceName != other.ServiceName ||
+			record.ServiceDate != other.ServiceDate ||
+			record.ServiceCost != other.ServiceCost ||
+			record.Amount != other.Amount ||
+			record.Status != other.Status ||
+			record.CounselorID != other.CounselorID ||
+			!record.CreatedAt.Equal(other.CreatedAt) ||
+			!record.UpdatedAt.Equal(other.UpdatedAt) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ListForDuplicateScan returns every student that is not deleted, for the duplicate finder.
+func (r *StudentRepository) ListForDuplicateScan() ([]models.Student, error) {
+	studentCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_students")
+	ctx := context.Background()
+
+	cursor, err := studentCollection.Find(ctx, bson.M{"deleted_at": notDeleted})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var students []models.Student
+	if err := cursor.All(ctx, &students); err != nil {
+		return nil, err
+	}
+
+	return students, nil
+}
+
+// moveInvoiceLines hands the invoiced services of merged students to the
+// winner. A service the winner was already invoiced for keeps the winner's
+// claim, like the backfill of tb_invoice_lines keeps the first invoice.
+func moveInvoiceLines(sessCtx mongo.SessionContext, lineCollection *mongo.Collection, winnerID primitive.ObjectID, loserIDs []primitive.ObjectID) error {
+	cursor, err := lineCollection.Find(sessCtx, bson.M{"student_id": bson.M{"$in": loserIDs}})
+	if err != nil {
+		return err
+	}
+	var lines []bson.M
+	if err := cursor.All(sessCtx, &lines); err != nil {
+		return err
+	}
+
+	for _, line := range lines {
+		claimed, err := lineCollection.CountDocuments(sessCtx, bson.M{"student_id": winnerID, "service_name": line["service_name"]})
+		if err != nil {
+			return err
+		}
+		if claimed > 0 {
+			_, err = lineCollection.DeleteOne(sessCtx, bson.M{"_id": line["_id"]})
+		} else {
+			_, err = lineCollection.UpdateOne(sessCtx, bson.M{"_id": line["_id"]}, bson.M{"$set": bson.M{"student_id": winnerID}})
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Merge folds the loser students into the winner in one transaction: track
+// records and lobby history are combined, empty fields of the winner are
+// filled in, invoices and portal accounts are moved to the winner and the
+// losers are soft deleted with merged_into pointing at the winner.
+func (r *StudentRepository) Merge(winnerID primitive.ObjectID, loserIDs []primitive.ObjectID, mergedBy string) (*models.StudentMergeResult, error) {
+	database := r.MongoClient.Database(r.cfg.MongoDBName)
+	studentCollection := database.Collection("tb_students")
+	serviceCollection := database.Collection("tb_service_student")
+	ctx := context.Background()
+
+	location, _ := time.LoadLocation("Asia/Jakarta")
+	result := &models.StudentMergeResult{WinnerID: winnerID}
+
+	err := withTransaction(ctx, r.MongoClient, func(sessCtx mongo.SessionContext) error {
+		*result = models.StudentMergeResult{WinnerID: winnerID}
+
+		var winner models.Student
+		err := studentCollection.FindOne(sessCtx, bson.M{"_id": winnerID, "deleted_at": notDeleted}).Decode(&winner)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				return errors.New("winning student not found")
+			}
+			return err
+		}
+
+		cursor, err := studentCollection.Find(sessCtx, bson.M{"_id": bson.M{"$in": loserIDs}, "deleted_at": notDeleted})
+		if err != nil {
+			return err
+		}
+		var losers []models.Student
+		if err := cursor.All(sessCtx, &losers); err != nil {
+			return err
+		}
+		if len(losers) != len(loserIDs) {
+			return errors.New("one or more students to merge were not found")
+		}
+
+		services := make(map[string]bool, len(winner.TrackRecords))
+		for _, record := range winner.TrackRecords {
+			services[record.ServiceName] = true
+		}
+		if winner.StageTimestamps == nil {
+			winner.StageTimestamps = make(map[string]time.Time)
+		}
+
+		for _, loser := range losers {
+			for _, record := range loser.TrackRecords {
+				if services[record.ServiceName] {
+					result.DroppedServices = append(result.DroppedServices, models.DroppedService{StudentID: loser.ID, TrackRecord: record})
+					continue
+				}
+				services[record.ServiceName] = true
+				winner.TrackRecords = append(winner.TrackRecords, record)
+				result.ServicesAdded++
+			}
+
+			winner.TrackLobby = append(winner.TrackLobby, loser.TrackLobby...)
+			for stage, at := range loser.StageTimestamps {
+				if current, ok := winner.StageTimestamps[stage]; !ok || at.Before(current) {
+					winner.StageTimestamps[stage] = at
+				}
+			}
+			fillEmptyStudentFields(&winner, &loser)
+		}
+		sort.SliceStable(winner.TrackLobby, func(i, j int) bool {
+			return winner.TrackLobby[i].CreatedAt.Before(winner.TrackLobby[j].CreatedAt)
+		})
+
+		now := time.Now().In(location)
+		set := bson.M{
+			"email":             winner.Email,
+			"school":            winner.School,
+			"interest":          winner.Interest,
+			"gender":            winner.Gender,
+			"phone":             winner.Phone,
+			"financial_ability": winner.FinancialAbility,
+			"detailsiswa_link":  winner.DetailSiswaLink,
+			"image":             winner.Image,
+			"category":          winner.Category,
+			"birthdate":         winner.Birthdate,
+			"track_records":     winner.TrackRecords,
+			"track_lobby":       winner.TrackLobby,
+			"stage_timestamps":  winner.StageTimestamps,
+			"updated_at":        now,
+		}
+		if !winner.SchoolID.IsZero() {
+			set["school_id"] = winner.SchoolID
+		}
+		if !winner.CounselorID.IsZero() {
+			set["counselor_id"] = winner.CounselorID
+		}
+		if _, err := studentCollection.UpdateOne(sessCtx, bson.M{"_id": winnerID}, bson.M{"$set": set}); err != nil {
+			return err
+		}
+
+		// The service copy of the winner is rebuilt, the ones of the losers are trashed with them
+		serviceCopy := models.Student{ID: winnerID, Name: winner.Name, TrackRecords: winner.TrackRecords, UpdatedAt: now}
+		if _, err := serviceCollection.ReplaceOne(sessCtx, bson.M{"_id": winnerID}, serviceCopy, options.Replace().SetUpsert(true)); err != nil {
+			return err
+		}
+
+		moveToWinner := bson.M{"$set": bson.M{"student_id": winnerID}}
+		if err := moveInvoiceLines(sessCtx, database.Collection("tb_invoice_lines"), winnerID, loserIDs); err != nil {
+			return err
+		}
+		// Invoices, tasks and the interaction log keep a copy of the student's name for listings
+		moveWithName := bson.M{"$set": bson.M{"student_id": winnerID, "student_name": winner.Name}}
+		for _, name := range []string{"tb_invoices", "tb_tasks", "tb_interactions"} {
+			if _, err := database.Collection(name).UpdateMany(sessCtx, bson.M{"student_id": bson.M{"$in": loserIDs}}, moveWithName); err != nil {
+				return err
+			}
+		}
+		// A student has a single portal account, so only move one if the winner has
+		// none. The other accounts of merged students are deleted with their
+		// sessions and login links, so nobody keeps logging in to a trashed record.
+		userCollection := database.Collection("tb_users")
+		hasAccount, err := userCollection.CountDocuments(sessCtx, bson.M{"student_id": winnerID})
+		if err != nil {
+			return err
+		}
+		if hasAccount == 0 {
+			if _, err := userCollection.UpdateOne(sessCtx, bson.M{"student_id": bson.M{"$in": loserIDs}}, moveToWinner); err != nil {
+				return err
+			}
+		}
+		userIDs, err := userCollection.Distinct(sessCtx, "_id", bson.M{"student_id": bson.M{"$in": loserIDs}})
+		if err != nil {
+			return err
+		}
+		if len(userIDs) > 0 {
+			for _, name := range []string{"tb_user_tokens", "tb_user_magic_links"} {
+				if _, err := database.Collection(name).DeleteMany(sessCtx, bson.M{"user_id": bson.M{"$in": userIDs}}); err != nil {
+					return err
+				}
+			}
+			removed, err := userCollection.DeleteMany(sessCtx, bson.M{"_id": bson.M{"$in": userIDs}})
+			if err != nil {
+				return err
+			}
+			result.RemovedAccounts = removed.DeletedCount
+		}
+
+		for _, loser := range losers {
+			if err := softDelete(sessCtx, studentCollection, loser.ID, mergedBy, bson.M{"merged_into": winnerID}); err != nil {
+				return err
+			}
+			err := softDelete(sessCtx, serviceCollection, loser.ID, mergedBy, nil)
+			if err != nil && err != mongo.ErrNoDocuments {
+				return err
+			}
+		}
+		result.Merged = len(losers)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return result, nil
 }
+
+// fillEmptyStudentFields copies the profile fields the winner is missing from a merged student.
+func fillEmptyStudentFields(winner, loser *models.Student) {
+	fields := []struct{ dst, src *string }{
+		{&winner.Email, &loser.Email},
+		{&winner.Interest, &loser.Interest},
+		{&winner.Gender, &loser.Gender},
+		{&winner.Phone, &loser.Phone},
+		{&winner.FinancialAbility, &loser.FinancialAbility},
+		{&winner.DetailSiswaLink, &loser.DetailSiswaLink},
+		{&winner.Image, &loser.Image},
+		{&winner.Category, &loser.Category},
+		{&winner.Birthdate, &loser.Birthdate},
+	}
+	for _, field := range fields {
+		if *field.dst == "" {
+			*field.dst = *field.src
+		}
+	}
+
+	if winner.SchoolID.IsZero() && !loser.SchoolID.IsZero() {
+		winner.SchoolID = loser.SchoolID
+		winner.School = loser.School
+	}
+	if winner.CounselorID.IsZero() {
+		winner.CounselorID = loser.CounselorID
+	}
+}
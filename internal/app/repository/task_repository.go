@@ -0,0 +1,247 @@
+package repository
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"elible/internal/app/models"
+	"elible/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type TaskRepository struct {
+	MongoClient *mongo.Client
+	cfg         *config.Config
+}
+
+func NewTaskRepository(cfg *config.Config, mongoClient *mongo.Client) *TaskRepository {
+	return &TaskRepository{
+		cfg:         cfg,
+		MongoClient: mongoClient,
+	}
+}
+
+func (r *TaskRepository) Create(task *models.Task) error {
+	taskCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_tasks")
+	ctx := context.Background()
+
+	location, _ := time.LoadLocation("Asia/Jakarta")
+	task.CreatedAt = time.Now().In(location)
+	task.UpdatedAt = task.CreatedAt
+
+	result, err := taskCollection.InsertOne(ctx, task)
+	if err != nil {
+		return err
+	}
+	task.ID = result.InsertedID.(primitive.ObjectID)
+
+	return nil
+}
+
+func (r *TaskRepository) FindByID(id primitive.ObjectID) (*models.Task, error) {
+	taskCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_tasks")
+	ctx := context.Background()
+
+	var task models.Task
+	err := taskCollection.FindOne(ctx, bson.M{"_id": id}).Decode(&task)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &task, nil
+}
+
+// Update sets the given fields and unsets the ones in unset.
+func (r *TaskRepository) Update(id primitive.ObjectID, set bson.M, unset []string) error {
+	taskCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_tasks")
+	ctx := context.Background()
+
+	location, _ := time.LoadLocation("Asia/Jakarta")
+	set["updated_at"] = time.Now().In(location)
+
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		fields := bson.M{}
+		for _, field := range unset {
+			fields[field] = ""
+		}
+		update["$unset"] = fields
+	}
+
+	result, err := taskCollection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// CancelOpenStageTasks cancels the open automatic follow-ups of a student,
+// which belong to a stage the student has left.
+func (r *TaskRepository) CancelOpenStageTasks(studentID primitive.ObjectID) error {
+	taskCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_tasks")
+	ctx := context.Background()
+
+	location, _ := time.LoadLocation("Asia/Jakarta")
+	_, err := taskCollection.UpdateMany(
+		ctx,
+		bson.M{"student_id": studentID, "source": models.TaskSourceStage, "status": models.TaskStatusOpen},
+		bson.M{"$set": bson.M{"status": models.TaskStatusCancelled, "updated_at": time.Now().In(location)}},
+	)
+	return err
+}
+
+// List returns tasks by due date, earliest first.
+func (r *TaskRepository) List(filter *models.TaskFilter) (*models.PagedTasks, error) {
+	taskCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_tasks")
+	ctx := context.Background()
+
+	bsonFilter := bson.M{}
+	if filter.StudentID != nil && *filter.StudentID != "" {
+		studentID, err := primitive.ObjectIDFromHex(*filter.StudentID)
+		if err != nil {
+			return nil, err
+		}
+		bsonFilter["student_id"] = studentID
+	}
+	if filter.AssigneeID != nil && *filter.AssigneeID != "" {
+		assigneeID, err := primitive.ObjectIDFromHex(*filter.AssigneeID)
+		if err != nil {
+			return nil, err
+		}
+		bsonFilter["assignee_id"] = assigneeID
+	}
+	if filter.Status != nil && *filter.Status != "" {
+		bsonFilter["status"] = *filter.Status
+	}
+
+	// Dates are inclusive days in YYYY-MM-DD, Jakarta time
+	location, _ := time.LoadLocation("Asia/Jakarta")
+	dueAt := bson.M{}
+	if filter.DueFrom != nil && *filter.DueFrom != "" {
+		from, err := time.ParseInLocation("2006-01-02", *filter.DueFrom, location)
+		if err != nil {
+			return nil, err
+		}
+		dueAt["$gte"] = from
+	}
+	if filter.DueTo != nil && *filter.DueTo != "" {
+		to, err := time.ParseInLocation("2006-01-02", *filter.DueTo, location)
+		if err != nil {
+			return nil, err
+		}
+		dueAt["$lt"] = to.AddDate(0, 0, 1)
+	}
+	if filter.Overdue != nil && *filter.Overdue {
+		bsonFilter["status"] = models.TaskStatusOpen
+		if lt, ok := dueAt["$lt"].(time.Time); !ok || time.Now().Before(lt) {
+			dueAt["$lt"] = time.Now()
+		}
+	}
+	if len(dueAt) > 0 {
+		bsonFilter["due_at"] = dueAt
+	}
+
+	page, pageSize := 1, 20
+	if filter.Page != nil && *filter.Page > 0 {
+		page = *filter.Page
+	}
+	if filter.PageSize != nil && *filter.PageSize > 0 {
+		pageSize = *filter.PageSize
+	}
+
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "due_at", Value: 1}, {Key: "_id", Value: 1}}).
+		SetSkip(int64((page - 1) * pageSize)).
+		SetLimit(int64(pageSize))
+
+	cursor, err := taskCollection.Find(ctx, bsonFilter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var tasks []models.Task
+	if err := cursor.All(ctx, &tasks); err != nil {
+		return nil, err
+	}
+
+	total, err := taskCollection.CountDocuments(ctx, bsonFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.PagedTasks{
+		CurrentPage:  page,
+		TotalRecords: total,
+		TotalPages:   int(math.Ceil(float64(total) / float64(pageSize))),
+		Records:      tasks,
+	}, nil
+}
+
+// OpenAssignedBefore returns the open tasks with an assignee that are due
+// before the given time, grouped by assignee and then by due date.
+func (r *TaskRepository) OpenAssignedBefore(until time.Time) ([]models.Task, error) {
+	taskCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_tasks")
+	ctx := context.Background()
+
+	filter := bson.M{
+		"status":      models.TaskStatusOpen,
+		"assignee_id": bson.M{"$exists": true},
+		"due_at":      bson.M{"$lt": until},
+	}
+	findOptions := options.Find().SetSort(bson.D{{Key: "assignee_id", Value: 1}, {Key: "due_at", Value: 1}})
+
+	cursor, err := taskCollection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var tasks []models.Task
+	if err := cursor.All(ctx, &tasks); err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+// ClaimDigest marks the digest of a day as sent to an assignee. It returns
+// false when it was claimed before, so a restart or a second instance does not
+// send it twice.
+func (r *TaskRepository) ClaimDigest(day string, assigneeID primitive.ObjectID) (bool, error) {
+	digestCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_task_digests")
+	ctx := context.Background()
+
+	location, _ := time.LoadLocation("Asia/Jakarta")
+	_, err := digestCollection.InsertOne(ctx, bson.M{"_id": day + "/" + assigneeID.Hex(), "sent_at": time.Now().In(location)})
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// ReleaseDigest undoes ClaimDigest after the digest could not be sent, so the
+// next run tries again.
+func (r *TaskRepository) ReleaseDigest(day string, assigneeID primitive.ObjectID) error {
+	digestCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_task_digests")
+	ctx := context.Background()
+
+	_, err := digestCollection.DeleteOne(ctx, bson.M{"_id": day + "/" + assigneeID.Hex()})
+	return err
+}
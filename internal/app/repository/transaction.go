@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// withTransaction runs fn inside a multi-document transaction, retrying on
+// transient errors. Transactions need a replica set or sharded cluster, which
+// includes every MongoDB Atlas deployment.
+func withTransaction(ctx context.Context, client *mongo.Client, fn func(sessCtx mongo.SessionContext) error) error {
+	session, err := client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sessCtx)
+	})
+	return err
+}
@@ -54,17 +54,53 @@ func (r *UniversityRepository) UpdateUniversity(id primitive.ObjectID, u models.
 	ctx := context.Background()
 
 	u.UpdatedAt = time.Now()
+	// Deletion is only changed through DeleteUniversity and RestoreUniversity
+	u.DeletedAt = nil
+	u.DeletedBy = ""
 
-	_, err := UniversityCollection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": u})
-	return err
+	result, err := UniversityCollection.UpdateOne(ctx, bson.M{"_id": id, "deleted_at": notDeleted}, bson.M{"$set": u})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+func (r *UniversityRepository) DeleteUniversity(id primitive.ObjectID, deletedBy string) error {
+	UniversityCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_universities")
+	ctx := context.Background()
+
+	return softDelete(ctx, UniversityCollection, id, deletedBy, nil)
+}
+
+func (r *UniversityRepository) RestoreUniversity(id primitive.ObjectID) error {
+	UniversityCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_universities")
+	ctx := context.Background()
+
+	return restoreDeleted(ctx, UniversityCollection, id)
+}
+
+func (r *UniversityRepository) GetDeletedUniversities() ([]models.University, error) {
+	UniversityCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_universities")
+	ctx := context.Background()
+
+	var universities []models.University
+	if err := findDeleted(ctx, UniversityCollection, &universities); err != nil {
+		return nil, err
+	}
+
+	return universities, nil
 }
 
-func (r *UniversityRepository) DeleteUniversity(id primitive.ObjectID) error {
+// PurgeDeleted permanently removes universities deleted before the given time.
+func (r *UniversityRepository) PurgeDeleted(before time.Time) (int64, error) {
 	UniversityCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_universities")
 	ctx := context.Background()
 
-	_, err := UniversityCollection.DeleteOne(ctx, bson.M{"_id": id})
-	return err
+	return purgeDeleted(ctx, UniversityCollection, before)
 }
 
 func (r *UniversityRepository) GetUniversity(id primitive.ObjectID) (models.University, error) {
@@ -72,7 +108,7 @@ func (r *UniversityRepository) GetUniversity(id primitive.ObjectID) (models.Univ
 	ctx := context.Background()
 
 	var u models.University
-	err := UniversityCollection.FindOne(ctx, bson.M{"_id": id}).Decode(&u)
+	err := UniversityCollection.FindOne(ctx, bson.M{"_id": id, "deleted_at": notDeleted}).Decode(&u)
 	return u, err
 }
 
@@ -81,7 +117,7 @@ func (r *UniversityRepository) GetUniversityByName(name string) (models.Universi
 	ctx := context.Background()
 
 	var u models.University
-	err := UniversityCollection.FindOne(ctx, bson.M{"name": name}).Decode(&u)
+	err := UniversityCollection.FindOne(ctx, bson.M{"name": name, "deleted_at": notDeleted}).Decode(&u)
 	return u, err
 }
 
@@ -89,7 +125,7 @@ func (r *UniversityRepository) GetUniversities() ([]models.University, error) {
 	UniversityCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_universities")
 	ctx := context.Background()
 
-	cursor, err := UniversityCollection.Find(ctx, bson.M{})
+	cursor, err := UniversityCollection.Find(ctx, bson.M{"deleted_at": notDeleted})
 	if err != nil {
 		return nil, err
 	}
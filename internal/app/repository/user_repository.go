@@ -1 +1,153 @@
-package repository
\ No newline at end of file
+package repository
+
+import (
+	"context"
+	"time"
+
+	"elible/internal/app/models"
+	"elible/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type UserRepository struct {
+	MongoClient *mongo.Client
+	cfg         *config.Config
+}
+
+func NewUserRepository(cfg *config.Config, mongoClient *mongo.Client) *UserRepository {
+
+	return &UserRepository{
+		cfg:         cfg,
+		MongoClient: mongoClient,
+	}
+}
+
+func (r *UserRepository) Create(user *models.User) error {
+	userCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_users")
+	ctx := context.Background()
+
+	// Use Jakarta's time zone
+	location, _ := time.LoadLocation("Asia/Jakarta")
+
+	user.CreatedAt = time.Now().In(location)
+	user.UpdatedAt = time.Now().In(location)
+
+	res, err := userCollection.InsertOne(ctx, user)
+	if err != nil {
+		return err
+	}
+	user.ID = res.InsertedID.(primitive.ObjectID)
+
+	return nil
+}
+
+func (r *UserRepository) FindByEmail(email string) (*models.User, error) {
+	return r.findOne(bson.M{"email": email})
+}
+
+func (r *UserRepository) FindByID(id primitive.ObjectID) (*models.User, error) {
+	return r.findOne(bson.M{"_id": id})
+}
+
+func (r *UserRepository) findOne(filter bson.M) (*models.User, error) {
+	userCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_users")
+	ctx := context.Background()
+
+	var user models.User
+	err := userCollection.FindOne(ctx, filter).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r *UserRepository) Update(id primitive.ObjectID, fields bson.M) error {
+	userCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_users")
+	ctx := context.Background()
+
+	fields["updated_at"] = time.Now()
+
+	_, err := userCollection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": fields})
+	return err
+}
+
+func (r *UserRepository) SaveToken(token *models.UserToken) error {
+	tokenCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_user_tokens")
+	ctx := context.Background()
+
+	token.CreatedAt = time.Now()
+
+	_, err := tokenCollection.InsertOne(ctx, token)
+	return err
+}
+
+func (r *UserRepository) DeleteToken(accessToken string) error {
+	tokenCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_user_tokens")
+	ctx := context.Background()
+
+	_, err := tokenCollection.DeleteOne(ctx, bson.M{"access_token": accessToken})
+	return err
+}
+
+func (r *UserRepository) GetUserByToken(accessToken string) (*models.User, error) {
+	tokenCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_user_tokens")
+	ctx := context.Background()
+
+	var token models.UserToken
+	err := tokenCollection.FindOne(ctx, bson.M{"access_token": accessToken, "expires_at": bson.M{"$gt": time.Now().Unix()}}).Decode(&token)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return r.FindByID(token.UserID)
+}
+
+// SaveMagicLink stores a new login link and invalidates older unused ones of the same user.
+func (r *UserRepository) SaveMagicLink(link *models.MagicLink) error {
+	linkCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_user_magic_links")
+	ctx := context.Background()
+
+	now := time.Now()
+	_, err := linkCollection.UpdateMany(ctx, bson.M{"user_id": link.UserID, "used_at": bson.M{"$exists": false}}, bson.M{"$set": bson.M{"used_at": now}})
+	if err != nil {
+		return err
+	}
+
+	link.CreatedAt = now
+	_, err = linkCollection.InsertOne(ctx, link)
+	return err
+}
+
+// UseMagicLink atomically marks a valid login link as used and returns it.
+func (r *UserRepository) UseMagicLink(tokenHash string) (*models.MagicLink, error) {
+	linkCollection := r.MongoClient.Database(r.cfg.MongoDBName).Collection("tb_user_magic_links")
+	ctx := context.Background()
+
+	now := time.Now()
+	filter := bson.M{
+		"token_hash": tokenHash,
+		"used_at":    bson.M{"$exists": false},
+		"expires_at": bson.M{"$gt": now},
+	}
+
+	var link models.MagicLink
+	err := linkCollection.FindOneAndUpdate(ctx, filter, bson.M{"$set": bson.M{"used_at": now}}).Decode(&link)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &link, nil
+}
@@ -4,21 +4,41 @@ package services
 import (
 	"errors"
 	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
 
 	"elible/internal/app/models"
 	"elible/internal/app/repository"
 	"elible/internal/app/utils"
+	"elible/internal/config"
+	"elible/internal/mailer"
 
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"golang.org/x/crypto/bcrypt"
 )
 
+const (
+	MinPasswordLength     = 8
+	PasswordResetLifetime = time.Hour
+
+	totpIssuer        = "Elible"
+	recoveryCodeCount = 10
+)
+
 type AdminService struct {
-	repo *repository.AdminRepository
+	repo   *repository.AdminRepository
+	cfg    *config.Config
+	mailer mailer.Mailer
 }
 
-func NewAdminService(repo *repository.AdminRepository) *AdminService {
+func NewAdminService(cfg *config.Config, repo *repository.AdminRepository, mail mailer.Mailer) *AdminService {
 	return &AdminService{
-		repo: repo,
+		repo:   repo,
+		cfg:    cfg,
+		mailer: mail,
 	}
 }
 
@@ -32,14 +52,31 @@ func (s *AdminService) Create(admin *models.Admin) error {
 		return errors.New("admin already exists")
 	}
 
+	if admin.Role == "" {
+		admin.Role = models.RoleViewer
+	}
+	if !admin.Role.IsValid() {
+		return errors.New("invalid role")
+	}
+
+	if len(admin.Password) < MinPasswordLength {
+		return errors.New("password must be at least 8 characters")
+	}
+
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(admin.Password), bcrypt.DefaultCost)
 	if err != nil {
 		return err
 	}
-	
-	fmt.Println("password ASeli :", admin.Password)
+
 	admin.Password = string(hashedPassword)
-	fmt.Println("Hashed password during creation:", admin.Password)
+	admin.Disabled = false
+	// Two-factor authentication is only set up through enrollment
+	admin.TOTPSecret = ""
+	admin.TOTPEnabled = false
+	admin.TOTPLastStep = 0
+	admin.RecoveryCodes = nil
+	admin.CreatedAt = time.Now()
+	admin.UpdatedAt = time.Now()
 
 	if err := s.repo.Create(admin); err != nil {
 		return err
@@ -48,39 +85,657 @@ func (s *AdminService) Create(admin *models.Admin) error {
 	return nil
 }
 
-func (s *AdminService) Login(username, password string) (*models.Admin, string, error) {
+func (s *AdminService) Login(username, password string, session models.SessionInfo) (*models.LoginResult, error) {
+	if err := s.checkLoginAllowed(username, session); err != nil {
+		return nil, err
+	}
+
 	admin, err := s.repo.FindByUsername(username)
 	if err != nil {
-		return nil, "", err
+		return nil, err
 	}
 
 	if admin == nil {
-		return nil, "", errors.New("admin not found")
+		s.recordFailedLogin(username, nil, session, "unknown username")
+		return nil, errors.New("admin not found")
+	}
+
+	if admin.Disabled {
+		s.recordFailedLogin(username, admin, session, "account disabled")
+		return nil, errors.New("admin account is disabled")
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(admin.Password), []byte(password)); err != nil {
-		return nil, "", errors.New("invalid password")
+		s.recordFailedLogin(username, admin, session, "invalid password")
+		return nil, errors.New("invalid password")
+	}
+
+	// With two-factor authentication the session is only issued by VerifyLogin
+	if admin.TOTPEnabled {
+		mfaToken, err := utils.CreateMFAToken(s.cfg, admin.ID.Hex())
+		if err != nil {
+			return nil, err
+		}
+		return &models.LoginResult{Admin: admin, MFARequired: true, MFAToken: mfaToken}, nil
 	}
 
-	tokenDetails, err := utils.CreateToken(username)
+	s.resetFailedLogins(username)
+	return s.issueSession(admin, session)
+}
+
+// VerifyLogin completes a two-step login with either a TOTP code or a recovery code.
+func (s *AdminService) VerifyLogin(mfaToken, code, recoveryCode string, session models.SessionInfo) (*models.LoginResult, error) {
+	adminID, err := utils.ValidateMFAToken(mfaToken, s.cfg)
 	if err != nil {
-		return nil, "", err
+		return nil, errors.New("invalid or expired two-factor token")
+	}
+
+	admin, err := s.GetByID(adminID)
+	if err != nil {
+		return nil, err
+	}
+	if admin.Disabled || !admin.TOTPEnabled {
+		return nil, errors.New("invalid or expired two-factor token")
+	}
+
+	if err := s.checkLoginAllowed(admin.Username, session); err != nil {
+		return nil, err
+	}
+
+	if err := s.verifySecondFactor(admin, code, recoveryCode); err != nil {
+		s.recordFailedLogin(admin.Username, admin, session, err.Error())
+		return nil, err
+	}
+
+	s.resetFailedLogins(admin.Username)
+	return s.issueSession(admin, session)
+}
+
+func (s *AdminService) issueSession(admin *models.Admin, session models.SessionInfo) (*models.LoginResult, error) {
+	tokenDetails, err := utils.CreateToken(s.cfg, admin.ID.Hex())
+	if err != nil {
+		return nil, err
 	}
 
 	token := &models.Token{
-		AccessToken: tokenDetails.AccessToken,
-		AccessUUID:  admin.ID.Hex(),
-		AtExpires:   tokenDetails.AtExpires,
+		AccessToken:  tokenDetails.AccessToken,
+		AccessUUID:   admin.ID.Hex(),
+		AtExpires:    tokenDetails.AtExpires,
+		RefreshToken: tokenDetails.RefreshToken,
+		RtExpires:    tokenDetails.RtExpires,
+		UserAgent:    session.UserAgent,
+		IPAddress:    session.IPAddress,
 	}
 
 	// Store the token into the database
 	if err = s.repo.SaveToken(token); err != nil {
-		return nil, "", err
+		return nil, err
 	}
 
-	return admin, token.AccessToken, nil
+	if err = s.repo.RecordLogin(&models.LoginHistory{
+		AccessUUID: token.AccessUUID,
+		Username:   admin.Username,
+		UserAgent:  session.UserAgent,
+		IPAddress:  session.IPAddress,
+		Status:     models.LoginStatusSuccess,
+	}); err != nil {
+		return nil, err
+	}
+
+	return &models.LoginResult{Admin: admin, Token: token}, nil
+}
+
+func (s *AdminService) verifySecondFactor(admin *models.Admin, code, recoveryCode string) error {
+	if recoveryCode != "" {
+		used, err := s.repo.UseRecoveryCode(admin.ID, utils.HashToken(normalizeRecoveryCode(recoveryCode)))
+		if err != nil {
+			return err
+		}
+		if !used {
+			return errors.New("invalid recovery code")
+		}
+		return nil
+	}
+
+	step, ok := utils.ValidateTOTP(admin.TOTPSecret, code, time.Now())
+	if !ok {
+		return errors.New("invalid two-factor code")
+	}
+
+	fresh, err := s.repo.UseTOTPStep(admin.ID, step)
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		return errors.New("two-factor code has already been used")
+	}
+	return nil
+}
+
+// SetupTOTP starts enrollment with a new secret. It is not required at login until ConfirmTOTP succeeds.
+func (s *AdminService) SetupTOTP(admin *models.Admin) (*models.TOTPSetup, error) {
+	if admin.TOTPEnabled {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(admin.ID, bson.M{"totpSecret": secret}); err != nil {
+		return nil, err
+	}
+
+	return &models.TOTPSetup{
+		Secret:          secret,
+		ProvisioningURI: utils.TOTPProvisioningURI(totpIssuer, admin.Username, secret),
+	}, nil
+}
+
+// ConfirmTOTP enables two-factor authentication and returns the recovery codes, which are only shown once.
+func (s *AdminService) ConfirmTOTP(admin *models.Admin, code string) ([]string, error) {
+	if admin.TOTPEnabled {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+	if admin.TOTPSecret == "" {
+		return nil, errors.New("two-factor setup has not been started")
+	}
+
+	step, ok := utils.ValidateTOTP(admin.TOTPSecret, code, time.Now())
+	if !ok {
+		return nil, errors.New("invalid two-factor code")
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	fields := bson.M{
+		"totpEnabled":   true,
+		"totpLastStep":  step,
+		"recoveryCodes": hashes,
+	}
+	if err := s.repo.Update(admin.ID, fields); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+func (s *AdminService) RegenerateRecoveryCodes(admin *models.Admin, code string) ([]string, error) {
+	if !admin.TOTPEnabled {
+		return nil, errors.New("two-factor authentication is not enabled")
+	}
+
+	if err := s.verifySecondFactor(admin, code, ""); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(admin.ID, bson.M{"recoveryCodes": hashes}); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+func (s *AdminService) DisableTOTP(admin *models.Admin, password, code string) error {
+	if !admin.TOTPEnabled {
+		return errors.New("two-factor authentication is not enabled")
+	}
+	if admin.EffectiveRole() == models.RoleSuperAdmin {
+		return errors.New("superadmins must keep two-factor authentication enabled")
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(admin.Password), []byte(password)); err != nil {
+		return errors.New("invalid password")
+	}
+
+	if err := s.verifySecondFactor(admin, code, ""); err != nil {
+		return err
+	}
+
+	return s.repo.ClearTOTP(admin.ID)
+}
+
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := utils.RandomString(5)
+		if err != nil {
+			return nil, nil, err
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, utils.HashToken(code))
+	}
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+}
+
+func (s *AdminService) Refresh(refreshToken string) (*models.Token, error) {
+	token, err := s.repo.FindByRefreshToken(refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if token == nil || token.RtExpires < time.Now().Unix() {
+		return nil, errors.New("invalid or expired refresh token")
+	}
+
+	tokenDetails, err := utils.CreateToken(s.cfg, token.AccessUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	token.AccessToken = tokenDetails.AccessToken
+	token.AtExpires = tokenDetails.AtExpires
+	token.RefreshToken = tokenDetails.RefreshToken
+	token.RtExpires = tokenDetails.RtExpires
+
+	rotated, err := s.repo.RotateToken(token.ID, refreshToken, token)
+	if err != nil {
+		return nil, err
+	}
+	if !rotated {
+		// The refresh token was used twice, so it may have leaked: end the session
+		if _, err := s.repo.DeleteSession(token.AccessUUID, token.ID); err != nil {
+			log.Printf("Error while revoking reused session, Reason: %v\n", err)
+		}
+		return nil, errors.New("invalid or expired refresh token")
+	}
+
+	return token, nil
+}
+
+func (s *AdminService) Logout(accessToken string) error {
+	return s.repo.DeleteTokenByValue(accessToken)
+}
+
+func (s *AdminService) LogoutAll(admin *models.Admin) error {
+	return s.repo.DeleteAllTokens(admin.ID.Hex())
+}
+
+func (s *AdminService) ListSessions(admin *models.Admin, currentToken string) ([]models.Session, error) {
+	tokens, err := s.repo.ListSessions(admin.ID.Hex())
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]models.Session, 0, len(tokens))
+	for _, token := range tokens {
+		expiresAt := token.RtExpires
+		if expiresAt == 0 {
+			expiresAt = token.AtExpires
+		}
+		sessions = append(sessions, models.Session{
+			ID:         token.ID,
+			UserAgent:  token.UserAgent,
+			IPAddress:  token.IPAddress,
+			CreatedAt:  token.CreatedAt,
+			LastSeenAt: token.LastSeenAt,
+			ExpiresAt:  expiresAt,
+			Current:    token.AccessToken == currentToken,
+		})
+	}
+
+	return sessions, nil
+}
+
+func (s *AdminService) RevokeSession(admin *models.Admin, sessionID string) error {
+	objectId, err := primitive.ObjectIDFromHex(sessionID)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := s.repo.DeleteSession(admin.ID.Hex(), objectId)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return errors.New("session not found")
+	}
+
+	return nil
+}
+
+func (s *AdminService) LoginHistory(admin *models.Admin, limit int64) ([]models.LoginHistory, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+
+	history, err := s.repo.ListLoginHistory(admin.ID.Hex(), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Older entries have no createdAt, the ObjectID still tells when they were written
+	for i := range history {
+		if history[i].CreatedAt.IsZero() {
+			history[i].CreatedAt = history[i].ID.Timestamp()
+		}
+	}
+
+	return history, nil
 }
 
 func (s *AdminService) GetAdminByToken(token string) (*models.Admin, error) {
 	return s.repo.GetAdminByToken(token)
 }
+
+func (s *AdminService) AssignRole(adminID string, role models.Role) (*models.Admin, error) {
+	objectId, err := primitive.ObjectIDFromHex(adminID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !role.IsValid() {
+		return nil, errors.New("invalid role")
+	}
+
+	admin, err := s.repo.FindByID(objectId)
+	if err != nil {
+		return nil, err
+	}
+	if admin == nil {
+		return nil, errors.New("admin not found")
+	}
+
+	if admin.Role == models.RoleSuperAdmin && role != models.RoleSuperAdmin && !admin.Disabled {
+		if err := s.ensureAnotherSuperAdmin(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.UpdateRole(objectId, role); err != nil {
+		return nil, err
+	}
+
+	admin.Role = role
+	return admin, nil
+}
+
+func (s *AdminService) List() ([]models.Admin, error) {
+	return s.repo.List()
+}
+
+func (s *AdminService) GetByID(adminID string) (*models.Admin, error) {
+	objectId, err := primitive.ObjectIDFromHex(adminID)
+	if err != nil {
+		return nil, err
+	}
+
+	admin, err := s.repo.FindByID(objectId)
+	if err != nil {
+		return nil, err
+	}
+	if admin == nil {
+		return nil, errors.New("admin not found")
+	}
+
+	return admin, nil
+}
+
+func (s *AdminService) UpdateProfile(adminID string, profile models.AdminProfile) (*models.Admin, error) {
+	admin, err := s.GetByID(adminID)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := bson.M{}
+	if profile.Username != "" && profile.Username != admin.Username {
+		existingAdmin, err := s.repo.FindByUsername(profile.Username)
+		if err != nil {
+			return nil, err
+		}
+		if existingAdmin != nil {
+			return nil, errors.New("username is already taken")
+		}
+		fields["username"] = profile.Username
+		admin.Username = profile.Username
+	}
+	if profile.Email != "" {
+		fields["email"] = profile.Email
+		admin.Email = profile.Email
+	}
+	if profile.FullName != "" {
+		fields["fullName"] = profile.FullName
+		admin.FullName = profile.FullName
+	}
+
+	if len(fields) == 0 {
+		return admin, nil
+	}
+
+	if err := s.repo.Update(admin.ID, fields); err != nil {
+		return nil, err
+	}
+
+	return admin, nil
+}
+
+// ChangePassword verifies the current password and signs out every other session.
+func (s *AdminService) ChangePassword(admin *models.Admin, currentToken, oldPassword, newPassword string) error {
+	if err := bcrypt.CompareHashAndPassword([]byte(admin.Password), []byte(oldPassword)); err != nil {
+		return errors.New("invalid password")
+	}
+
+	if err := s.setPassword(admin, newPassword); err != nil {
+		return err
+	}
+
+	return s.repo.DeleteOtherTokens(admin.ID.Hex(), currentToken)
+}
+
+// ForgotPassword emails a reset link. It does not reveal whether the email
+// belongs to an admin, so failures are only logged.
+func (s *AdminService) ForgotPassword(email string) {
+	admin, err := s.repo.FindByEmail(email)
+	if err != nil {
+		log.Printf("forgot password: %v", err)
+		return
+	}
+	if admin == nil || admin.Disabled {
+		return
+	}
+
+	if err := s.sendPasswordReset(admin); err != nil {
+		log.Printf("forgot password: send reset link to admin %s: %v", admin.ID.Hex(), err)
+	}
+}
+
+func (s *AdminService) sendPasswordReset(admin *models.Admin) error {
+	token, err := utils.RandomString(32)
+	if err != nil {
+		return err
+	}
+
+	reset := &models.PasswordReset{
+		AdminID:   admin.ID,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(PasswordResetLifetime),
+	}
+	if err := s.repo.SavePasswordReset(reset); err != nil {
+		return err
+	}
+
+	body, err := mailer.Render(mailer.PasswordResetTemplate, mailer.PasswordResetData{
+		Name:      admin.FullName,
+		Username:  admin.Username,
+		Link:      s.cfg.PasswordResetURL + "?token=" + url.QueryEscape(token),
+		ExpiresIn: PasswordResetLifetime.String(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(admin.Email, mailer.PasswordResetSubject, body)
+}
+
+// ResetPassword consumes a reset token, sets the new password and ends all sessions.
+func (s *AdminService) ResetPassword(token, newPassword string) error {
+	if len(newPassword) < MinPasswordLength {
+		return errors.New("password must be at least 8 characters")
+	}
+
+	reset, err := s.repo.UsePasswordReset(utils.HashToken(token))
+	if err != nil {
+		return err
+	}
+	if reset == nil {
+		return errors.New("invalid or expired reset token")
+	}
+
+	admin, err := s.repo.FindByID(reset.AdminID)
+	if err != nil {
+		return err
+	}
+	if admin == nil || admin.Disabled {
+		return errors.New("invalid or expired reset token")
+	}
+
+	if err := s.setPassword(admin, newPassword); err != nil {
+		return err
+	}
+
+	return s.repo.DeleteAllTokens(admin.ID.Hex())
+}
+
+func (s *AdminService) setPassword(admin *models.Admin, password string) error {
+	if len(password) < MinPasswordLength {
+		return errors.New("password must be at least 8 characters")
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	admin.Password = string(hashedPassword)
+	return s.repo.Update(admin.ID, bson.M{"password": admin.Password})
+}
+
+// SetDisabled enables or disables an account. Disabling ends all of its sessions.
+func (s *AdminService) SetDisabled(actor *models.Admin, adminID string, disabled bool) (*models.Admin, error) {
+	admin, err := s.GetByID(adminID)
+	if err != nil {
+		return nil, err
+	}
+
+	if disabled {
+		if admin.ID == actor.ID {
+			return nil, errors.New("you cannot disable your own account")
+		}
+		if admin.Role == models.RoleSuperAdmin && !admin.Disabled {
+			if err := s.ensureAnotherSuperAdmin(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := s.repo.Update(admin.ID, bson.M{"disabled": disabled}); err != nil {
+		return nil, err
+	}
+
+	if disabled {
+		if err := s.repo.DeleteAllTokens(admin.ID.Hex()); err != nil {
+			return nil, err
+		}
+	}
+
+	admin.Disabled = disabled
+	return admin, nil
+}
+
+func (s *AdminService) Delete(actor *models.Admin, adminID string) error {
+	admin, err := s.GetByID(adminID)
+	if err != nil {
+		return err
+	}
+
+	if admin.ID == actor.ID {
+		return errors.New("you cannot delete your own account")
+	}
+	if admin.Role == models.RoleSuperAdmin && !admin.Disabled {
+		if err := s.ensureAnotherSuperAdmin(); err != nil {
+			return err
+		}
+	}
+
+	if err := s.repo.DeleteAllTokens(admin.ID.Hex()); err != nil {
+		return err
+	}
+
+	return s.repo.Delete(admin.ID)
+}
+
+func (s *AdminService) EnsureIndexes() error {
+	if err := s.repo.EnsureTokenIndexes(); err != nil {
+		return err
+	}
+	return s.repo.EnsureLoginAttemptIndexes(loginAttemptWindow)
+}
+
+// EnsureSuperAdmin promotes the oldest admin when no superadmin exists yet,
+// so deployments that predate roles keep someone able to assign them. A fresh
+// deployment without any admin gets its first superadmin from the
+// BOOTSTRAP_ADMIN_* settings.
+func (s *AdminService) EnsureSuperAdmin() error {
+	count, err := s.repo.CountByRole(models.RoleSuperAdmin)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	admin, err := s.repo.FindOldest()
+	if err != nil {
+		return err
+	}
+	if admin == nil {
+		return s.bootstrapSuperAdmin()
+	}
+
+	return s.repo.UpdateRole(admin.ID, models.RoleSuperAdmin)
+}
+
+func (s *AdminService) bootstrapSuperAdmin() error {
+	if s.cfg.BootstrapAdminUsername == "" || s.cfg.BootstrapAdminPassword == "" {
+		log.Println("No admin exists yet; set BOOTSTRAP_ADMIN_USERNAME and BOOTSTRAP_ADMIN_PASSWORD to create the first superadmin")
+		return nil
+	}
+
+	admin := &models.Admin{
+		Username: s.cfg.BootstrapAdminUsername,
+		Password: s.cfg.BootstrapAdminPassword,
+		Email:    s.cfg.BootstrapAdminEmail,
+		Role:     models.RoleSuperAdmin,
+	}
+	if err := s.Create(admin); err != nil {
+		return fmt.Errorf("creating bootstrap superadmin: %v", err)
+	}
+
+	log.Printf("Created superadmin %q from BOOTSTRAP_ADMIN_USERNAME\n", admin.Username)
+	return nil
+}
+
+func (s *AdminService) ensureAnotherSuperAdmin() error {
+	count, err := s.repo.CountByRole(models.RoleSuperAdmin)
+	if err != nil {
+		return err
+	}
+	if count <= 1 {
+		return errors.New("cannot remove the last superadmin")
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package services
+
+import (
+	"elible/internal/app/models"
+	"elible/internal/app/repository"
+)
+
+type AnalyticsService struct {
+	repo     *repository.AnalyticsRepository
+	pipeline *PipelineService
+}
+
+func NewAnalyticsService(repo *repository.AnalyticsRepository, pipeline *PipelineService) *AnalyticsService {
+	return &AnalyticsService{
+		repo:     repo,
+		pipeline: pipeline,
+	}
+}
+
+// RevenueBy groups revenue by one of the models.RevenueBy* groupings.
+func (s *AnalyticsService) RevenueBy(grouping string, filter *models.AnalyticsFilter) ([]models.RevenueRow, error) {
+	return s.repo.RevenueBy(grouping, filter)
+}
+
+func (s *AnalyticsService) CountByStatus(filter *models.AnalyticsFilter) ([]models.StatusCount, error) {
+	return s.repo.CountByStatus(filter)
+}
+
+func (s *AnalyticsService) Outstanding(filter *models.AnalyticsFilter) (*models.OutstandingSummary, error) {
+	return s.repo.Outstanding(filter)
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"elible/internal/app/models"
+	"elible/internal/app/repository"
+	"elible/internal/app/utils"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// apiKeyPrefix marks the keys issued by this API so they are easy to spot in configs and logs.
+const apiKeyPrefix = "elk_"
+
+type APIKeyService struct {
+	repo *repository.APIKeyRepository
+}
+
+func NewAPIKeyService(repo *repository.APIKeyRepository) *APIKeyService {
+	return &APIKeyService{
+		repo: repo,
+	}
+}
+
+// Create stores a new key and returns it together with the plain key, which
+// is only available at this point.
+func (s *APIKeyService) Create(name string, scopes []models.Permission, createdBy *models.Admin) (*models.APIKey, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", errors.New("name is required")
+	}
+	if len(scopes) == 0 {
+		return nil, "", errors.New("at least one scope is required")
+	}
+	for _, scope := range scopes {
+		if !models.IsValidAPIKeyScope(scope) {
+			return nil, "", fmt.Errorf("invalid scope: %s", scope)
+		}
+	}
+
+	prefix, err := utils.RandomString(4)
+	if err != nil {
+		return nil, "", err
+	}
+	secret, err := utils.RandomString(32)
+	if err != nil {
+		return nil, "", err
+	}
+	plainKey := apiKeyPrefix + prefix + "_" + secret
+
+	key := &models.APIKey{
+		Name:    name,
+		Prefix:  apiKeyPrefix + prefix,
+		KeyHash: utils.HashToken(plainKey),
+		Scopes:  scopes,
+	}
+	if createdBy != nil {
+		key.CreatedBy = createdBy.ID
+	}
+
+	if err := s.repo.Create(key); err != nil {
+		return nil, "", err
+	}
+
+	return key, plainKey, nil
+}
+
+// Authenticate returns the active key matching the plain key, or nil when there is none.
+func (s *APIKeyService) Authenticate(plainKey string) (*models.APIKey, error) {
+	if !strings.HasPrefix(plainKey, apiKeyPrefix) {
+		return nil, nil
+	}
+	return s.repo.FindActiveByHash(utils.HashToken(plainKey))
+}
+
+func (s *APIKeyService) List() ([]models.APIKey, error) {
+	return s.repo.List()
+}
+
+func (s *APIKeyService) Revoke(id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	revoked, err := s.repo.Revoke(objectID)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return errors.New("api key not found or already revoked")
+	}
+	return nil
+}
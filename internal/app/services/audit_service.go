@@ -0,0 +1,37 @@
+package services
+
+import (
+	"log"
+
+	"elible/internal/app/models"
+	"elible/internal/app/repository"
+	"elible/internal/app/utils"
+)
+
+type AuditService struct {
+	repo *repository.AuditRepository
+}
+
+func NewAuditService(repo *repository.AuditRepository) *AuditService {
+	return &AuditService{
+		repo: repo,
+	}
+}
+
+// Record stores an audit entry with the diff between before and after. The
+// write being audited has already happened, so failures are only logged.
+func (s *AuditService) Record(entry *models.AuditLog, before, after interface{}) {
+	changes, err := utils.Diff(before, after)
+	if err != nil {
+		log.Printf("Error while computing audit diff, Reason: %v\n", err)
+	}
+	entry.Changes = changes
+
+	if err := s.repo.Create(entry); err != nil {
+		log.Printf("Error while recording audit log, Reason: %v\n", err)
+	}
+}
+
+func (s *AuditService) List(filter *models.AuditFilter) (*models.PagedAuditLogs, error) {
+	return s.repo.List(filter)
+}
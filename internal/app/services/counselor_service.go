@@ -0,0 +1,212 @@
+package services
+
+import (
+	"errors"
+	"sort"
+
+	"elible/internal/app/models"
+	"elible/internal/app/repository"
+	"elible/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var ErrNotCounselor = errors.New("admin is not an active counselor")
+
+type CounselorService struct {
+	strategy  string
+	repo      *repository.CounselorRepository
+	adminRepo *repository.AdminRepository
+}
+
+func NewCounselorService(cfg *config.Config, repo *repository.CounselorRepository, adminRepo *repository.AdminRepository) *CounselorService {
+	return &CounselorService{
+		strategy:  cfg.AssignmentStrategy(),
+		repo:      repo,
+		adminRepo: adminRepo,
+	}
+}
+
+// Pick chooses counselors for n new students using the configured strategy.
+// It returns nil when assignment is manual or there are no active counselors,
+// leaving the students unassigned.
+func (s *CounselorService) Pick(n int) ([]primitive.ObjectID, error) {
+	if n <= 0 || s.strategy == config.AssignmentManual {
+		return nil, nil
+	}
+
+	counselors, err := s.adminRepo.ListActiveByRole(models.RoleCounselor)
+	if err != nil {
+		return nil, err
+	}
+	if len(counselors) == 0 {
+		return nil, nil
+	}
+
+	picked := make([]primitive.ObjectID, 0, n)
+	switch s.strategy {
+	case config.AssignmentLeastLoaded:
+		caseloads, err := s.repo.Caseloads()
+		if err != nil {
+			return nil, err
+		}
+		loads := make(map[primitive.ObjectID]int64, len(caseloads))
+		for _, caseload := range caseloads {
+			loads[caseload.CounselorID] = caseload.Active
+		}
+
+		// Ties go to the counselor who joined first
+		for i := 0; i < n; i++ {
+			best := counselors[0].ID
+			for _, counselor := range counselors[1:] {
+				if loads[counselor.ID] < loads[best] {
+					best = counselor.ID
+				}
+			}
+			loads[best]++
+			picked = append(picked, best)
+		}
+	default:
+		start, err := s.repo.NextRoundRobin(n)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < n; i++ {
+			picked = append(picked, counselors[(start+int64(i))%int64(len(counselors))].ID)
+		}
+	}
+
+	return picked, nil
+}
+
+// Validate checks that the id belongs to an enabled admin with the counselor role.
+func (s *CounselorService) Validate(counselorID primitive.ObjectID) error {
+	admin, err := s.adminRepo.FindByID(counselorID)
+	if err != nil {
+		return err
+	}
+	if admin == nil || admin.Disabled || admin.EffectiveRole() != models.RoleCounselor {
+		return ErrNotCounselor
+	}
+	return nil
+}
+
+// Assign moves students to a counselor, for example when a counselor leaves.
+func (s *CounselorService) Assign(studentIDs []string, counselorID string) (*models.AssignResult, error) {
+	counselorObjectID, err := primitive.ObjectIDFromHex(counselorID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Validate(counselorObjectID); err != nil {
+		return nil, err
+	}
+
+	objectIDs := make([]primitive.ObjectID, 0, len(studentIDs))
+	for _, studentID := range studentIDs {
+		objectID, err := primitive.ObjectIDFromHex(studentID)
+		if err != nil {
+			return nil, err
+		}
+		objectIDs = append(objectIDs, objectID)
+	}
+	if len(objectIDs) == 0 {
+		return nil, errors.New("no students to assign")
+	}
+
+	assigned, err := s.repo.Assign(objectIDs, counselorObjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AssignResult{
+		CounselorID: counselorObjectID,
+		Assigned:    assigned,
+	}, nil
+}
+
+// AssignNew gives counselors to students created in bulk, such as by the
+// Excel import, and returns how many were assigned.
+func (s *CounselorService) AssignNew(studentIDs []primitive.ObjectID) (int64, error) {
+	picked, err := s.Pick(len(studentIDs))
+	if err != nil || len(picked) == 0 {
+		return 0, err
+	}
+
+	byCounselor := make(map[primitive.ObjectID][]primitive.ObjectID)
+	for i, studentID := range studentIDs {
+		byCounselor[picked[i]] = append(byCounselor[picked[i]], studentID)
+	}
+
+	var assigned int64
+	for counselorID, ids := range byCounselor {
+		count, err := s.repo.Assign(ids, counselorID)
+		if err != nil {
+			return assigned, err
+		}
+		assigned += count
+	}
+
+	return assigned, nil
+}
+
+// Caseloads lists every active counselor with their number of students, plus
+// admins who still have students but are disabled or no longer counselors,
+// and a row for unassigned students when there are any.
+func (s *CounselorService) Caseloads() ([]models.CounselorCaseload, error) {
+	counts, err := s.repo.Caseloads()
+	if err != nil {
+		return nil, err
+	}
+	counselors, err := s.adminRepo.ListActiveByRole(models.RoleCounselor)
+	if err != nil {
+		return nil, err
+	}
+	admins, err := s.adminRepo.List()
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[primitive.ObjectID]models.CounselorCaseload, len(counts))
+	for _, count := range counts {
+		byID[count.CounselorID] = count
+	}
+	for _, counselor := range counselors {
+		if _, ok := byID[counselor.ID]; !ok {
+			byID[counselor.ID] = models.CounselorCaseload{CounselorID: counselor.ID}
+		}
+	}
+
+	caseloads := make([]models.CounselorCaseload, 0, len(byID))
+	var unassigned *models.CounselorCaseload
+	for id, caseload := range byID {
+		if id.IsZero() {
+			caseload := caseload
+			unassigned = &caseload
+			continue
+		}
+		caseloads = append(caseloads, caseload)
+	}
+	adminsByID := make(map[primitive.ObjectID]models.Admin, len(admins))
+	for _, admin := range admins {
+		adminsByID[admin.ID] = admin
+	}
+	for i := range caseloads {
+		if admin, ok := adminsByID[caseloads[i].CounselorID]; ok {
+			caseloads[i].Username = admin.Username
+			caseloads[i].FullName = admin.FullName
+			caseloads[i].Disabled = admin.Disabled
+		}
+	}
+
+	sort.Slice(caseloads, func(i, j int) bool {
+		if caseloads[i].Active != caseloads[j].Active {
+			return caseloads[i].Active > caseloads[j].Active
+		}
+		return caseloads[i].Username < caseloads[j].Username
+	})
+	if unassigned != nil {
+		caseloads = append(caseloads, *unassigned)
+	}
+
+	return caseloads, nil
+}
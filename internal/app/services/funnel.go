@@ -0,0 +1,290 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"elible/internal/app/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// The funnel reports are computed from the stage history of each student:
+// stage_timestamps for moves made since the pipeline became configurable and
+// track_lobby for older ones. A student that reached a non-terminal stage is
+// counted as having passed every earlier non-terminal stage, which fills the
+// gaps in imported or older histories. Terminal stages such as accepted and
+// rejected are only counted when they were actually reached.
+
+// Funnel reports how many students reached each stage, the conversion between
+// stages and the median time spent in each stage.
+func (s *AnalyticsService) Funnel(filter *models.FunnelFilter) ([]models.FunnelStage, error) {
+	stages, students, err := s.funnelData(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	reached := make([]int, len(stages))
+	durations := make([][]float64, len(stages))
+	for i := range students {
+		entries := stageEntries(&students[i], stages)
+		for j, stage := range stages {
+			if reachedStage(entries, stages, j) {
+				reached[j]++
+			}
+
+			// Time in stage runs until the next stage the student entered
+			entered, ok := entries[stage.Key]
+			if !ok || entered.IsZero() {
+				continue
+			}
+			var next time.Time
+			for _, at := range entries {
+				if at.After(entered) && (next.IsZero() || at.Before(next)) {
+					next = at
+				}
+			}
+			if !next.IsZero() {
+				durations[j] = append(durations[j], next.Sub(entered).Hours())
+			}
+		}
+	}
+
+	funnel := make([]models.FunnelStage, len(stages))
+	previous := -1
+	for j, stage := range stages {
+		funnel[j] = models.FunnelStage{
+			Key:                 stage.Key,
+			Name:                stage.Name,
+			Reached:             reached[j],
+			ConversionFromFirst: rate(reached[j], reached[0]),
+			TimedStudents:       len(durations[j]),
+		}
+		if previous >= 0 {
+			funnel[j].ConversionFromPrevious = rate(reached[j], reached[previous])
+		} else {
+			funnel[j].ConversionFromPrevious = 1
+		}
+		if median, ok := medianOf(durations[j]); ok {
+			funnel[j].MedianHoursInStage = &median
+		}
+
+		// Terminal stages branch off the main path, so later stages still compare against the last regular one
+		if !stage.Terminal {
+			previous = j
+		}
+	}
+
+	return funnel, nil
+}
+
+// DropOff compares the progress of students grouped by school, province or interest.
+func (s *AnalyticsService) DropOff(grouping string, filter *models.FunnelFilter) ([]models.DropOffRow, error) {
+	stages, students, err := s.funnelData(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var provinces map[primitive.ObjectID]string
+	if grouping == models.DropOffByProvince {
+		if provinces, err = s.repo.SchoolProvinces(); err != nil {
+			return nil, err
+		}
+	}
+
+	enrolledIndex := stageIndex(stages, models.StageEnrolledService)
+	rows := make(map[string]*models.DropOffRow)
+	var keys []string
+	for i := range students {
+		student := &students[i]
+
+		var key string
+		switch grouping {
+		case models.DropOffBySchool:
+			key = student.School
+		case models.DropOffByProvince:
+			key = provinces[student.SchoolID]
+		case models.DropOffByInterest:
+			key = student.Interest
+		default:
+			return nil, errors.New("unknown drop-off grouping")
+		}
+
+		row, ok := rows[key]
+		if !ok {
+			row = &models.DropOffRow{Key: key}
+			rows[key] = row
+			keys = append(keys, key)
+		}
+
+		row.Students++
+		if enrolledIndex >= 0 && reachedStage(stageEntries(student, stages), stages, enrolledIndex) {
+			row.Enrolled++
+		}
+		if hasPaidService(student) {
+			row.Paid++
+		}
+		if student.Progress == models.StageRejected {
+			row.Rejected++
+		}
+	}
+
+	result := make([]models.DropOffRow, 0, len(keys))
+	for _, key := range keys {
+		row := rows[key]
+		row.DropOffRate = 1 - rate(row.Enrolled, row.Students)
+		row.PaidRate = rate(row.Paid, row.Students)
+		result = append(result, *row)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Students != result[j].Students {
+			return result[i].Students > result[j].Students
+		}
+		return result[i].Key < result[j].Key
+	})
+
+	return result, nil
+}
+
+// Cohorts follows the students created in each month through the pipeline.
+func (s *AnalyticsService) Cohorts(filter *models.FunnelFilter) ([]models.CohortRow, error) {
+	stages, students, err := s.funnelData(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	location, _ := time.LoadLocation("Asia/Jakarta")
+	cohorts := make(map[string]*models.CohortRow)
+	var months []string
+	for i := range students {
+		student := &students[i]
+		month := student.CreatedAt.In(location).Format("2006-01")
+
+		cohort, ok := cohorts[month]
+		if !ok {
+			cohort = &models.CohortRow{Month: month, Reached: make(map[string]int)}
+			cohorts[month] = cohort
+			months = append(months, month)
+		}
+
+		cohort.Students++
+		entries := stageEntries(student, stages)
+		for j, stage := range stages {
+			if reachedStage(entries, stages, j) {
+				cohort.Reached[stage.Key]++
+			}
+		}
+		if hasPaidService(student) {
+			cohort.Paid++
+		}
+	}
+
+	sort.Strings(months)
+	result := make([]models.CohortRow, 0, len(months))
+	for _, month := range months {
+		cohort := cohorts[month]
+		cohort.PaidRate = rate(cohort.Paid, cohort.Students)
+		result = append(result, *cohort)
+	}
+
+	return result, nil
+}
+
+func (s *AnalyticsService) funnelData(filter *models.FunnelFilter) ([]models.PipelineStage, []models.Student, error) {
+	stages, err := s.pipeline.GetPipeline()
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(stages) == 0 {
+		return nil, nil, errors.New("pipeline has no stages")
+	}
+	sort.SliceStable(stages, func(i, j int) bool { return stages[i].Order < stages[j].Order })
+
+	students, err := s.repo.FunnelStudents(filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	return stages, students, nil
+}
+
+// stageEntries returns when the student first entered each stage it is known to have reached.
+func stageEntries(student *models.Student, stages []models.PipelineStage) map[string]time.Time {
+	entries := make(map[string]time.Time)
+	for stage, at := range student.StageTimestamps {
+		entries[stage] = at
+	}
+	for _, lobby := range student.TrackLobby {
+		if at, ok := entries[lobby.Progress]; !ok || lobby.CreatedAt.Before(at) {
+			entries[lobby.Progress] = lobby.CreatedAt
+		}
+	}
+
+	// Students start in the first stage when they are created
+	if _, ok := entries[stages[0].Key]; !ok && !student.CreatedAt.IsZero() {
+		entries[stages[0].Key] = student.CreatedAt
+	}
+	if _, ok := entries[student.Progress]; !ok && student.Progress != "" {
+		entries[student.Progress] = time.Time{}
+	}
+	return entries
+}
+
+// reachedStage reports whether the student reached stages[index], either
+// directly or, for non-terminal stages, by reaching a later non-terminal stage.
+func reachedStage(entries map[string]time.Time, stages []models.PipelineStage, index int) bool {
+	if _, ok := entries[stages[index].Key]; ok {
+		return true
+	}
+	if stages[index].Terminal {
+		return false
+	}
+	for _, later := range stages[index+1:] {
+		if _, ok := entries[later.Key]; ok && !later.Terminal {
+			return true
+		}
+	}
+	return false
+}
+
+func stageIndex(stages []models.PipelineStage, key string) int {
+	for i, stage := range stages {
+		if stage.Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func hasPaidService(student *models.Student) bool {
+	for _, record := range student.TrackRecords {
+		if record.Amount > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// rate is part/whole rounded to four decimals, or 0 when whole is 0.
+func rate(part, whole int) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return math.Round(float64(part)/float64(whole)*10000) / 10000
+}
+
+func medianOf(values []float64) (float64, bool) {
+	if len(values) == 0 {
+		return 0, false
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	middle := len(sorted) / 2
+	median := sorted[middle]
+	if len(sorted)%2 == 0 {
+		median = (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return math.Round(median*100) / 100, true
+}
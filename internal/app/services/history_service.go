@@ -0,0 +1,218 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+
+	"elible/internal/app/models"
+	"elible/internal/app/repository"
+	"elible/internal/app/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// HistoryConflictError is returned when a field was changed again after the
+// change set being reverted.
+type HistoryConflictError struct {
+	Field string
+}
+
+func (e *HistoryConflictError) Error() string {
+	return fmt.Sprintf("%s was changed again after this change, revert the later change first", e.Field)
+}
+
+// revertableFields are the profile fields that can be written back from the
+// history. Services, lobby progress and deletion have their own endpoints.
+var revertableFields = map[string]bool{
+	"name":              true,
+	"email":             true,
+	"school":            true,
+	"school_id":         true,
+	"interest":          true,
+	"gender":            true,
+	"phone":             true,
+	"financial_ability": true,
+	"detailsiswa_link":  true,
+	"image":             true,
+	"category":          true,
+	"birthdate":         true,
+	"is_active":         true,
+}
+
+type HistoryService struct {
+	repo        *repository.HistoryRepository
+	studentRepo *repository.StudentRepository
+}
+
+func NewHistoryService(repo *repository.HistoryRepository, studentRepo *repository.StudentRepository) *HistoryService {
+	return &HistoryService{
+		repo:        repo,
+		studentRepo: studentRepo,
+	}
+}
+
+// Record appends a change set with the fields that differ between before and
+// after. Writes that changed nothing are not recorded. Like the audit log, the
+// write has already happened, so failures are only logged.
+func (s *HistoryService) Record(studentID string, actor models.Actor, action, route string, before, after *models.Student) {
+	objectID, err := primitive.ObjectIDFromHex(studentID)
+	if err != nil {
+		log.Printf("Error while recording student history, Reason: %v\n", err)
+		return
+	}
+
+	changes, err := utils.Diff(before, after)
+	if err != nil {
+		log.Printf("Error while computing student history diff, Reason: %v\n", err)
+		return
+	}
+	if len(changes) == 0 {
+		return
+	}
+
+	err = s.repo.Create(&models.StudentChangeSet{
+		StudentID: objectID,
+		Action:    action,
+		Route:     route,
+		Actor:     actor,
+		Changes:   changes,
+	})
+	if err != nil {
+		log.Printf("Error while recording student history, Reason: %v\n", err)
+	}
+}
+
+func (s *HistoryService) Timeline(filter *models.HistoryFilter) (*models.PagedStudentHistory, error) {
+	studentID, err := primitive.ObjectIDFromHex(filter.StudentID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListByStudent(studentID, filter)
+}
+
+// Revert writes the old values of a change set back to the student. With
+// fields empty every revertable field of the change set is reverted. A field
+// that was changed again since is refused with a HistoryConflictError, so a
+// revert never silently overwrites a later edit.
+func (s *HistoryService) Revert(changeSetID string, fields []string, actor models.Actor, route string) (*models.StudentChangeSet, error) {
+	objectID, err := primitive.ObjectIDFromHex(changeSetID)
+	if err != nil {
+		return nil, err
+	}
+
+	changeSet, err := s.repo.FindByID(objectID)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := selectRevertChanges(changeSet, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	before, err := s.studentRepo.GetByID(changeSet.StudentID)
+	if err != nil {
+		return nil, err
+	}
+	if before == nil {
+		return nil, errors.New("student not found")
+	}
+
+	current, err := utils.ToDocument(before)
+	if err != nil {
+		return nil, err
+	}
+
+	// The update only applies while every field still holds its value after the
+	// change, so an edit between this check and the write is refused too
+	expected := bson.M{}
+	set := bson.M{}
+	var unset []string
+	var names []string
+	for _, change := range changes {
+		if !reflect.DeepEqual(current[change.Field], change.After) {
+			return nil, &HistoryConflictError{Field: change.Field}
+		}
+		expected[change.Field] = change.After
+		names = append(names, change.Field)
+		if change.Before == nil {
+			unset = append(unset, change.Field)
+		} else {
+			set[change.Field] = change.Before
+		}
+	}
+
+	reverted, err := s.studentRepo.RevertFields(changeSet.StudentID, expected, set, unset)
+	if err != nil {
+		return nil, err
+	}
+	if !reverted {
+		return nil, &HistoryConflictError{Field: strings.Join(names, ", ")}
+	}
+
+	after, err := s.studentRepo.GetByID(changeSet.StudentID)
+	if err != nil {
+		return nil, err
+	}
+
+	diff, err := utils.Diff(before, after)
+	if err != nil {
+		return nil, err
+	}
+
+	revert := &models.StudentChangeSet{
+		StudentID: changeSet.StudentID,
+		Action:    models.HistoryActionRevert,
+		Route:     route,
+		Actor:     actor,
+		Changes:   diff,
+		RevertOf:  changeSet.ID,
+	}
+	if err := s.repo.Create(revert); err != nil {
+		return nil, err
+	}
+
+	return revert, nil
+}
+
+// selectRevertChanges picks the changes of a change set that a revert applies to.
+func selectRevertChanges(changeSet *models.StudentChangeSet, fields []string) ([]models.FieldChange, error) {
+	if len(fields) == 0 {
+		var changes []models.FieldChange
+		for _, change := range changeSet.Changes {
+			if revertableFields[change.Field] {
+				changes = append(changes, change)
+			}
+		}
+		if len(changes) == 0 {
+			return nil, errors.New("change set has no fields that can be reverted")
+		}
+		return changes, nil
+	}
+
+	byField := make(map[string]models.FieldChange, len(changeSet.Changes))
+	for _, change := range changeSet.Changes {
+		byField[change.Field] = change
+	}
+
+	var changes []models.FieldChange
+	var invalid []string
+	for _, field := range fields {
+		change, ok := byField[field]
+		if !ok || !revertableFields[field] {
+			invalid = append(invalid, field)
+			continue
+		}
+		changes = append(changes, change)
+	}
+	if len(invalid) > 0 {
+		return nil, fmt.Errorf("fields cannot be reverted from this change set: %s", strings.Join(invalid, ", "))
+	}
+
+	return changes, nil
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"elible/internal/app/models"
+)
+
+func TestSelectRevertChanges(t *testing.T) {
+	changeSet := &models.StudentChangeSet{
+		Changes: []models.FieldChange{
+			{Field: "name", Before: "Rina", After: "Rina Putri"},
+			{Field: "phone", Before: nil, After: "0812"},
+			{Field: "track_records", Before: nil, After: []interface{}{"IELTS"}},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		changeSet *models.StudentChangeSet
+		fields    []string
+		want      []string
+		wantErr   bool
+	}{
+		{"all revertable fields", changeSet, nil, []string{"name", "phone"}, false},
+		{"selected field", changeSet, []string{"phone"}, []string{"phone"}, false},
+		{"selected fields keep the requested order", changeSet, []string{"phone", "name"}, []string{"phone", "name"}, false},
+		{"field that is not revertable", changeSet, []string{"track_records"}, nil, true},
+		{"field that did not change", changeSet, []string{"email"}, nil, true},
+		{"one invalid field refuses all", changeSet, []string{"name", "email"}, nil, true},
+		{
+			"nothing revertable",
+			&models.StudentChangeSet{Changes: []models.FieldChange{{Field: "track_lobby", After: "lead"}}},
+			nil,
+			nil,
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes, err := selectRevertChanges(tt.changeSet, tt.fields)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("selectRevertChanges() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			var got []string
+			for _, change := range changes {
+				got = append(got, change.Field)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("selectRevertChanges() fields = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"elible/internal/app/models"
+	"elible/internal/app/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// latestInteractions is how many log entries the student detail shows.
+const latestInteractions = 20
+
+type InteractionService struct {
+	repo        *repository.InteractionRepository
+	studentRepo *repository.StudentRepository
+}
+
+func NewInteractionService(repo *repository.InteractionRepository, studentRepo *repository.StudentRepository) *InteractionService {
+	return &InteractionService{
+		repo:        repo,
+		studentRepo: studentRepo,
+	}
+}
+
+func (s *InteractionService) EnsureIndexes() error {
+	return s.repo.EnsureIndexes()
+}
+
+func (s *InteractionService) Create(draft *models.InteractionDraft, author models.Actor) (*models.Interaction, error) {
+	studentID, err := primitive.ObjectIDFromHex(draft.StudentID)
+	if err != nil {
+		return nil, err
+	}
+	student, err := s.studentRepo.GetByID(studentID)
+	if err != nil {
+		return nil, err
+	}
+	if student == nil {
+		return nil, errors.New("student not found")
+	}
+
+	interactionType := strings.ToLower(strings.TrimSpace(draft.Type))
+	if !isInteractionType(interactionType) {
+		return nil, fmt.Errorf("unknown interaction type %q", draft.Type)
+	}
+	body := strings.TrimSpace(draft.Body)
+	if body == "" {
+		return nil, errors.New("interaction body is required")
+	}
+
+	var occurredAt time.Time
+	if draft.OccurredAt != "" {
+		if occurredAt, err = time.Parse(time.RFC3339, draft.OccurredAt); err != nil {
+			return nil, err
+		}
+		if occurredAt.After(time.Now()) {
+			return nil, errors.New("an interaction cannot take place in the future")
+		}
+	}
+
+	interaction := &models.Interaction{
+		StudentID:   studentID,
+		StudentName: student.Name,
+		Type:        interactionType,
+		Body:        body,
+		Attachment:  strings.TrimSpace(draft.Attachment),
+		Author:      author,
+		OccurredAt:  occurredAt,
+	}
+	if err := s.repo.Create(interaction); err != nil {
+		return nil, err
+	}
+
+	return interaction, nil
+}
+
+func (s *InteractionService) GetByID(interactionID string) (*models.Interaction, error) {
+	objectID, err := primitive.ObjectIDFromHex(interactionID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.FindByID(objectID)
+}
+
+func (s *InteractionService) Delete(interactionID string) error {
+	objectID, err := primitive.ObjectIDFromHex(interactionID)
+	if err != nil {
+		return err
+	}
+	return s.repo.Delete(objectID)
+}
+
+func (s *InteractionService) Search(filter *models.InteractionFilter) (*models.PagedInteractions, error) {
+	if filter.Type != nil && *filter.Type != "" && !isInteractionType(*filter.Type) {
+		return nil, fmt.Errorf("unknown interaction type %q", *filter.Type)
+	}
+	return s.repo.Search(filter)
+}
+
+// Latest returns the newest entries of a student's log and the total number of entries.
+func (s *InteractionService) Latest(studentID primitive.ObjectID) ([]models.Interaction, int64, error) {
+	return s.repo.Latest(studentID, latestInteractions)
+}
+
+func isInteractionType(interactionType string) bool {
+	switch interactionType {
+	case models.InteractionCall, models.InteractionWhatsApp, models.InteractionMeeting, models.InteractionEmail, models.InteractionNote:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,326 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"elible/internal/app/models"
+	"elible/internal/app/repository"
+	"elible/internal/app/utils"
+	"elible/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrInvoiceChanged is returned when an invoice was paid or edited between reading and saving it.
+var ErrInvoiceChanged = errors.New("invoice was changed in the meantime, reload it and try again")
+
+type InvoiceService struct {
+	cfg         *config.Config
+	repo        *repository.InvoiceRepository
+	studentRepo *repository.StudentRepository
+}
+
+func NewInvoiceService(cfg *config.Config, repo *repository.InvoiceRepository, studentRepo *repository.StudentRepository) *InvoiceService {
+	return &InvoiceService{
+		cfg:         cfg,
+		repo:        repo,
+		studentRepo: studentRepo,
+	}
+}
+
+// Create bills the student's track records. A service can only be on one
+// invoice at a time unless that invoice is voided.
+func (s *InvoiceService) Create(draft *models.InvoiceDraft, createdBy string) (*models.Invoice, error) {
+	studentID, err := primitive.ObjectIDFromHex(draft.StudentID)
+	if err != nil {
+		return nil, err
+	}
+	student, err := s.studentRepo.GetByID(studentID)
+	if err != nil {
+		return nil, err
+	}
+	if student == nil {
+		return nil, errors.New("student not found")
+	}
+
+	invoiced, err := s.repo.InvoicedServices(studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make(map[string]models.TrackRecord, len(student.TrackRecords))
+	for _, record := range student.TrackRecords {
+		records[record.ServiceName] = record
+	}
+
+	var names []string
+	seen := make(map[string]bool, len(draft.ServiceNames))
+	for _, name := range draft.ServiceNames {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		for _, record := range student.TrackRecords {
+			if !invoiced[record.ServiceName] {
+				names = append(names, record.ServiceName)
+			}
+		}
+		if len(names) == 0 {
+			return nil, errors.New("all services of this student are already invoiced")
+		}
+	}
+
+	var items []models.InvoiceItem
+	var subtotal int64
+	for _, name := range names {
+		record, ok := records[name]
+		if !ok {
+			return nil, fmt.Errorf("service %q not found", name)
+		}
+		if invoiced[name] {
+			return nil, fmt.Errorf("service %q is already invoiced", name)
+		}
+
+		amount, err := serviceAmount(&record)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, models.InvoiceItem{ServiceName: name, ServiceDate: record.ServiceDate, Amount: amount})
+		subtotal += amount
+	}
+
+	if draft.Discount < 0 || draft.Discount > subtotal {
+		return nil, errors.New("discount must be between 0 and the subtotal")
+	}
+
+	location, _ := time.LoadLocation("Asia/Jakarta")
+	now := time.Now().In(location)
+	dueDate := now.Add(s.cfg.InvoiceDueIn())
+	if draft.DueDate != "" {
+		if dueDate, err = parseDueDate(draft.DueDate); err != nil {
+			return nil, err
+		}
+	}
+
+	number, err := s.repo.NextNumber(now.Year())
+	if err != nil {
+		return nil, err
+	}
+
+	invoice := &models.Invoice{
+		Number:      number,
+		StudentID:   studentID,
+		StudentName: student.Name,
+		Items:       items,
+		Subtotal:    subtotal,
+		Discount:    draft.Discount,
+		Total:       subtotal - draft.Discount,
+		DueDate:     dueDate,
+		Notes:       strings.TrimSpace(draft.Notes),
+		CreatedBy:   createdBy,
+	}
+	invoice.Status = models.PaymentStatus(invoice.Total, 0)
+
+	created, err := s.repo.Create(invoice)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, errors.New("one of the services was invoiced in the meantime")
+	}
+	return invoice, nil
+}
+
+func (s *InvoiceService) EnsureIndexes() error {
+	return s.repo.EnsureIndexes()
+}
+
+func (s *InvoiceService) GetByID(invoiceID string) (*models.Invoice, error) {
+	objectId, err := primitive.ObjectIDFromHex(invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.FindByID(objectId)
+}
+
+func (s *InvoiceService) List(filter *models.InvoiceFilter) (*models.PagedInvoices, error) {
+	return s.repo.List(filter)
+}
+
+// RecordPayment adds a full or partial payment. Payments cannot exceed the balance.
+func (s *InvoiceService) RecordPayment(draft *models.PaymentDraft, recordedBy string) (*models.Invoice, error) {
+	invoice, err := s.findOpen(draft.InvoiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if draft.Amount <= 0 {
+		return nil, errors.New("payment amount must be positive")
+	}
+	if draft.Amount > invoice.Balance() {
+		return nil, fmt.Errorf("payment exceeds the balance of %s", utils.FormatRupiah(invoice.Balance()))
+	}
+
+	location, _ := time.LoadLocation("Asia/Jakarta")
+	paidAt := time.Now().In(location)
+	if draft.PaidAt != "" {
+		if paidAt, err = time.ParseInLocation("2006-01-02", draft.PaidAt, location); err != nil {
+			return nil, err
+		}
+	}
+
+	payment := models.Payment{
+		ID:         primitive.NewObjectID(),
+		Amount:     draft.Amount,
+		Method:     strings.TrimSpace(draft.Method),
+		Reference:  strings.TrimSpace(draft.Reference),
+		Note:       strings.TrimSpace(draft.Note),
+		PaidAt:     paidAt,
+		RecordedBy: recordedBy,
+	}
+	saved, err := s.repo.AddPayment(invoice, payment)
+	if err != nil {
+		return nil, err
+	}
+	if !saved {
+		return nil, ErrInvoiceChanged
+	}
+
+	return s.repo.FindByID(invoice.ID)
+}
+
+// UpdateTerms changes the discount, due date or notes of an invoice that is not void.
+func (s *InvoiceService) UpdateTerms(update *models.InvoiceTermsUpdate) (*models.Invoice, error) {
+	invoice, err := s.GetByID(update.ID)
+	if err != nil {
+		return nil, err
+	}
+	if invoice == nil {
+		return nil, errors.New("invoice not found")
+	}
+	if invoice.Status == models.InvoiceStatusVoid {
+		return nil, errors.New("invoice is void")
+	}
+
+	previousTotal := invoice.Total
+	if update.Discount != nil {
+		if *update.Discount < 0 || *update.Discount > invoice.Subtotal {
+			return nil, errors.New("discount must be between 0 and the subtotal")
+		}
+		invoice.Discount = *update.Discount
+		invoice.Total = invoice.Subtotal - invoice.Discount
+		if invoice.Total < invoice.AmountPaid {
+			return nil, errors.New("discount would bring the total below the amount already paid")
+		}
+		invoice.Status = models.PaymentStatus(invoice.Total, invoice.AmountPaid)
+	}
+	if update.DueDate != nil {
+		if invoice.DueDate, err = parseDueDate(*update.DueDate); err != nil {
+			return nil, err
+		}
+	}
+	if update.Notes != nil {
+		invoice.Notes = strings.TrimSpace(*update.Notes)
+	}
+
+	saved, err := s.repo.UpdateTerms(invoice, previousTotal)
+	if err != nil {
+		return nil, err
+	}
+	if !saved {
+		return nil, ErrInvoiceChanged
+	}
+
+	return invoice, nil
+}
+
+// Void cancels an unpaid invoice so its services can be invoiced again.
+func (s *InvoiceService) Void(invoiceID string, reason string) error {
+	invoice, err := s.findOpen(invoiceID)
+	if err != nil {
+		return err
+	}
+	if invoice.AmountPaid > 0 {
+		return errors.New("invoice has payments and cannot be voided")
+	}
+
+	voided, err := s.repo.Void(invoice.ID, strings.TrimSpace(reason))
+	if err != nil {
+		return err
+	}
+	if !voided {
+		return ErrInvoiceChanged
+	}
+	return nil
+}
+
+// Receipt renders the invoice as a PDF together with its payments.
+func (s *InvoiceService) Receipt(invoiceID string) (*models.Invoice, []byte, error) {
+	invoice, err := s.GetByID(invoiceID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if invoice == nil {
+		return nil, nil, errors.New("invoice not found")
+	}
+
+	issuer := s.cfg.InvoiceIssuer
+	if issuer == "" {
+		issuer = "Elible"
+	}
+	pdf, err := utils.RenderReceipt(issuer, invoice)
+	if err != nil {
+		return nil, nil, err
+	}
+	return invoice, pdf, nil
+}
+
+// findOpen loads an invoice that can still take payments.
+func (s *InvoiceService) findOpen(invoiceID string) (*models.Invoice, error) {
+	invoice, err := s.GetByID(invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	if invoice == nil {
+		return nil, errors.New("invoice not found")
+	}
+
+	switch invoice.Status {
+	case models.InvoiceStatusVoid:
+		return nil, errors.New("invoice is void")
+	case models.InvoiceStatusPaid:
+		return nil, errors.New("invoice is already paid")
+	}
+	return invoice, nil
+}
+
+// serviceAmount prefers the numeric amount and falls back to parsing ServiceCost for older records.
+func serviceAmount(record *models.TrackRecord) (int64, error) {
+	if record.Amount > 0 {
+		return record.Amount, nil
+	}
+	if record.ServiceCost == "" {
+		return 0, fmt.Errorf("service %q has no cost", record.ServiceName)
+	}
+
+	amount, err := utils.ParseRupiah(record.ServiceCost)
+	if err != nil {
+		return 0, fmt.Errorf("service %q: %v", record.ServiceName, err)
+	}
+	return amount, nil
+}
+
+// parseDueDate reads a YYYY-MM-DD due date as the end of that day in Jakarta.
+func parseDueDate(value string) (time.Time, error) {
+	location, _ := time.LoadLocation("Asia/Jakarta")
+	day, err := time.ParseInLocation("2006-01-02", value, location)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return day.AddDate(0, 0, 1).Add(-time.Second), nil
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"elible/internal/app/models"
+)
+
+func TestServiceAmount(t *testing.T) {
+	tests := []struct {
+		name    string
+		record  models.TrackRecord
+		want    int64
+		wantErr bool
+	}{
+		{"numeric amount", models.TrackRecord{ServiceName: "IELTS", Amount: 2500000, ServiceCost: "Rp 1"}, 2500000, false},
+		{"cost text of an older record", models.TrackRecord{ServiceName: "IELTS", ServiceCost: "Rp 2.500.000"}, 2500000, false},
+		{"no cost", models.TrackRecord{ServiceName: "IELTS"}, 0, true},
+		{"cost that is not an amount", models.TrackRecord{ServiceName: "Konsultasi", ServiceCost: "gratis"}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := serviceAmount(&tt.record)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("serviceAmount() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("serviceAmount() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDueDate(t *testing.T) {
+	location, _ := time.LoadLocation("Asia/Jakarta")
+
+	due, err := parseDueDate("2024-03-31")
+	if err != nil {
+		t.Fatalf("parseDueDate: %v", err)
+	}
+	if want := time.Date(2024, 3, 31, 23, 59, 59, 0, location); !due.Equal(want) {
+		t.Errorf("parseDueDate() = %v, want %v", due, want)
+	}
+
+	for _, value := range []string{"", "31-03-2024", "2024-02-30"} {
+		if _, err := parseDueDate(value); err == nil {
+			t.Errorf("parseDueDate(%q) returned no error", value)
+		}
+	}
+}
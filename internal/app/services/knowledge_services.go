@@ -1,6 +1,8 @@
 package services
 
 import (
+	"time"
+
 	"elible/internal/app/models"
 	"elible/internal/app/repository"
 
@@ -21,12 +23,28 @@ func (s *KnowledgeBaseService) CreateKnowledgeBase(kb *models.KnowledgeBase) err
 	return s.repo.CreateKnowledgeBase(kb)
 }
 
-func (s *KnowledgeBaseService) DeleteKnowledgeBase(id string) error {
+func (s *KnowledgeBaseService) DeleteKnowledgeBase(id string, deletedBy string) error {
 	oid, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return err
 	}
-	return s.repo.DeleteKnowledgeBase(oid)
+	return s.repo.DeleteKnowledgeBase(oid, deletedBy)
+}
+
+func (s *KnowledgeBaseService) RestoreKnowledgeBase(id string) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+	return s.repo.RestoreKnowledgeBase(oid)
+}
+
+func (s *KnowledgeBaseService) ListDeletedKnowledgeBases() ([]models.KnowledgeBase, error) {
+	return s.repo.ListDeletedKnowledgeBase()
+}
+
+func (s *KnowledgeBaseService) PurgeDeleted(before time.Time) (int64, error) {
+	return s.repo.PurgeDeleted(before)
 }
 
 func (s *KnowledgeBaseService) UpdateKnowledgeBase(id string, kb *models.KnowledgeBase) error {
@@ -38,6 +56,14 @@ func (s *KnowledgeBaseService) UpdateKnowledgeBase(id string, kb *models.Knowled
 	return s.repo.UpdateKnowledgeBase(kb)
 }
 
+func (s *KnowledgeBaseService) GetKnowledgeBase(id string) (*models.KnowledgeBase, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetKnowledgeBase(oid)
+}
+
 func (s *KnowledgeBaseService) ListKnowledgeBases() ([]models.KnowledgeBase, error) {
 	return s.repo.ListKnowledgeBase()
 }
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"elible/internal/app/models"
+)
+
+// Failed logins are tracked per username and per IP address. Each failure past
+// loginDelayAfter doubles the wait before the next attempt, and reaching the
+// lockout threshold blocks the key for loginLockoutDuration.
+const (
+	loginAttemptWindow   = time.Hour
+	loginDelayAfter      = 3
+	loginMaxDelay        = time.Minute
+	usernameLockoutAfter = 5
+	ipLockoutAfter       = 20
+	loginLockoutDuration = 15 * time.Minute
+)
+
+// LoginThrottledError is returned while a username or IP address has to wait before logging in again.
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+	Locked     bool
+}
+
+func (e *LoginThrottledError) Error() string {
+	seconds := int(math.Ceil(e.RetryAfter.Seconds()))
+	if e.Locked {
+		return fmt.Sprintf("account temporarily locked after too many failed logins, retry in %d seconds", seconds)
+	}
+	return fmt.Sprintf("too many failed logins, retry in %d seconds", seconds)
+}
+
+func usernameAttemptKey(username string) string {
+	return "username:" + strings.ToLower(strings.TrimSpace(username))
+}
+
+func ipAttemptKey(ip string) string {
+	return "ip:" + ip
+}
+
+// checkLoginAllowed returns a LoginThrottledError when the username or the IP is locked or still waiting out a delay.
+func (s *AdminService) checkLoginAllowed(username string, session models.SessionInfo) error {
+	now := time.Now()
+	for _, key := range []string{usernameAttemptKey(username), ipAttemptKey(session.IPAddress)} {
+		attempt, err := s.repo.FindLoginAttempt(key)
+		if err != nil {
+			return err
+		}
+		if attempt == nil || now.Sub(attempt.LastFailedAt) > loginAttemptWindow {
+			continue
+		}
+
+		if attempt.LockedUntil.After(now) {
+			return &LoginThrottledError{RetryAfter: attempt.LockedUntil.Sub(now), Locked: true}
+		}
+
+		if wait := loginDelay(attempt.FailedCount) - now.Sub(attempt.LastFailedAt); wait > 0 {
+			return &LoginThrottledError{RetryAfter: wait}
+		}
+	}
+	return nil
+}
+
+func loginDelay(failedCount int) time.Duration {
+	if failedCount < loginDelayAfter {
+		return 0
+	}
+	delay := time.Second << uint(failedCount-loginDelayAfter)
+	if delay > loginMaxDelay || delay <= 0 {
+		return loginMaxDelay
+	}
+	return delay
+}
+
+// recordFailedLogin counts the failure for the username and the IP and writes it to the login history.
+func (s *AdminService) recordFailedLogin(username string, admin *models.Admin, session models.SessionInfo, reason string) {
+	status := models.LoginStatusFailed
+	if s.countFailure(usernameAttemptKey(username), usernameLockoutAfter) {
+		status = models.LoginStatusLocked
+	}
+	if s.countFailure(ipAttemptKey(session.IPAddress), ipLockoutAfter) {
+		status = models.LoginStatusLocked
+	}
+
+	entry := &models.LoginHistory{
+		Username:  username,
+		UserAgent: session.UserAgent,
+		IPAddress: session.IPAddress,
+		Status:    status,
+		Reason:    reason,
+	}
+	if admin != nil {
+		entry.AccessUUID = admin.ID.Hex()
+	}
+	if err := s.repo.RecordLogin(entry); err != nil {
+		log.Printf("Error while recording failed login, Reason: %v\n", err)
+	}
+}
+
+// countFailure increments the counter of a key and reports whether it is now locked.
+func (s *AdminService) countFailure(key string, lockoutAfter int) bool {
+	now := time.Now()
+	attempt, err := s.repo.IncrementLoginAttempt(key, now, now.Add(-loginAttemptWindow), lockoutAfter, now.Add(loginLockoutDuration))
+	if err != nil {
+		log.Printf("Error while saving login attempts, Reason: %v\n", err)
+		return false
+	}
+	return attempt.FailedCount >= lockoutAfter
+}
+
+func (s *AdminService) resetFailedLogins(username string) {
+	if _, err := s.repo.DeleteLoginAttempt(usernameAttemptKey(username)); err != nil {
+		log.Printf("Error while resetting login attempts, Reason: %v\n", err)
+	}
+}
+
+// Unlock clears the failed login counters of a username and, optionally, an IP address.
+func (s *AdminService) Unlock(username, ip string) error {
+	if username != "" {
+		if _, err := s.repo.DeleteLoginAttempt(usernameAttemptKey(username)); err != nil {
+			return err
+		}
+	}
+	if ip != "" {
+		if _, err := s.repo.DeleteLoginAttempt(ipAttemptKey(ip)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *AdminService) ListLockouts() ([]models.LoginAttempt, error) {
+	return s.repo.ListLockedAttempts()
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoginDelay(t *testing.T) {
+	tests := []struct {
+		failedCount int
+		want        time.Duration
+	}{
+		{0, 0},
+		{1, 0},
+		{loginDelayAfter - 1, 0},
+		{loginDelayAfter, time.Second},
+		{loginDelayAfter + 1, 2 * time.Second},
+		{loginDelayAfter + 2, 4 * time.Second},
+		{loginDelayAfter + 5, 32 * time.Second},
+		{loginDelayAfter + 6, loginMaxDelay},
+		{loginDelayAfter + 40, loginMaxDelay},
+		// The shift overflows for very large counts, which must not disable the delay
+		{loginDelayAfter + 64, loginMaxDelay},
+		{loginDelayAfter + 200, loginMaxDelay},
+	}
+
+	for _, tt := range tests {
+		if got := loginDelay(tt.failedCount); got != tt.want {
+			t.Errorf("loginDelay(%d) = %v, want %v", tt.failedCount, got, tt.want)
+		}
+	}
+}
+
+func TestLoginAttemptKeys(t *testing.T) {
+	tests := []struct {
+		got  string
+		want string
+	}{
+		{usernameAttemptKey("Admin"), "username:admin"},
+		{usernameAttemptKey("  admin "), "username:admin"},
+		{ipAttemptKey("10.0.0.1"), "ip:10.0.0.1"},
+		// A username cannot collide with an IP key
+		{usernameAttemptKey("ip:10.0.0.1"), "username:ip:10.0.0.1"},
+	}
+
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("key = %q, want %q", tt.got, tt.want)
+		}
+	}
+}
+
+func TestLoginThrottledError(t *testing.T) {
+	tests := []struct {
+		err  *LoginThrottledError
+		want string
+	}{
+		{&LoginThrottledError{RetryAfter: 1500 * time.Millisecond}, "too many failed logins, retry in 2 seconds"},
+		{&LoginThrottledError{RetryAfter: 15 * time.Minute, Locked: true}, "account temporarily locked after too many failed logins, retry in 900 seconds"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.err.Error(); got != tt.want {
+			t.Errorf("Error() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+// A lockout must end within the counting window, so the TTL index on the last
+// failure never removes a counter that still blocks a login.
+func TestLoginLockoutEndsWithinWindow(t *testing.T) {
+	if loginLockoutDuration > loginAttemptWindow {
+		t.Errorf("loginLockoutDuration %v is longer than loginAttemptWindow %v", loginLockoutDuration, loginAttemptWindow)
+	}
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"elible/internal/app/models"
+	"elible/internal/app/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TransitionError is returned when a student cannot move between two pipeline stages.
+type TransitionError struct {
+	From string
+	To   string
+}
+
+func (e *TransitionError) Error() string {
+	if e.From == "" {
+		return fmt.Sprintf("unknown pipeline stage %q", e.To)
+	}
+	return fmt.Sprintf("cannot move student from %q to %q", e.From, e.To)
+}
+
+type PipelineService struct {
+	repo *repository.PipelineRepository
+}
+
+func NewPipelineService(repo *repository.PipelineRepository) *PipelineService {
+	return &PipelineService{
+		repo: repo,
+	}
+}
+
+// EnsureDefaultStages seeds the default pipeline when none has been configured
+// yet, and adds the default follow-ups to a pipeline configured before them.
+func (s *PipelineService) EnsureDefaultStages() error {
+	count, err := s.repo.CountStages()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		if err := s.repo.ReplaceStages(models.DefaultPipelineStages); err != nil {
+			return err
+		}
+	}
+	return s.repo.BackfillFollowUps(models.DefaultPipelineStages)
+}
+
+func (s *PipelineService) GetPipeline() ([]models.PipelineStage, error) {
+	return s.repo.ListStages()
+}
+
+func (s *PipelineService) UpdatePipeline(stages []models.PipelineStage) error {
+	if len(stages) == 0 {
+		return errors.New("pipeline needs at least one stage")
+	}
+
+	keys := make(map[string]bool)
+	for i := range stages {
+		stages[i].Key = strings.TrimSpace(stages[i].Key)
+		if stages[i].Key == "" {
+			return errors.New("stage key is required")
+		}
+		if keys[stages[i].Key] {
+			return fmt.Errorf("duplicate stage key %q", stages[i].Key)
+		}
+		keys[stages[i].Key] = true
+	}
+
+	for i := range stages {
+		for _, next := range stages[i].Transitions {
+			if !keys[next] {
+				return fmt.Errorf("stage %q has a transition to unknown stage %q", stages[i].Key, next)
+			}
+		}
+		if stages[i].Transitions == nil {
+			stages[i].Transitions = []string{}
+		}
+		if followUp := stages[i].FollowUp; followUp != nil {
+			followUp.Title = strings.TrimSpace(followUp.Title)
+			if followUp.Title == "" || followUp.DueInDays < 0 {
+				return fmt.Errorf("stage %q needs a follow-up title and a due in days of zero or more", stages[i].Key)
+			}
+		}
+		stages[i].ID = primitive.NilObjectID
+	}
+
+	return s.repo.ReplaceStages(stages)
+}
+
+// GetStage returns the stage with the given key, or nil when there is none.
+func (s *PipelineService) GetStage(key string) (*models.PipelineStage, error) {
+	stages, err := s.repo.ListStages()
+	if err != nil {
+		return nil, err
+	}
+	for i := range stages {
+		if stages[i].Key == key {
+			return &stages[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// ValidateTransition checks that a student in stage from may move to stage to.
+// Students whose progress is empty or a value from before the pipeline existed
+// may move to any stage.
+func (s *PipelineService) ValidateTransition(from, to string) error {
+	stages, err := s.repo.ListStages()
+	if err != nil {
+		return err
+	}
+
+	var current, next *models.PipelineStage
+	for i := range stages {
+		if stages[i].Key == from {
+			current = &stages[i]
+		}
+		if stages[i].Key == to {
+			next = &stages[i]
+		}
+	}
+
+	if next == nil {
+		return &TransitionError{To: to}
+	}
+	if current == nil {
+		return nil
+	}
+	if !current.CanMoveTo(to) {
+		return &TransitionError{From: from, To: to}
+	}
+	return nil
+}
@@ -1,6 +1,8 @@
 package services
 
 import (
+	"time"
+
 	"elible/internal/app/models"
 	"elible/internal/app/repository"
 
@@ -29,12 +31,36 @@ func (s *StudyProgramService) UpdateStudyProgram(id string, sp models.StudyProgr
 	return s.repo.UpdateStudyProgram(oid, sp)
 }
 
-func (s *StudyProgramService) DeleteStudyProgram(id string) error {
+func (s *StudyProgramService) DeleteStudyProgram(id string, deletedBy string) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+	return s.repo.DeleteStudyProgram(oid, deletedBy)
+}
+
+func (s *StudyProgramService) RestoreStudyProgram(id string) error {
 	oid, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return err
 	}
-	return s.repo.DeleteStudyProgram(oid)
+	return s.repo.RestoreStudyProgram(oid)
+}
+
+func (s *StudyProgramService) GetDeletedStudyPrograms() ([]models.StudyProgram, error) {
+	return s.repo.GetDeletedStudyPrograms()
+}
+
+func (s *StudyProgramService) PurgeDeleted(before time.Time) (int64, error) {
+	return s.repo.PurgeDeleted(before)
+}
+
+func (s *StudyProgramService) FindStudyProgram(id string) (*models.StudyProgram, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.FindStudyProgram(oid)
 }
 
 func (s *StudyProgramService) GetStudyProgram(id primitive.ObjectID) (models.StudyProgramWithUniversity, error) {
@@ -0,0 +1,283 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"elible/internal/app/models"
+	"elible/internal/app/repository"
+	"elible/internal/app/utils"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// School image kinds accepted by SetImage.
+const (
+	SchoolImageLogo  = "logo"
+	SchoolImagePhoto = "image"
+)
+
+type SchoolService struct {
+	repo        *repository.SchoolRepository
+	studentRepo *repository.StudentRepository
+}
+
+func NewSchoolService(repo *repository.SchoolRepository, studentRepo *repository.StudentRepository) *SchoolService {
+	return &SchoolService{
+		repo:        repo,
+		studentRepo: studentRepo,
+	}
+}
+
+func (s *SchoolService) EnsureIndexes() error {
+	return s.repo.EnsureIndexes()
+}
+
+func (s *SchoolService) Create(school *models.School) error {
+	school.Name = strings.TrimSpace(school.Name)
+	if school.Name == "" {
+		return errors.New("school name is required")
+	}
+	school.ID = primitive.NilObjectID
+	// Provinces and cities are stored in upper case, as the importer does
+	school.Province = strings.ToUpper(strings.TrimSpace(school.Province))
+	school.City = strings.ToUpper(strings.TrimSpace(school.City))
+
+	return s.repo.Create(school)
+}
+
+func (s *SchoolService) GetByID(schoolID string) (*models.School, error) {
+	objectId, err := primitive.ObjectIDFromHex(schoolID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(objectId)
+}
+
+func (s *SchoolService) Update(schoolID string, school *models.School) error {
+	objectId, err := primitive.ObjectIDFromHex(schoolID)
+	if err != nil {
+		return err
+	}
+
+	school.Name = strings.TrimSpace(school.Name)
+	school.Province = strings.ToUpper(strings.TrimSpace(school.Province))
+	school.City = strings.ToUpper(strings.TrimSpace(school.City))
+
+	return s.repo.Update(objectId, school)
+}
+
+func (s *SchoolService) Delete(schoolID string) error {
+	objectId, err := primitive.ObjectIDFromHex(schoolID)
+	if err != nil {
+		return err
+	}
+	return s.repo.Delete(objectId)
+}
+
+func (s *SchoolService) List(filter *models.SchoolFilter) (*models.PagedSchools, error) {
+	return s.repo.List(filter)
+}
+
+// Students lists the students of a school, accepting the usual student filters.
+func (s *SchoolService) Students(schoolID string, filter *models.StudentFilter) (*models.PagedStudents, error) {
+	filter.SchoolID = &schoolID
+	if filter.Page == nil || *filter.Page < 1 {
+		page := 1
+		filter.Page = &page
+	}
+	if filter.PageSize == nil || *filter.PageSize < 1 {
+		pageSize := 20
+		filter.PageSize = &pageSize
+	}
+	return s.studentRepo.GetAll(filter)
+}
+
+// SetImage stores the URL of an uploaded logo or image on the school.
+func (s *SchoolService) SetImage(schoolID string, kind string, url string) error {
+	objectId, err := primitive.ObjectIDFromHex(schoolID)
+	if err != nil {
+		return err
+	}
+
+	switch kind {
+	case SchoolImageLogo:
+		return s.repo.SetImage(objectId, "school_logo", url)
+	case SchoolImagePhoto:
+		return s.repo.SetImage(objectId, "school_image", url)
+	default:
+		return errors.New("image type must be logo or image")
+	}
+}
+
+// DefaultSchoolSimilarity is the similarity of normalized names above which two schools are reported as duplicates.
+const DefaultSchoolSimilarity = 0.9
+
+// Normalize stores the normalized name of every school that does not have an
+// up to date one yet and returns how many schools were updated. Schools whose
+// normalized name is taken are left for FindDuplicates and Merge.
+func (s *SchoolService) Normalize() (int, error) {
+	schools, err := s.repo.AllWithCounts()
+	if err != nil {
+		return 0, err
+	}
+
+	updated := 0
+	for _, school := range schools {
+		normalized := utils.NormalizeSchoolName(school.Name)
+		if normalized == school.NormalizedName {
+			continue
+		}
+		set, err := s.repo.SetNormalizedName(school.ID, normalized)
+		if err != nil {
+			return updated, err
+		}
+		if set {
+			updated++
+		}
+	}
+	return updated, nil
+}
+
+// FindDuplicates groups schools whose normalized names are at least
+// minSimilarity alike. Names with different numbers, such as "SMA NEGERI 1"
+// and "SMA NEGERI 2", are never considered duplicates.
+func (s *SchoolService) FindDuplicates(minSimilarity float64) ([]models.SchoolDuplicateGroup, error) {
+	if minSimilarity <= 0 || minSimilarity > 1 {
+		minSimilarity = DefaultSchoolSimilarity
+	}
+
+	schools, err := s.repo.AllWithCounts()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(schools))
+	for i, school := range schools {
+		names[i] = utils.NormalizeSchoolName(school.Name)
+	}
+
+	// Only schools of the same type with the same numbers can be duplicates, so
+	// names are compared within those blocks instead of all pairs
+	blocks := make(map[string][]int)
+	for i, name := range names {
+		key := duplicateBlock(name)
+		blocks[key] = append(blocks[key], i)
+	}
+
+	// Union-find over every pair of similar schools
+	parent := make([]int, len(schools))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	lowest := make(map[int]float64)
+
+	for _, block := range blocks {
+		for x, i := range block {
+			for _, j := range block[x+1:] {
+				similarity := utils.Similarity(names[i], names[j])
+				if similarity < minSimilarity {
+					continue
+				}
+
+				a, b := find(i), find(j)
+				low := similarity
+				for _, root := range []int{a, b} {
+					if value, ok := lowest[root]; ok && value < low {
+						low = value
+					}
+				}
+				delete(lowest, a)
+				delete(lowest, b)
+				parent[b] = a
+				lowest[a] = low
+			}
+		}
+	}
+
+	members := make(map[int][]int)
+	for i := range schools {
+		root := find(i)
+		members[root] = append(members[root], i)
+	}
+
+	var groups []models.SchoolDuplicateGroup
+	for root, indexes := range members {
+		if len(indexes) < 2 {
+			continue
+		}
+
+		group := models.SchoolDuplicateGroup{Normalized: names[root], Similarity: math.Round(lowest[root]*10000) / 10000}
+		winner := schools[indexes[0]]
+		for _, i := range indexes {
+			school := schools[i]
+			group.Schools = append(group.Schools, school)
+			if school.StudentCount > winner.StudentCount ||
+				(school.StudentCount == winner.StudentCount && school.CreatedAt.Before(winner.CreatedAt)) {
+				winner = school
+			}
+		}
+		group.SuggestedWinnerID = winner.ID
+		groups = append(groups, group)
+	}
+
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Normalized < groups[j].Normalized })
+	return groups, nil
+}
+
+// Merge folds the loser schools into the winner.
+func (s *SchoolService) Merge(winnerID string, loserIDs []string) (*models.SchoolMergeResult, error) {
+	winner, err := primitive.ObjectIDFromHex(winnerID)
+	if err != nil {
+		return nil, err
+	}
+	if len(loserIDs) == 0 {
+		return nil, errors.New("at least one school to merge is required")
+	}
+
+	losers := make([]primitive.ObjectID, 0, len(loserIDs))
+	seen := make(map[primitive.ObjectID]bool)
+	for _, loserID := range loserIDs {
+		loser, err := primitive.ObjectIDFromHex(loserID)
+		if err != nil {
+			return nil, err
+		}
+		if loser == winner {
+			return nil, errors.New("a school cannot be merged into itself")
+		}
+		if !seen[loser] {
+			seen[loser] = true
+			losers = append(losers, loser)
+		}
+	}
+
+	return s.repo.Merge(winner, losers)
+}
+
+// duplicateBlock is the part of a normalized name that duplicates must share:
+// the school type, e.g. SMA, and the numbers in the name, so "SMA NEGERI 1"
+// and "SMA NEGERI 2" are never considered duplicates.
+func duplicateBlock(name string) string {
+	words := strings.Fields(name)
+	if len(words) == 0 {
+		return ""
+	}
+
+	key := []string{words[0]}
+	for _, word := range words[1:] {
+		if strings.IndexFunc(word, unicode.IsDigit) >= 0 {
+			key = append(key, word)
+		}
+	}
+	return strings.Join(key, " ")
+}
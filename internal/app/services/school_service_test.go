@@ -0,0 +1,47 @@
+package services
+
+import (
+	"testing"
+
+	"elible/internal/app/utils"
+)
+
+func TestDuplicateBlock(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"SMA NEGERI 1 BANDUNG", "SMA 1"},
+		{"SMA NEGERI 12 BANDUNG", "SMA 12"},
+		{"SMK TUNAS HARAPAN", "SMK"},
+		{"SMP NEGERI 2A JAKARTA", "SMP 2A"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := duplicateBlock(tt.name); got != tt.want {
+			t.Errorf("duplicateBlock(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// Spelling variants of one school land in the same block, different numbers do not.
+func TestDuplicateBlockOfNormalizedNames(t *testing.T) {
+	tests := []struct {
+		a, b      string
+		sameBlock bool
+	}{
+		{"SMAN 1 Bandung", "SMA Negeri 1 Bandng", true},
+		{"SMA N 1 Bandung", "sma negeri 1 bandung", true},
+		{"SMAN 1 Bandung", "SMAN 2 Bandung", false},
+		{"SMAN 1 Bandung", "SMKN 1 Bandung", false},
+	}
+
+	for _, tt := range tests {
+		a := duplicateBlock(utils.NormalizeSchoolName(tt.a))
+		b := duplicateBlock(utils.NormalizeSchoolName(tt.b))
+		if (a == b) != tt.sameBlock {
+			t.Errorf("blocks of %q (%q) and %q (%q) equal = %v, want %v", tt.a, a, tt.b, b, a == b, tt.sameBlock)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strings"
+
+	"elible/internal/app/models"
+	"elible/internal/app/utils"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// DefaultNameSimilarity is how alike two normalized names must be to count as the same name.
+	DefaultNameSimilarity = 0.85
+	// DefaultDuplicateLimit caps the number of candidate pairs returned at once.
+	DefaultDuplicateLimit = 100
+	// maxSharedContact skips phone numbers and emails shared by more students
+	// than this, which are placeholders such as "0000" rather than real duplicates.
+	maxSharedContact = 20
+)
+
+// Reasons reported on a models.DuplicateCandidate.
+const (
+	DuplicateReasonPhone     = "phone"
+	DuplicateReasonEmail     = "email"
+	DuplicateReasonName      = "name"
+	DuplicateReasonBirthdate = "birthdate"
+	DuplicateReasonSchool    = "school"
+)
+
+// FindDuplicates lists pairs of students that are probably the same person:
+// they share a phone number or email, or they have a similar name and share
+// their birthdate or school. Pairs matching on more fields come first.
+func (s *StudentService) FindDuplicates(minNameSimilarity float64, limit int) ([]models.DuplicateCandidate, error) {
+	if minNameSimilarity <= 0 || minNameSimilarity > 1 {
+		minNameSimilarity = DefaultNameSimilarity
+	}
+	if limit <= 0 {
+		limit = DefaultDuplicateLimit
+	}
+
+	students, err := s.repo.ListForDuplicateScan()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(students))
+	byPhone := make(map[string][]int)
+	byEmail := make(map[string][]int)
+	byBirthdate := make(map[string][]int)
+	bySchool := make(map[primitive.ObjectID][]int)
+	for i, student := range students {
+		names[i] = utils.NormalizePersonName(student.Name)
+		if phone := utils.NormalizePhone(student.Phone); len(phone) >= 8 {
+			byPhone[phone] = append(byPhone[phone], i)
+		}
+		if email := strings.ToLower(strings.TrimSpace(student.Email)); email != "" {
+			byEmail[email] = append(byEmail[email], i)
+		}
+		if birthdate := strings.TrimSpace(student.Birthdate); birthdate != "" {
+			byBirthdate[birthdate] = append(byBirthdate[birthdate], i)
+		}
+		if !student.SchoolID.IsZero() {
+			bySchool[student.SchoolID] = append(bySchool[student.SchoolID], i)
+		}
+	}
+
+	// Only students sharing a contact, birthdate or school are compared, not every pair
+	pairs := make(map[[2]int]bool)
+	collect := func(bucket []int, limited bool) {
+		if limited && len(bucket) > maxSharedContact {
+			return
+		}
+		for a := 0; a < len(bucket); a++ {
+			for b := a + 1; b < len(bucket); b++ {
+				pairs[[2]int{bucket[a], bucket[b]}] = true
+			}
+		}
+	}
+	for _, bucket := range byPhone {
+		collect(bucket, true)
+	}
+	for _, bucket := range byEmail {
+		collect(bucket, true)
+	}
+	for _, bucket := range byBirthdate {
+		collect(bucket, false)
+	}
+	for _, bucket := range bySchool {
+		collect(bucket, false)
+	}
+
+	var candidates []models.DuplicateCandidate
+	for pair := range pairs {
+		a, b := &students[pair[0]], &students[pair[1]]
+		similarity := utils.Similarity(names[pair[0]], names[pair[1]])
+
+		var reasons []string
+		if phone := utils.NormalizePhone(a.Phone); len(phone) >= 8 && phone == utils.NormalizePhone(b.Phone) && len(byPhone[phone]) <= maxSharedContact {
+			reasons = append(reasons, DuplicateReasonPhone)
+		}
+		if email := strings.ToLower(strings.TrimSpace(a.Email)); email != "" && email == strings.ToLower(strings.TrimSpace(b.Email)) && len(byEmail[email]) <= maxSharedContact {
+			reasons = append(reasons, DuplicateReasonEmail)
+		}
+		contact := len(reasons) > 0
+
+		nameMatches := similarity >= minNameSimilarity
+		if nameMatches {
+			reasons = append(reasons, DuplicateReasonName)
+		}
+		sameBirthdate := strings.TrimSpace(a.Birthdate) != "" && strings.TrimSpace(a.Birthdate) == strings.TrimSpace(b.Birthdate)
+		if sameBirthdate {
+			reasons = append(reasons, DuplicateReasonBirthdate)
+		}
+		sameSchool := !a.SchoolID.IsZero() && a.SchoolID == b.SchoolID
+		if sameSchool {
+			reasons = append(reasons, DuplicateReasonSchool)
+		}
+
+		if !contact && !(nameMatches && (sameBirthdate || sameSchool)) {
+			continue
+		}
+
+		first, second := *a, *b
+		if second.CreatedAt.Before(first.CreatedAt) {
+			first, second = second, first
+		}
+		candidates = append(candidates, models.DuplicateCandidate{
+			Students:       [2]models.Student{first, second},
+			Reasons:        reasons,
+			NameSimilarity: math.Round(similarity*10000) / 10000,
+		})
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if len(candidates[i].Reasons) != len(candidates[j].Reasons) {
+			return len(candidates[i].Reasons) > len(candidates[j].Reasons)
+		}
+		if candidates[i].NameSimilarity != candidates[j].NameSimilarity {
+			return candidates[i].NameSimilarity > candidates[j].NameSimilarity
+		}
+		return candidates[i].Students[0].ID.Hex() < candidates[j].Students[0].ID.Hex()
+	})
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+
+	return candidates, nil
+}
+
+// Compare returns two students and the fields in which they differ.
+func (s *StudentService) Compare(firstID, secondID string) (*models.StudentComparison, error) {
+	var comparison models.StudentComparison
+	for i, studentID := range []string{firstID, secondID} {
+		student, err := s.GetByID(studentID)
+		if err != nil {
+			return nil, err
+		}
+		if student == nil {
+			return nil, errors.New("student not found")
+		}
+		comparison.Students[i] = *student
+	}
+
+	differences, err := utils.Diff(&comparison.Students[0], &comparison.Students[1])
+	if err != nil {
+		return nil, err
+	}
+	comparison.Differences = differences
+
+	return &comparison, nil
+}
+
+// Merge folds the loser students into the winner, see StudentRepository.Merge.
+func (s *StudentService) Merge(winnerID string, loserIDs []string, mergedBy string) (*models.StudentMergeResult, error) {
+	winner, err := primitive.ObjectIDFromHex(winnerID)
+	if err != nil {
+		return nil, err
+	}
+	if len(loserIDs) == 0 {
+		return nil, errors.New("at least one student to merge is required")
+	}
+
+	losers := make([]primitive.ObjectID, 0, len(loserIDs))
+	seen := make(map[primitive.ObjectID]bool)
+	for _, loserID := range loserIDs {
+		loser, err := primitive.ObjectIDFromHex(loserID)
+		if err != nil {
+			return nil, err
+		}
+		if loser == winner {
+			return nil, errors.New("a student cannot be merged into itself")
+		}
+		if !seen[loser] {
+			seen[loser] = true
+			losers = append(losers, loser)
+		}
+	}
+
+	return s.repo.Merge(winner, losers, mergedBy)
+}
@@ -2,20 +2,31 @@ package services
 
 import (
 	"errors"
+	"log"
+	"time"
 
 	"elible/internal/app/models"
 	"elible/internal/app/repository"
+	"elible/internal/app/utils"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type StudentService struct {
-	repo *repository.StudentRepository
+	repo         *repository.StudentRepository
+	pipeline     *PipelineService
+	counselors   *CounselorService
+	tasks        *TaskService
+	interactions *InteractionService
 }
 
-func NewStudentService(repo *repository.StudentRepository) *StudentService {
+func NewStudentService(repo *repository.StudentRepository, pipeline *PipelineService, counselors *CounselorService, tasks *TaskService, interactions *InteractionService) *StudentService {
 	return &StudentService{
-		repo: repo,
+		repo:         repo,
+		pipeline:     pipeline,
+		counselors:   counselors,
+		tasks:        tasks,
+		interactions: interactions,
 	}
 }
 
@@ -29,9 +40,38 @@ func (s *StudentService) Create(student *models.Student) error {
 		return errors.New("student already exists")
 	}
 
+	// New students start at the first stage unless a valid stage is given
+	if student.Progress == "" {
+		student.Progress = models.StageLead
+	}
+	if err := s.pipeline.ValidateTransition("", student.Progress); err != nil {
+		return err
+	}
+	student.TrackLobby = nil
+	student.StageTimestamps = map[string]time.Time{student.Progress: time.Now()}
+	// Services are only added through AddService, merges only through Merge
+	student.TrackRecords = nil
+	student.MergedInto = primitive.NilObjectID
+
+	// A counselor given on creation must be a real one, otherwise one is picked
+	if !student.CounselorID.IsZero() {
+		if err := s.counselors.Validate(student.CounselorID); err != nil {
+			return err
+		}
+	} else {
+		picked, err := s.counselors.Pick(1)
+		if err != nil {
+			return err
+		}
+		if len(picked) > 0 {
+			student.CounselorID = picked[0]
+		}
+	}
+
 	if err := s.repo.Create(student); err != nil {
 		return err
 	}
+	s.tasks.FollowUp(student, student.Progress)
 
 	return nil
 }
@@ -48,12 +88,48 @@ func (s *StudentService) GetByID(studentID string) (*models.Student, error) {
 	return s.repo.GetByID(objectId)
 }
 
-func (s *StudentService) Delete(studentID string) error {
+// GetDetail returns a student with the latest entries of their interaction
+// log, or nil when the student does not exist.
+func (s *StudentService) GetDetail(studentID string) (*models.StudentDetail, error) {
+	student, err := s.GetByID(studentID)
+	if err != nil || student == nil {
+		return nil, err
+	}
+
+	interactions, count, err := s.interactions.Latest(student.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.StudentDetail{
+		Student:          student,
+		Interactions:     interactions,
+		InteractionCount: count,
+	}, nil
+}
+
+func (s *StudentService) Delete(studentID string, deletedBy string) error {
+	objectId, err := primitive.ObjectIDFromHex(studentID)
+	if err != nil {
+		return err
+	}
+	return s.repo.Delete(objectId, deletedBy)
+}
+
+func (s *StudentService) Restore(studentID string) error {
 	objectId, err := primitive.ObjectIDFromHex(studentID)
 	if err != nil {
 		return err
 	}
-	return s.repo.Delete(objectId)
+	return s.repo.Restore(objectId)
+}
+
+func (s *StudentService) ListDeleted() ([]models.Student, error) {
+	return s.repo.ListDeleted()
+}
+
+func (s *StudentService) PurgeDeleted(before time.Time) (int64, error) {
+	return s.repo.PurgeDeleted(before)
 }
 
 func (s *StudentService) Deactivate(studentID string) error {
@@ -69,6 +145,15 @@ func (s *StudentService) Update(studentID string, student *models.Student) error
 	if err != nil {
 		return err
 	}
+
+	// The pipeline stage can only change through AddLobby
+	student.Progress = ""
+	student.TrackLobby = nil
+	student.StageTimestamps = nil
+	// Services have their own endpoints, merges only happen through Merge
+	student.TrackRecords = nil
+	student.MergedInto = primitive.NilObjectID
+
 	return s.repo.Update(objectId, student)
 }
 
@@ -77,21 +162,116 @@ func (s *StudentService) AddService(studentID string, service *models.TrackRecor
 	if err != nil {
 		return err
 	}
+	fillServiceAmount(service)
 	return s.repo.AddService(objectId, service)
 }
 
+func (s *StudentService) UpdateService(studentID string, oldServiceName string, service *models.TrackRecord) error {
+	objectId, err := primitive.ObjectIDFromHex(studentID)
+	if err != nil {
+		return err
+	}
+	fillServiceAmount(service)
+	return s.repo.UpdateService(objectId, oldServiceName, service)
+}
+
+func (s *StudentService) DeleteService(studentID string, serviceName string) error {
+	objectId, err := primitive.ObjectIDFromHex(studentID)
+	if err != nil {
+		return err
+	}
+	return s.repo.DeleteService(objectId, serviceName)
+}
+
+func (s *StudentService) FilterServices(filter models.ServiceFilter) ([]models.Student, error) {
+	return s.repo.FilterServices(filter)
+}
+
+// fillServiceAmount derives the numeric amount from ServiceCost when it was not
+// given. Costs that are not an amount, such as "gratis", are kept as text only.
+func fillServiceAmount(service *models.TrackRecord) {
+	if service.Amount != 0 || service.ServiceCost == "" {
+		return
+	}
+	if amount, err := utils.ParseRupiah(service.ServiceCost); err == nil {
+		service.Amount = amount
+	}
+}
+
+// ErrStageChanged is returned when a student moved to another pipeline stage between reading and saving it.
+var ErrStageChanged = errors.New("student stage was changed in the meantime, reload it and try again")
+
+// AddLobby moves the student to the pipeline stage in lobby.Progress, which must be an allowed transition from the current stage.
 func (s *StudentService) AddLobby(studentID string, lobby *models.Student) error {
 	objectId, err := primitive.ObjectIDFromHex(studentID)
 	if err != nil {
 		return err
 	}
-	return s.repo.AddLobby(objectId, lobby)
+
+	student, err := s.repo.GetByID(objectId)
+	if err != nil {
+		return err
+	}
+	if student == nil {
+		return errors.New("student not found")
+	}
+
+	if err := s.pipeline.ValidateTransition(student.Progress, lobby.Progress); err != nil {
+		return err
+	}
+
+	moved, err := s.repo.AddLobby(objectId, student.Progress, lobby)
+	if err != nil {
+		return err
+	}
+	if !moved {
+		return ErrStageChanged
+	}
+	if student.Progress != lobby.Progress {
+		s.tasks.FollowUp(student, lobby.Progress)
+	}
+
+	return nil
 }
 
-func (s *StudentService) ActivateStudnetAll() error {
+// ActivateStudnetAll activates every inactive student and returns them as they were before.
+func (s *StudentService) ActivateStudnetAll() ([]models.Student, error) {
 	return s.repo.ActivateAll()
 }
 
 func (s *StudentService) ImportDataFromExcelStudent(filePath string) (*models.ImportResultStudent, error) {
-	return s.repo.ImportDataFromExcelStudent(filePath)
+	result, err := s.repo.ImportDataFromExcelStudent(filePath, s.pipeline.ValidateTransition)
+	if err != nil {
+		return nil, err
+	}
+
+	// The rows are already imported, so a failed assignment only leaves the new students unassigned
+	assigned, err := s.counselors.AssignNew(result.CreatedIDs)
+	if err != nil {
+		log.Printf("Error while assigning counselors to imported students, Reason: %v\n", err)
+	}
+	result.CounselorsAssigned = assigned
+
+	// Loaded again so the follow-ups go to the counselors assigned above
+	for _, id := range result.CreatedIDs {
+		student, err := s.repo.GetByID(id)
+		if err != nil {
+			log.Printf("Error while loading imported student for follow-up, Reason: %v\n", err)
+			continue
+		}
+		if student != nil {
+			s.tasks.FollowUp(student, student.Progress)
+		}
+	}
+
+	return result, nil
+}
+
+// AssignCounselor hands the students to another counselor.
+func (s *StudentService) AssignCounselor(studentIDs []string, counselorID string) (*models.AssignResult, error) {
+	return s.counselors.Assign(studentIDs, counselorID)
+}
+
+func (s *StudentService) Caseloads() ([]models.CounselorCaseload, error) {
+	return s.counselors.Caseloads()
 }
@@ -0,0 +1,321 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"elible/internal/app/models"
+	"elible/internal/app/repository"
+	"elible/internal/config"
+	"elible/internal/mailer"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type TaskService struct {
+	digestHour  int
+	repo        *repository.TaskRepository
+	studentRepo *repository.StudentRepository
+	adminRepo   *repository.AdminRepository
+	pipeline    *PipelineService
+	mail        mailer.Mailer
+}
+
+func NewTaskService(cfg *config.Config, repo *repository.TaskRepository, studentRepo *repository.StudentRepository, adminRepo *repository.AdminRepository, pipeline *PipelineService, mail mailer.Mailer) *TaskService {
+	return &TaskService{
+		digestHour:  cfg.DigestHour(),
+		repo:        repo,
+		studentRepo: studentRepo,
+		adminRepo:   adminRepo,
+		pipeline:    pipeline,
+		mail:        mail,
+	}
+}
+
+// Create adds a task to a student. Without an assignee the task goes to the
+// student's counselor, or to the admin creating it when there is none.
+func (s *TaskService) Create(draft *models.TaskDraft, actor models.Actor) (*models.Task, error) {
+	studentID, err := primitive.ObjectIDFromHex(draft.StudentID)
+	if err != nil {
+		return nil, err
+	}
+	student, err := s.studentRepo.GetByID(studentID)
+	if err != nil {
+		return nil, err
+	}
+	if student == nil {
+		return nil, errors.New("student not found")
+	}
+
+	title := strings.TrimSpace(draft.Title)
+	if title == "" {
+		return nil, errors.New("task title is required")
+	}
+	dueAt, err := parseTaskDue(draft.DueDate)
+	if err != nil {
+		return nil, err
+	}
+
+	assigneeID := student.CounselorID
+	if draft.AssigneeID != "" {
+		if assigneeID, err = s.validateAssignee(draft.AssigneeID); err != nil {
+			return nil, err
+		}
+	} else if assigneeID.IsZero() && actor.Type == models.ActorTypeAdmin {
+		assigneeID, _ = primitive.ObjectIDFromHex(actor.ID)
+	}
+
+	task := &models.Task{
+		StudentID:   studentID,
+		StudentName: student.Name,
+		AssigneeID:  assigneeID,
+		Title:       title,
+		Notes:       draft.Notes,
+		DueAt:       dueAt,
+		Status:      models.TaskStatusOpen,
+		Source:      models.TaskSourceManual,
+		CreatedBy:   actor,
+	}
+	if err := s.repo.Create(task); err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+func (s *TaskService) GetByID(taskID string) (*models.Task, error) {
+	objectID, err := primitive.ObjectIDFromHex(taskID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.FindByID(objectID)
+}
+
+func (s *TaskService) List(filter *models.TaskFilter) (*models.PagedTasks, error) {
+	if filter.Status != nil && *filter.Status != "" && !isTaskStatus(*filter.Status) {
+		return nil, fmt.Errorf("unknown task status %q", *filter.Status)
+	}
+	return s.repo.List(filter)
+}
+
+// Update changes the given fields of a task. An empty assignee unassigns it.
+func (s *TaskService) Update(update *models.TaskUpdate) error {
+	taskID, err := primitive.ObjectIDFromHex(update.ID)
+	if err != nil {
+		return err
+	}
+
+	set := bson.M{}
+	var unset []string
+	if update.Title != nil {
+		title := strings.TrimSpace(*update.Title)
+		if title == "" {
+			return errors.New("task title is required")
+		}
+		set["title"] = title
+	}
+	if update.Notes != nil {
+		set["notes"] = *update.Notes
+	}
+	if update.DueDate != nil {
+		dueAt, err := parseTaskDue(*update.DueDate)
+		if err != nil {
+			return err
+		}
+		set["due_at"] = dueAt
+	}
+	if update.AssigneeID != nil {
+		if *update.AssigneeID == "" {
+			unset = append(unset, "assignee_id")
+		} else {
+			assigneeID, err := s.validateAssignee(*update.AssigneeID)
+			if err != nil {
+				return err
+			}
+			set["assignee_id"] = assigneeID
+		}
+	}
+	if update.Status != nil {
+		if !isTaskStatus(*update.Status) {
+			return fmt.Errorf("unknown task status %q", *update.Status)
+		}
+		set["status"] = *update.Status
+		if *update.Status == models.TaskStatusDone {
+			location, _ := time.LoadLocation("Asia/Jakarta")
+			set["completed_at"] = time.Now().In(location)
+		} else {
+			unset = append(unset, "completed_at")
+		}
+	}
+
+	if len(set) == 0 && len(unset) == 0 {
+		return errors.New("nothing to update")
+	}
+
+	return s.repo.Update(taskID, set, unset)
+}
+
+func (s *TaskService) Complete(taskID string) error {
+	done := models.TaskStatusDone
+	return s.Update(&models.TaskUpdate{ID: taskID, Status: &done})
+}
+
+// FollowUp creates the follow-up configured for the stage the student just
+// entered and cancels the open follow-ups of earlier stages. The stage change
+// has already been saved, so failures are only logged.
+func (s *TaskService) FollowUp(student *models.Student, stageKey string) {
+	if err := s.repo.CancelOpenStageTasks(student.ID); err != nil {
+		log.Printf("Error while cancelling old follow-ups, Reason: %v\n", err)
+	}
+
+	stage, err := s.pipeline.GetStage(stageKey)
+	if err != nil {
+		log.Printf("Error while loading pipeline stage for follow-up, Reason: %v\n", err)
+		return
+	}
+	if stage == nil || stage.FollowUp == nil {
+		return
+	}
+
+	location, _ := time.LoadLocation("Asia/Jakarta")
+	now := time.Now().In(location)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+
+	err = s.repo.Create(&models.Task{
+		StudentID:   student.ID,
+		StudentName: student.Name,
+		AssigneeID:  student.CounselorID,
+		Title:       stage.FollowUp.Title,
+		DueAt:       today.AddDate(0, 0, stage.FollowUp.DueInDays+1).Add(-time.Second),
+		Status:      models.TaskStatusOpen,
+		Source:      models.TaskSourceStage,
+		Stage:       stage.Key,
+		CreatedBy:   models.Actor{Type: models.ActorTypeSystem, Name: "pipeline"},
+	})
+	if err != nil {
+		log.Printf("Error while creating follow-up task, Reason: %v\n", err)
+	}
+}
+
+// SendDigests mails every assignee their overdue tasks and the ones due today.
+// The scheduler calls it every hour; it sends once a day, from DigestHour on.
+func (s *TaskService) SendDigests() error {
+	location, _ := time.LoadLocation("Asia/Jakarta")
+	now := time.Now().In(location)
+	if now.Hour() < s.digestHour {
+		return nil
+	}
+
+	day := now.Format("2006-01-02")
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+	tasks, err := s.repo.OpenAssignedBefore(today.AddDate(0, 0, 1))
+	if err != nil {
+		return err
+	}
+
+	byAssignee := make(map[primitive.ObjectID]*mailer.TaskDigestData)
+	var order []primitive.ObjectID
+	for _, task := range tasks {
+		digest, ok := byAssignee[task.AssigneeID]
+		if !ok {
+			digest = &mailer.TaskDigestData{Date: day}
+			byAssignee[task.AssigneeID] = digest
+			order = append(order, task.AssigneeID)
+		}
+
+		item := mailer.TaskDigestItem{
+			Title:       task.Title,
+			StudentName: task.StudentName,
+			Due:         task.DueAt.In(location).Format("2006-01-02"),
+		}
+		if task.DueAt.Before(today) {
+			digest.Overdue = append(digest.Overdue, item)
+		} else {
+			digest.DueToday = append(digest.DueToday, item)
+		}
+	}
+
+	// Every assignee is claimed separately, so a failed mail is retried on the
+	// next run without sending the others twice
+	var failed int
+	for _, assigneeID := range order {
+		admin, err := s.adminRepo.FindByID(assigneeID)
+		if err != nil {
+			log.Printf("Error while loading task digest recipient %s, Reason: %v\n", assigneeID.Hex(), err)
+			failed++
+			continue
+		}
+		if admin == nil || admin.Disabled || admin.Email == "" {
+			continue
+		}
+
+		claimed, err := s.repo.ClaimDigest(day, assigneeID)
+		if err != nil {
+			log.Printf("Error while claiming task digest for %s, Reason: %v\n", admin.Username, err)
+			failed++
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		digest := byAssignee[assigneeID]
+		digest.Name = admin.FullName
+		if digest.Name == "" {
+			digest.Name = admin.Username
+		}
+
+		body, err := mailer.Render(mailer.TaskDigestTemplate, digest)
+		if err == nil {
+			err = s.mail.Send(admin.Email, mailer.TaskDigestSubject, body)
+		}
+		if err != nil {
+			log.Printf("Error while sending task digest to %s, Reason: %v\n", admin.Username, err)
+			failed++
+			if err := s.repo.ReleaseDigest(day, assigneeID); err != nil {
+				log.Printf("Error while releasing task digest for %s, Reason: %v\n", admin.Username, err)
+			}
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d task digests could not be sent", failed, len(order))
+	}
+	return nil
+}
+
+// validateAssignee checks that a task is given to an enabled admin.
+func (s *TaskService) validateAssignee(adminID string) (primitive.ObjectID, error) {
+	objectID, err := primitive.ObjectIDFromHex(adminID)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	admin, err := s.adminRepo.FindByID(objectID)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	if admin == nil || admin.Disabled {
+		return primitive.NilObjectID, errors.New("assignee is not an active admin")
+	}
+	return objectID, nil
+}
+
+func isTaskStatus(status string) bool {
+	switch status {
+	case models.TaskStatusOpen, models.TaskStatusDone, models.TaskStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// parseTaskDue reads an RFC 3339 time, or a YYYY-MM-DD date as the end of that day.
+func parseTaskDue(value string) (time.Time, error) {
+	if due, err := time.Parse(time.RFC3339, value); err == nil {
+		return due, nil
+	}
+	return parseDueDate(value)
+}
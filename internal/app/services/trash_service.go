@@ -0,0 +1,51 @@
+package services
+
+import (
+	"log"
+	"time"
+)
+
+// TrashService purges soft deleted documents once they are older than the retention period.
+type TrashService struct {
+	students       *StudentService
+	universities   *UniversityService
+	studyPrograms  *StudyProgramService
+	knowledgeBases *KnowledgeBaseService
+	retention      time.Duration
+}
+
+func NewTrashService(students *StudentService, universities *UniversityService, studyPrograms *StudyProgramService, knowledgeBases *KnowledgeBaseService, retention time.Duration) *TrashService {
+	return &TrashService{
+		students:       students,
+		universities:   universities,
+		studyPrograms:  studyPrograms,
+		knowledgeBases: knowledgeBases,
+		retention:      retention,
+	}
+}
+
+func (s *TrashService) Purge() error {
+	before := time.Now().Add(-s.retention)
+
+	purges := []struct {
+		name  string
+		purge func(time.Time) (int64, error)
+	}{
+		{"students", s.students.PurgeDeleted},
+		{"universities", s.universities.PurgeDeleted},
+		{"study programs", s.studyPrograms.PurgeDeleted},
+		{"knowledge bases", s.knowledgeBases.PurgeDeleted},
+	}
+
+	for _, p := range purges {
+		count, err := p.purge(before)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			log.Printf("Purged %d deleted %s from the trash\n", count, p.name)
+		}
+	}
+
+	return nil
+}
@@ -1,6 +1,8 @@
 package services
 
 import (
+	"time"
+
 	"elible/internal/app/models"
 	"elible/internal/app/repository"
 
@@ -23,8 +25,20 @@ func (s *UniversityService) UpdateUniversity(id primitive.ObjectID, u models.Uni
 	return s.repo.UpdateUniversity(id, u)
 }
 
-func (s *UniversityService) DeleteUniversity(id primitive.ObjectID) error {
-	return s.repo.DeleteUniversity(id)
+func (s *UniversityService) DeleteUniversity(id primitive.ObjectID, deletedBy string) error {
+	return s.repo.DeleteUniversity(id, deletedBy)
+}
+
+func (s *UniversityService) RestoreUniversity(id primitive.ObjectID) error {
+	return s.repo.RestoreUniversity(id)
+}
+
+func (s *UniversityService) GetDeletedUniversities() ([]models.University, error) {
+	return s.repo.GetDeletedUniversities()
+}
+
+func (s *UniversityService) PurgeDeleted(before time.Time) (int64, error) {
+	return s.repo.PurgeDeleted(before)
 }
 
 func (s *UniversityService) GetUniversity(id primitive.ObjectID) (models.University, error) {
@@ -1 +1,222 @@
-package services
\ No newline at end of file
+package services
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"elible/internal/app/models"
+	"elible/internal/app/repository"
+	"elible/internal/app/utils"
+	"elible/internal/config"
+	"elible/internal/mailer"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// MagicLinkLifetime is how long a student portal login link stays valid.
+const MagicLinkLifetime = 15 * time.Minute
+
+// UserService handles the student portal accounts.
+type UserService struct {
+	repo        *repository.UserRepository
+	studentRepo *repository.StudentRepository
+	cfg         *config.Config
+	mailer      mailer.Mailer
+}
+
+func NewUserService(cfg *config.Config, repo *repository.UserRepository, studentRepo *repository.StudentRepository, mail mailer.Mailer) *UserService {
+	return &UserService{
+		repo:        repo,
+		studentRepo: studentRepo,
+		cfg:         cfg,
+		mailer:      mail,
+	}
+}
+
+// RequestMagicLink emails a login link to an active student. It does not reveal
+// whether the email belongs to a student.
+func (s *UserService) RequestMagicLink(email string) error {
+	email = strings.TrimSpace(email)
+	student, err := s.studentRepo.FindByEmail(email)
+	if err != nil {
+		return err
+	}
+	if student == nil || !student.IsActive {
+		return nil
+	}
+
+	user, err := s.repo.FindByEmail(email)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		user = &models.User{StudentID: student.ID, Email: email}
+		if err := s.repo.Create(user); err != nil {
+			return err
+		}
+	}
+
+	token, err := utils.RandomString(32)
+	if err != nil {
+		return err
+	}
+
+	link := &models.MagicLink{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(MagicLinkLifetime),
+	}
+	if err := s.repo.SaveMagicLink(link); err != nil {
+		return err
+	}
+
+	body, err := mailer.Render(mailer.MagicLinkTemplate, mailer.MagicLinkData{
+		Name:      student.Name,
+		Link:      s.cfg.PortalLoginURL + "?token=" + url.QueryEscape(token),
+		ExpiresIn: MagicLinkLifetime.String(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(email, mailer.MagicLinkSubject, body)
+}
+
+func (s *UserService) VerifyMagicLink(token string) (*models.User, *models.UserToken, error) {
+	link, err := s.repo.UseMagicLink(utils.HashToken(token))
+	if err != nil {
+		return nil, nil, err
+	}
+	if link == nil {
+		return nil, nil, errors.New("invalid or expired login link")
+	}
+
+	user, err := s.repo.FindByID(link.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user == nil {
+		return nil, nil, errors.New("invalid or expired login link")
+	}
+
+	return s.issueToken(user)
+}
+
+func (s *UserService) Login(email, password string) (*models.User, *models.UserToken, error) {
+	user, err := s.repo.FindByEmail(strings.TrimSpace(email))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Accounts created through a magic link have no password until the student sets one
+	if user == nil || user.Password == "" {
+		return nil, nil, errors.New("invalid email or password")
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return nil, nil, errors.New("invalid email or password")
+	}
+
+	return s.issueToken(user)
+}
+
+func (s *UserService) issueToken(user *models.User) (*models.User, *models.UserToken, error) {
+	student, err := s.studentRepo.GetByID(user.StudentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if student == nil || !student.IsActive {
+		return nil, nil, errors.New("student account is not active")
+	}
+
+	accessToken, expiresAt, err := utils.CreateStudentToken(s.cfg, user.ID.Hex())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	token := &models.UserToken{
+		UserID:      user.ID,
+		AccessToken: accessToken,
+		ExpiresAt:   expiresAt,
+	}
+	if err := s.repo.SaveToken(token); err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.repo.Update(user.ID, bson.M{"last_login_at": time.Now()}); err != nil {
+		return nil, nil, err
+	}
+
+	return user, token, nil
+}
+
+func (s *UserService) Logout(accessToken string) error {
+	return s.repo.DeleteToken(accessToken)
+}
+
+func (s *UserService) GetUserByToken(accessToken string) (*models.User, error) {
+	if _, err := utils.ValidateStudentToken(accessToken, s.cfg); err != nil {
+		return nil, err
+	}
+	return s.repo.GetUserByToken(accessToken)
+}
+
+// SetPassword lets a student add a password to their account. Changing an
+// existing password requires the current one.
+func (s *UserService) SetPassword(user *models.User, oldPassword, newPassword string) error {
+	if user.Password != "" {
+		if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(oldPassword)); err != nil {
+			return errors.New("invalid password")
+		}
+	}
+
+	if len(newPassword) < MinPasswordLength {
+		return errors.New("password must be at least 8 characters")
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.Update(user.ID, bson.M{"password": string(hashedPassword)})
+}
+
+// GetStudent returns the student record of the logged in user, including track records and lobby progress.
+func (s *UserService) GetStudent(user *models.User) (*models.Student, error) {
+	student, err := s.studentRepo.GetByID(user.StudentID)
+	if err != nil {
+		return nil, err
+	}
+	if student == nil {
+		return nil, errors.New("student not found")
+	}
+	return student, nil
+}
+
+func (s *UserService) UpdateStudent(user *models.User, update models.StudentSelfUpdate) (*models.Student, error) {
+	fields := bson.M{}
+	if update.Phone != "" {
+		fields["phone"] = update.Phone
+	}
+	if update.Interest != "" {
+		fields["interest"] = update.Interest
+	}
+	if update.Image != "" {
+		fields["image"] = update.Image
+	}
+	if update.Birthdate != "" {
+		fields["birthdate"] = update.Birthdate
+	}
+
+	if len(fields) > 0 {
+		if err := s.studentRepo.UpdateFields(user.StudentID, fields); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.GetStudent(user)
+}
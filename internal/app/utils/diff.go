@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"reflect"
+	"sort"
+
+	"elible/internal/app/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// diffIgnoredFields are bookkeeping fields that change on every write.
+var diffIgnoredFields = map[string]bool{
+	"updated_at": true,
+	"updatedAt":  true,
+}
+
+// Diff compares the BSON form of two documents and returns the top level
+// fields whose values differ. Either side may be nil for creates and deletes.
+func Diff(before, after interface{}) ([]models.FieldChange, error) {
+	beforeDoc, err := ToDocument(before)
+	if err != nil {
+		return nil, err
+	}
+	afterDoc, err := ToDocument(after)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]bool)
+	for field := range beforeDoc {
+		fields[field] = true
+	}
+	for field := range afterDoc {
+		fields[field] = true
+	}
+
+	names := make([]string, 0, len(fields))
+	for field := range fields {
+		if !diffIgnoredFields[field] {
+			names = append(names, field)
+		}
+	}
+	sort.Strings(names)
+
+	var changes []models.FieldChange
+	for _, field := range names {
+		if reflect.DeepEqual(beforeDoc[field], afterDoc[field]) {
+			continue
+		}
+		changes = append(changes, models.FieldChange{
+			Field:  field,
+			Before: beforeDoc[field],
+			After:  afterDoc[field],
+		})
+	}
+
+	return changes, nil
+}
+
+// ToDocument converts a value to its BSON document form, treating nil as empty.
+func ToDocument(value interface{}) (bson.M, error) {
+	if value == nil {
+		return bson.M{}, nil
+	}
+	if v := reflect.ValueOf(value); v.Kind() == reflect.Ptr && v.IsNil() {
+		return bson.M{}, nil
+	}
+
+	data, err := bson.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc bson.M
+	if err := bson.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"elible/internal/app/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestDiff(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	before := &models.Student{Name: "Rina", Email: "rina@elible.test", Phone: "0812", UpdatedAt: created}
+
+	tests := []struct {
+		name   string
+		before interface{}
+		after  interface{}
+		want   []models.FieldChange
+	}{
+		{
+			name:   "no change",
+			before: before,
+			after:  &models.Student{Name: "Rina", Email: "rina@elible.test", Phone: "0812", UpdatedAt: created},
+			want:   nil,
+		},
+		{
+			name:   "changed, added and removed fields in field order",
+			before: before,
+			after:  &models.Student{Name: "Rina Putri", Phone: "0812", Gender: "F"},
+			want: []models.FieldChange{
+				{Field: "email", Before: "rina@elible.test", After: nil},
+				{Field: "gender", Before: nil, After: "F"},
+				{Field: "name", Before: "Rina", After: "Rina Putri"},
+			},
+		},
+		{
+			name:   "updated_at is ignored",
+			before: before,
+			after:  &models.Student{Name: "Rina", Email: "rina@elible.test", Phone: "0812", UpdatedAt: created.Add(time.Hour)},
+			want:   nil,
+		},
+		{
+			name:   "create",
+			before: nil,
+			after:  &models.Student{Name: "Rina"},
+			want: []models.FieldChange{
+				{Field: "name", Before: nil, After: "Rina"},
+			},
+		},
+		{
+			name:   "delete from a nil pointer",
+			before: &models.Student{Name: "Rina"},
+			after:  (*models.Student)(nil),
+			want: []models.FieldChange{
+				{Field: "name", Before: "Rina", After: nil},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Diff(tt.before, tt.after)
+			if err != nil {
+				t.Fatalf("Diff: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToDocument(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  bson.M
+	}{
+		{"nil", nil, bson.M{}},
+		{"nil pointer", (*models.Student)(nil), bson.M{}},
+		{"struct uses bson keys", models.School{Name: "SMA 1", Phone: "022"}, bson.M{"name": "SMA 1", "phone": "022"}},
+		{"map", bson.M{"a": int32(1)}, bson.M{"a": int32(1)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ToDocument(tt.value)
+			if err != nil {
+				t.Fatalf("ToDocument: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ToDocument() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
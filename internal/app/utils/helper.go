@@ -2,10 +2,13 @@ package utils
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
+	"fmt"
 	"log"
 	"mime/multipart"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -25,6 +28,24 @@ func IsImage(file *multipart.FileHeader) bool {
 	return false
 }
 
+// IsAttachment accepts images and common document formats.
+func IsAttachment(file *multipart.FileHeader) bool {
+	if IsImage(file) {
+		return true
+	}
+
+	supportedExtensions := []string{".pdf", ".doc", ".docx", ".xls", ".xlsx", ".txt"}
+	extension := strings.ToLower(filepath.Ext(file.Filename))
+
+	for _, supportedExtension := range supportedExtensions {
+		if extension == supportedExtension {
+			return true
+		}
+	}
+
+	return false
+}
+
 func RandomString(length int) (string, error) {
 	bytes := make([]byte, length)
 	if _, err := rand.Read(bytes); err != nil {
@@ -42,3 +63,50 @@ func ParseDate(dateString string) time.Time {
 	}
 	return t
 }
+
+// HashToken returns the SHA-256 hex digest used to store single-use tokens.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// ParseRupiah reads amounts such as "Rp 1.500.000", "1500000" or "IDR 1.500.000,00" as whole rupiah.
+func ParseRupiah(value string) (int64, error) {
+	cleaned := strings.ToUpper(strings.TrimSpace(value))
+	cleaned = strings.TrimPrefix(cleaned, "IDR")
+	cleaned = strings.TrimPrefix(cleaned, "RP")
+	cleaned = strings.NewReplacer(" ", "", ".", "").Replace(strings.TrimSpace(cleaned))
+
+	// Drop a decimal part written with a comma, as in 1.500.000,00
+	if whole, cents, found := strings.Cut(cleaned, ","); found {
+		if len(cents) > 2 || strings.Trim(cents, "0123456789") != "" {
+			return 0, fmt.Errorf("invalid rupiah amount: %q", value)
+		}
+		cleaned = whole
+	}
+
+	amount, err := strconv.ParseInt(cleaned, 10, 64)
+	if err != nil || amount < 0 {
+		return 0, fmt.Errorf("invalid rupiah amount: %q", value)
+	}
+	return amount, nil
+}
+
+// FormatRupiah writes an amount the way it is printed on invoices, e.g. "Rp 1.500.000".
+func FormatRupiah(amount int64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	digits := strconv.FormatInt(amount, 10)
+	var grouped strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			grouped.WriteByte('.')
+		}
+		grouped.WriteRune(digit)
+	}
+	return sign + "Rp " + grouped.String()
+}
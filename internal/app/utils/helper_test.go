@@ -0,0 +1,67 @@
+package utils
+
+import "testing"
+
+func TestParseRupiah(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int64
+		wantErr bool
+	}{
+		{"1500000", 1500000, false},
+		{"Rp 1.500.000", 1500000, false},
+		{"Rp1.500.000", 1500000, false},
+		{"rp 1.500.000", 1500000, false},
+		{"IDR 1.500.000,00", 1500000, false},
+		{"1.500.000,5", 1500000, false},
+		{"  Rp 250.000  ", 250000, false},
+		{"0", 0, false},
+		{"", 0, true},
+		{"gratis", 0, true},
+		{"Rp -5.000", 0, true},
+		{"1.500.000,000", 0, true},
+		{"1.500.000,ab", 0, true},
+		{"1,500,000", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseRupiah(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseRupiah(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseRupiah(%q) = %d, want %d", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestFormatRupiah(t *testing.T) {
+	tests := []struct {
+		amount int64
+		want   string
+	}{
+		{0, "Rp 0"},
+		{999, "Rp 999"},
+		{1000, "Rp 1.000"},
+		{250000, "Rp 250.000"},
+		{1500000, "Rp 1.500.000"},
+		{1234567890, "Rp 1.234.567.890"},
+		{-5000, "-Rp 5.000"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatRupiah(tt.amount); got != tt.want {
+			t.Errorf("FormatRupiah(%d) = %q, want %q", tt.amount, got, tt.want)
+		}
+	}
+}
+
+func TestFormatRupiahParsesBack(t *testing.T) {
+	for _, amount := range []int64{0, 7, 1000, 1500000, 987654321} {
+		got, err := ParseRupiah(FormatRupiah(amount))
+		if err != nil || got != amount {
+			t.Errorf("ParseRupiah(FormatRupiah(%d)) = (%d, %v)", amount, got, err)
+		}
+	}
+}
@@ -9,22 +9,27 @@ import (
 	"github.com/golang-jwt/jwt"
 )
 
-type Claims struct {
-	Username string `json:"username"`
-	jwt.StandardClaims
-}
-
 // TokenDetails struct
 type TokenDetails struct {
-	AccessToken string
-	AccessUUID  string
-	AtExpires   int64
+	AccessToken  string
+	AccessUUID   string
+	AtExpires    int64
+	RefreshToken string
+	RtExpires    int64
 }
 
-// CreateToken function
-func CreateToken(adminID string) (*TokenDetails, error) {
+// RefreshTokenLifetime is how long a refresh token can be exchanged for a new access token.
+const RefreshTokenLifetime = 7 * 24 * time.Hour
+
+// CreateToken issues an access token for the admin, signed with the current key from the config.
+func CreateToken(cfg *config.Config, adminID string) (*TokenDetails, error) {
+	if cfg.JWTSecret == "" {
+		return nil, errors.New("JWT secret is not configured")
+	}
+
+	now := time.Now()
 	td := &TokenDetails{}
-	td.AtExpires = time.Now().Add(time.Minute * 15).Unix()
+	td.AtExpires = now.Add(cfg.AccessTokenLifetime()).Unix()
 	td.AccessUUID = adminID
 
 	var err error
@@ -32,32 +37,32 @@ func CreateToken(adminID string) (*TokenDetails, error) {
 	atClaims := jwt.MapClaims{}
 	atClaims["authorized"] = true
 	atClaims["access_uuid"] = td.AccessUUID
+	atClaims["sub"] = adminID
+	atClaims["iat"] = now.Unix()
 	atClaims["exp"] = td.AtExpires
+	if cfg.JWTIssuer != "" {
+		atClaims["iss"] = cfg.JWTIssuer
+	}
+	if cfg.JWTAudience != "" {
+		atClaims["aud"] = cfg.JWTAudience
+	}
+
 	at := jwt.NewWithClaims(jwt.SigningMethodHS256, atClaims)
-	td.AccessToken, err = at.SignedString([]byte("your_secret_key"))
+	if cfg.JWTKeyID != "" {
+		at.Header["kid"] = cfg.JWTKeyID
+	}
+	td.AccessToken, err = at.SignedString([]byte(cfg.JWTSecret))
 	if err != nil {
 		return nil, err
 	}
-	return td, nil
-}
-
-func GenerateJWT(username string, secretKey string) (string, error) {
-	expirationTime := time.Now().Add(24 * time.Hour)
-	claims := &Claims{
-		Username: username,
-		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: expirationTime.Unix(),
-		},
-	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(secretKey))
 
+	// Refresh tokens are opaque and only valid while stored in tb_tokens
+	td.RefreshToken, err = RandomString(32)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-
-	return tokenString, nil
+	td.RtExpires = now.Add(RefreshTokenLifetime).Unix()
+	return td, nil
 }
 
 func ValidateJWT(tokenString string, cfg *config.Config) (jwt.MapClaims, error) {
@@ -65,35 +70,98 @@ func ValidateJWT(tokenString string, cfg *config.Config) (jwt.MapClaims, error)
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, errors.New("Unexpected signing method")
 		}
-		return []byte(cfg.JWTSecret), nil
+		kid, _ := token.Header["kid"].(string)
+		secret, ok := cfg.VerificationKey(kid)
+		if !ok {
+			return nil, errors.New("Unknown signing key")
+		}
+		return []byte(secret), nil
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		return claims, nil
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, errors.New("Invalid token")
 	}
 
-	return nil, errors.New("Invalid token")
+	if cfg.JWTIssuer != "" && !claims.VerifyIssuer(cfg.JWTIssuer, true) {
+		return nil, errors.New("Invalid token issuer")
+	}
+	if cfg.JWTAudience != "" && !claims.VerifyAudience(cfg.JWTAudience, true) {
+		return nil, errors.New("Invalid token audience")
+	}
+
+	return claims, nil
 }
 
 func ValidateJWTWithLocalSecret(tokenString string, cfg *config.Config) (jwt.MapClaims, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("Unexpected signing method")
-		}
-		return []byte(cfg.JWTSecret), nil
-	})
-	if err != nil {
-		return nil, err
+	return ValidateJWT(tokenString, cfg)
+}
+
+// MFATokenLifetime is how long an admin has to enter the second factor after the password.
+const MFATokenLifetime = 5 * time.Minute
+
+// StudentTokenLifetime is how long a student portal session lasts.
+const StudentTokenLifetime = 24 * time.Hour
+
+// CreateMFAToken issues a short-lived token proving the password step of a login succeeded.
+// It is never stored in tb_tokens, so it cannot be used as an access token.
+func CreateMFAToken(cfg *config.Config, adminID string) (string, error) {
+	return createPurposeToken(cfg, "mfa", adminID, time.Now().Add(MFATokenLifetime))
+}
+
+func ValidateMFAToken(tokenString string, cfg *config.Config) (string, error) {
+	return validatePurposeToken(tokenString, cfg, "mfa")
+}
+
+// CreateStudentToken issues a portal token for a student user. The purpose claim
+// keeps it from being accepted where an admin token is expected, and the other way round.
+func CreateStudentToken(cfg *config.Config, userID string) (string, int64, error) {
+	expiresAt := time.Now().Add(StudentTokenLifetime)
+	token, err := createPurposeToken(cfg, "student", userID, expiresAt)
+	return token, expiresAt.Unix(), err
+}
+
+func ValidateStudentToken(tokenString string, cfg *config.Config) (string, error) {
+	return validatePurposeToken(tokenString, cfg, "student")
+}
+
+func createPurposeToken(cfg *config.Config, purpose, subject string, expiresAt time.Time) (string, error) {
+	if cfg.JWTSecret == "" {
+		return "", errors.New("JWT secret is not configured")
 	}
 
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		return claims, nil
+	claims := jwt.MapClaims{
+		"purpose": purpose,
+		"sub":     subject,
+		"iat":     time.Now().Unix(),
+		"exp":     expiresAt.Unix(),
+	}
+	if cfg.JWTIssuer != "" {
+		claims["iss"] = cfg.JWTIssuer
+	}
+	if cfg.JWTAudience != "" {
+		claims["aud"] = cfg.JWTAudience
 	}
 
-	// main(cfg)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	if cfg.JWTKeyID != "" {
+		token.Header["kid"] = cfg.JWTKeyID
+	}
+	return token.SignedString([]byte(cfg.JWTSecret))
+}
+
+func validatePurposeToken(tokenString string, cfg *config.Config, purpose string) (string, error) {
+	claims, err := ValidateJWT(tokenString, cfg)
+	if err != nil {
+		return "", err
+	}
 
-	return nil, errors.New("Invalid token")
+	subject, _ := claims["sub"].(string)
+	if claims["purpose"] != purpose || subject == "" {
+		return "", errors.New("Invalid token")
+	}
+	return subject, nil
 }
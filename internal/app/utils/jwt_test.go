@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"testing"
+
+	"elible/internal/config"
+
+	"github.com/golang-jwt/jwt"
+)
+
+func TestValidateJWTSelectsKeyByKid(t *testing.T) {
+	// Tokens signed before the rotation carry the old kid, or none at all
+	oldConfig := &config.Config{JWTSecret: "old-secret", JWTKeyID: "2024-01"}
+	unversionedConfig := &config.Config{JWTSecret: "old-secret"}
+	rotated := &config.Config{
+		JWTSecret:       "new-secret",
+		JWTKeyID:        "2024-06",
+		JWTPreviousKeys: map[string]string{"2024-01": "old-secret"},
+	}
+	retired := &config.Config{JWTSecret: "new-secret", JWTKeyID: "2024-06"}
+
+	tests := []struct {
+		name     string
+		issuedBy *config.Config
+		checkBy  *config.Config
+		wantErr  bool
+	}{
+		{"current key", rotated, rotated, false},
+		{"previous key listed", oldConfig, rotated, false},
+		{"previous key retired", oldConfig, retired, true},
+		{"token without kid checked with the current secret", unversionedConfig, rotated, true},
+		{"token without kid before rotation", unversionedConfig, unversionedConfig, false},
+		{"unknown kid", &config.Config{JWTSecret: "other", JWTKeyID: "2023-01"}, rotated, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			td, err := CreateToken(tt.issuedBy, "admin-id")
+			if err != nil {
+				t.Fatalf("CreateToken: %v", err)
+			}
+
+			claims, err := ValidateJWT(td.AccessToken, tt.checkBy)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("ValidateJWT() accepted the token, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ValidateJWT(): %v", err)
+			}
+			if claims["sub"] != "admin-id" {
+				t.Errorf("sub = %v, want admin-id", claims["sub"])
+			}
+		})
+	}
+}
+
+func TestCreateTokenSetsKid(t *testing.T) {
+	tests := []struct {
+		keyID   string
+		wantKid interface{}
+	}{
+		{"2024-06", "2024-06"},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		td, err := CreateToken(&config.Config{JWTSecret: "secret", JWTKeyID: tt.keyID}, "admin-id")
+		if err != nil {
+			t.Fatalf("CreateToken: %v", err)
+		}
+		token, _, err := new(jwt.Parser).ParseUnverified(td.AccessToken, jwt.MapClaims{})
+		if err != nil {
+			t.Fatalf("parse: %v", err)
+		}
+		if kid := token.Header["kid"]; kid != tt.wantKid {
+			t.Errorf("kid with JWT_KEY_ID %q = %v, want %v", tt.keyID, kid, tt.wantKid)
+		}
+	}
+}
+
+func TestValidateJWTChecksIssuerAndAudience(t *testing.T) {
+	issuer := &config.Config{JWTSecret: "secret", JWTIssuer: "elible", JWTAudience: "elible-admin"}
+
+	tests := []struct {
+		name    string
+		checkBy *config.Config
+		wantErr bool
+	}{
+		{"matching", issuer, false},
+		{"other issuer", &config.Config{JWTSecret: "secret", JWTIssuer: "someone-else"}, true},
+		{"other audience", &config.Config{JWTSecret: "secret", JWTAudience: "portal"}, true},
+		{"not checked", &config.Config{JWTSecret: "secret"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			td, err := CreateToken(issuer, "admin-id")
+			if err != nil {
+				t.Fatalf("CreateToken: %v", err)
+			}
+			if _, err := ValidateJWT(td.AccessToken, tt.checkBy); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateJWT() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPurposeTokensAreNotInterchangeable(t *testing.T) {
+	cfg := &config.Config{JWTSecret: "secret"}
+
+	mfaToken, err := CreateMFAToken(cfg, "admin-id")
+	if err != nil {
+		t.Fatalf("CreateMFAToken: %v", err)
+	}
+	if subject, err := ValidateMFAToken(mfaToken, cfg); err != nil || subject != "admin-id" {
+		t.Errorf("ValidateMFAToken() = (%q, %v), want (admin-id, nil)", subject, err)
+	}
+	if _, err := ValidateStudentToken(mfaToken, cfg); err == nil {
+		t.Error("an MFA token was accepted as a student token")
+	}
+
+	td, err := CreateToken(cfg, "admin-id")
+	if err != nil {
+		t.Fatalf("CreateToken: %v", err)
+	}
+	if _, err := ValidateMFAToken(td.AccessToken, cfg); err == nil {
+		t.Error("an access token was accepted as an MFA token")
+	}
+}
@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"elible/internal/app/models"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// RenderReceipt draws an invoice as an A4 PDF, listing its items and the
+// payments made so far. Paid invoices are titled as a receipt.
+func RenderReceipt(issuer string, invoice *models.Invoice) ([]byte, error) {
+	location, _ := time.LoadLocation("Asia/Jakarta")
+
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle(invoice.Number, true)
+	pdf.SetMargins(20, 20, 20)
+	pdf.AddPage()
+
+	title := "INVOICE"
+	if invoice.Status == models.InvoiceStatusPaid {
+		title = "RECEIPT"
+	}
+
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.CellFormat(0, 10, issuer, "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "B", 14)
+	pdf.CellFormat(0, 8, title, "", 1, "L", false, 0, "")
+	pdf.Ln(4)
+
+	pdf.SetFont("Helvetica", "", 10)
+	details := [][2]string{
+		{"Number", invoice.Number},
+		{"Student", invoice.StudentName},
+		{"Issued", invoice.CreatedAt.In(location).Format("02 Jan 2006")},
+		{"Due", invoice.DueDate.In(location).Format("02 Jan 2006")},
+		{"Status", invoice.Status},
+	}
+	for _, detail := range details {
+		pdf.CellFormat(30, 6, detail[0], "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 6, detail[1], "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(6)
+
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.CellFormat(100, 8, "Service", "B", 0, "L", false, 0, "")
+	pdf.CellFormat(30, 8, "Date", "B", 0, "L", false, 0, "")
+	pdf.CellFormat(40, 8, "Amount", "B", 1, "R", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	for _, item := range invoice.Items {
+		pdf.CellFormat(100, 7, item.ServiceName, "", 0, "L", false, 0, "")
+		pdf.CellFormat(30, 7, item.ServiceDate, "", 0, "L", false, 0, "")
+		pdf.CellFormat(40, 7, FormatRupiah(item.Amount), "", 1, "R", false, 0, "")
+	}
+	pdf.Ln(2)
+
+	totals := [][2]string{
+		{"Subtotal", FormatRupiah(invoice.Subtotal)},
+		{"Discount", FormatRupiah(-invoice.Discount)},
+		{"Total", FormatRupiah(invoice.Total)},
+		{"Paid", FormatRupiah(invoice.AmountPaid)},
+		{"Balance", FormatRupiah(invoice.Balance())},
+	}
+	for _, total := range totals {
+		pdf.CellFormat(130, 7, total[0], "", 0, "R", false, 0, "")
+		pdf.CellFormat(40, 7, total[1], "", 1, "R", false, 0, "")
+	}
+
+	if len(invoice.Payments) > 0 {
+		pdf.Ln(6)
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.CellFormat(40, 8, "Paid on", "B", 0, "L", false, 0, "")
+		pdf.CellFormat(40, 8, "Method", "B", 0, "L", false, 0, "")
+		pdf.CellFormat(50, 8, "Reference", "B", 0, "L", false, 0, "")
+		pdf.CellFormat(40, 8, "Amount", "B", 1, "R", false, 0, "")
+		pdf.SetFont("Helvetica", "", 10)
+		for _, payment := range invoice.Payments {
+			pdf.CellFormat(40, 7, payment.PaidAt.In(location).Format("02 Jan 2006"), "", 0, "L", false, 0, "")
+			pdf.CellFormat(40, 7, payment.Method, "", 0, "L", false, 0, "")
+			pdf.CellFormat(50, 7, payment.Reference, "", 0, "L", false, 0, "")
+			pdf.CellFormat(40, 7, FormatRupiah(payment.Amount), "", 1, "R", false, 0, "")
+		}
+	}
+
+	if invoice.Notes != "" {
+		pdf.Ln(6)
+		pdf.MultiCell(0, 6, invoice.Notes, "", "L", false)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("error rendering receipt: %v", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// schoolAbbreviations expands the abbreviations commonly used in Indonesian
+// school names so that "SMAN 1" and "SMA Negeri 1" normalize the same way.
+var schoolAbbreviations = map[string][]string{
+	"SDN":  {"SD", "NEGERI"},
+	"SMPN": {"SMP", "NEGERI"},
+	"SMAN": {"SMA", "NEGERI"},
+	"SMKN": {"SMK", "NEGERI"},
+	"MIN":  {"MI", "NEGERI"},
+	"MTSN": {"MTS", "NEGERI"},
+	"MAN":  {"MA", "NEGERI"},
+	"NEG":  {"NEGERI"},
+	"N":    {"NEGERI"},
+	"SWT":  {"SWASTA"},
+	"S":    {"SWASTA"},
+}
+
+// NormalizeSchoolName upper-cases a school name, drops punctuation and
+// expands common abbreviations, e.g. "sman 1 bandung" and "SMA Negeri 1
+// Bandung" both become "SMA NEGERI 1 BANDUNG".
+func NormalizeSchoolName(name string) string {
+	cleaned := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return ' '
+	}, name)
+
+	var words []string
+	for i, word := range strings.Fields(cleaned) {
+		expanded, ok := schoolAbbreviations[word]
+		// Single letters only mean negeri or swasta right after the school type, as in "SMA N 1"
+		if ok && len(word) == 1 && i != 1 {
+			ok = false
+		}
+		if ok {
+			words = append(words, expanded...)
+		} else {
+			words = append(words, word)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// Levenshtein is the number of single character edits between a and b.
+func Levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
+
+// Similarity scores two strings from 0 (nothing in common) to 1 (equal) by edit distance.
+func Similarity(a, b string) float64 {
+	longest := len([]rune(a))
+	if n := len([]rune(b)); n > longest {
+		longest = n
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(Levenshtein(a, b))/float64(longest)
+}
+
+func minInt(values ...int) int {
+	min := values[0]
+	for _, v := range values[1:] {
+		if v < min {
+			min = v
+		}
+	}
+	return min
+}
+
+// NormalizePhone keeps the digits of a phone number and writes Indonesian
+// numbers in their local form, so "+62 812-3456" and "0812 3456" are equal.
+func NormalizePhone(phone string) string {
+	digits := strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, phone)
+
+	switch {
+	case strings.HasPrefix(digits, "62"):
+		return "0" + digits[2:]
+	case strings.HasPrefix(digits, "8"):
+		return "0" + digits
+	}
+	return digits
+}
+
+// NormalizePersonName upper-cases a name and collapses its whitespace.
+func NormalizePersonName(name string) string {
+	return strings.Join(strings.Fields(strings.ToUpper(name)), " ")
+}
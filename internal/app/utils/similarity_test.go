@@ -0,0 +1,115 @@
+package utils
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNormalizeSchoolName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"SMA Negeri 1 Bandung", "SMA NEGERI 1 BANDUNG"},
+		{"sman 1 bandung", "SMA NEGERI 1 BANDUNG"},
+		{"SMA N 1 Bandung", "SMA NEGERI 1 BANDUNG"},
+		{"SMA Neg. 1 Bandung", "SMA NEGERI 1 BANDUNG"},
+		{"  SMA   Negeri-1,  Bandung ", "SMA NEGERI 1 BANDUNG"},
+		{"SMKN 2 Surabaya", "SMK NEGERI 2 SURABAYA"},
+		{"SMP S Tunas Harapan", "SMP SWASTA TUNAS HARAPAN"},
+		{"MTsN 3 Jakarta", "MTS NEGERI 3 JAKARTA"},
+		// A single letter elsewhere in the name is not an abbreviation
+		{"SMA Katolik St. N Jakarta", "SMA KATOLIK ST N JAKARTA"},
+		{"SD Kristen S", "SD KRISTEN S"},
+		{"", ""},
+		{"!!!", ""},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeSchoolName(tt.name); got != tt.want {
+			t.Errorf("NormalizeSchoolName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+		{"BANDUNG", "BANDUNG", 0},
+		{"BANDUNG", "BANDNUG", 2},
+		{"JAKARTA", "JAKARTA UTARA", 6},
+		{"ÉCOLE", "ECOLE", 1},
+	}
+
+	for _, tt := range tests {
+		if got := Levenshtein(tt.a, tt.b); got != tt.want {
+			t.Errorf("Levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := Levenshtein(tt.b, tt.a); got != tt.want {
+			t.Errorf("Levenshtein(%q, %q) = %d, want %d", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"", "", 1},
+		{"SMA NEGERI 1", "SMA NEGERI 1", 1},
+		{"ABCD", "", 0},
+		{"ABCD", "WXYZ", 0},
+		{"ABCD", "ABCE", 0.75},
+		{"SMA NEGERI 1 BANDUNG", "SMA NEGERI 2 BANDUNG", 0.95},
+	}
+
+	for _, tt := range tests {
+		if got := Similarity(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Similarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct {
+		phone string
+		want  string
+	}{
+		{"+62 812-3456-7890", "081234567890"},
+		{"62 812 3456 7890", "081234567890"},
+		{"0812 3456 7890", "081234567890"},
+		{"812-3456-7890", "081234567890"},
+		{"(022) 123456", "022123456"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizePhone(tt.phone); got != tt.want {
+			t.Errorf("NormalizePhone(%q) = %q, want %q", tt.phone, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizePersonName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Rina Putri", "RINA PUTRI"},
+		{"  rina   putri ", "RINA PUTRI"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizePersonName(tt.name); got != tt.want {
+			t.Errorf("NormalizePersonName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package utils
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA-1 seed of the RFC 6238 test vectors, "12345678901234567890", in base32.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestValidateTOTPRFCVectors(t *testing.T) {
+	// The RFC lists 8 digit codes, these are their last 6 digits
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		step, ok := ValidateTOTP(rfcSecret, tt.code, time.Unix(tt.unix, 0))
+		if !ok {
+			t.Errorf("ValidateTOTP(%q) at %d = false, want true", tt.code, tt.unix)
+			continue
+		}
+		if want := tt.unix / totpPeriod; step != want {
+			t.Errorf("ValidateTOTP(%q) at %d returned step %d, want %d", tt.code, tt.unix, step, want)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	current := now.Unix() / totpPeriod
+
+	tests := []struct {
+		name     string
+		secret   string
+		code     string
+		wantOK   bool
+		wantStep int64
+	}{
+		{"current step", rfcSecret, "005924", true, current},
+		{"surrounding spaces", rfcSecret, " 005924 ", true, current},
+		{"lower case secret", strings.ToLower(rfcSecret), "005924", true, current},
+		{"previous step", rfcSecret, totpCode([]byte("12345678901234567890"), current-1), true, current - 1},
+		{"next step", rfcSecret, totpCode([]byte("12345678901234567890"), current+1), true, current + 1},
+		{"two steps ago", rfcSecret, totpCode([]byte("12345678901234567890"), current-2), false, 0},
+		{"two steps ahead", rfcSecret, totpCode([]byte("12345678901234567890"), current+2), false, 0},
+		{"wrong code", rfcSecret, "000000", false, 0},
+		{"empty code", rfcSecret, "", false, 0},
+		{"invalid secret", "not base32!", "005924", false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := ValidateTOTP(tt.secret, tt.code, now)
+			if ok != tt.wantOK || step != tt.wantStep {
+				t.Errorf("ValidateTOTP() = (%d, %v), want (%d, %v)", step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+// A code stays valid for the neighbouring steps, so replay protection relies on
+// ValidateTOTP reporting the same step for it wherever it is checked in that window.
+func TestValidateTOTPReportsStepForReplayCheck(t *testing.T) {
+	issued := time.Unix(1234567890, 0)
+	code := totpCode([]byte("12345678901234567890"), issued.Unix()/totpPeriod)
+
+	tests := []struct {
+		name   string
+		offset time.Duration
+		wantOK bool
+	}{
+		{"same moment", 0, true},
+		{"one period later", totpPeriod * time.Second, true},
+		{"one period earlier", -totpPeriod * time.Second, true},
+		{"two periods later", 2 * totpPeriod * time.Second, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := ValidateTOTP(rfcSecret, code, issued.Add(tt.offset))
+			if ok != tt.wantOK {
+				t.Fatalf("ValidateTOTP() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && step != issued.Unix()/totpPeriod {
+				t.Errorf("ValidateTOTP() step = %d, want %d", step, issued.Unix()/totpPeriod)
+			}
+		})
+	}
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	first, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret: %v", err)
+	}
+	second, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret: %v", err)
+	}
+	if first == second {
+		t.Errorf("two secrets are equal: %q", first)
+	}
+
+	key, err := totpEncoding.DecodeString(first)
+	if err != nil {
+		t.Fatalf("secret %q is not unpadded base32: %v", first, err)
+	}
+	if len(key) != 20 {
+		t.Errorf("secret decodes to %d bytes, want 20", len(key))
+	}
+
+	now := time.Now()
+	code := totpCode(key, now.Unix()/totpPeriod)
+	if _, ok := ValidateTOTP(first, code, now); !ok {
+		t.Errorf("code %q of a generated secret is not accepted", code)
+	}
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	uri := TOTPProvisioningURI("Elible", "rina@elible.test", rfcSecret)
+
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		t.Fatalf("parse %q: %v", uri, err)
+	}
+	if parsed.Scheme != "otpauth" || parsed.Host != "totp" {
+		t.Errorf("uri %q does not start with otpauth://totp/", uri)
+	}
+	if parsed.Path != "/Elible:rina@elible.test" {
+		t.Errorf("label = %q, want %q", parsed.Path, "/Elible:rina@elible.test")
+	}
+
+	query := parsed.Query()
+	for key, want := range map[string]string{
+		"secret":    rfcSecret,
+		"issuer":    "Elible",
+		"algorithm": "SHA1",
+		"digits":    "6",
+		"period":    "30",
+	} {
+		if got := query.Get(key); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+}
@@ -4,23 +4,95 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
+// DefaultJWTExpiration is used when JWT_EXPIRATION is empty or not a valid duration.
+const DefaultJWTExpiration = 15 * time.Minute
+
+// DefaultTrashRetentionDays is used when TRASH_RETENTION_DAYS is empty or not a positive number.
+const DefaultTrashRetentionDays = 30
+
+// DefaultInvoiceDueDays is used when INVOICE_DUE_DAYS is empty or not a positive number.
+const DefaultInvoiceDueDays = 14
+
+// DefaultTaskDigestHour is used when TASK_DIGEST_HOUR is empty or not an hour of the day.
+const DefaultTaskDigestHour = 7
+
+// Strategies accepted by COUNSELOR_ASSIGNMENT for new students.
+const (
+	AssignmentRoundRobin  = "round_robin"
+	AssignmentLeastLoaded = "least_loaded"
+	AssignmentManual      = "manual"
+)
+
 type Config struct {
-	JWTSecret         string
-	JWTExpiration     string
-	MongoDBURI        string
-	MongoDBName       string
+	JWTSecret       string
+	JWTExpiration   string
+	JWTIssuer       string
+	JWTAudience     string
+	JWTKeyID        string
+	JWTPreviousKeys map[string]string
+	MongoDBURI      string
+	MongoDBName     string
+
+	SMTPHost         string
+	SMTPPort         string
+	SMTPUsername     string
+	SMTPPassword     string
+	SMTPFrom         string
+	PasswordResetURL string
+	PortalLoginURL   string
+
+	TrashRetentionDays string
+
+	InvoiceIssuer  string
+	InvoiceDueDays string
+
+	CounselorAssignment string
+
+	TaskDigestHour string
+
+	BootstrapAdminUsername string
+	BootstrapAdminPassword string
+	BootstrapAdminEmail    string
 }
 
 func NewConfig() *Config {
 	return &Config{
-		JWTSecret:     os.Getenv("JWT_SECRET"),
-		JWTExpiration: os.Getenv("JWT_EXPIRATION"),
-		MongoDBURI:    os.Getenv("MONGODB_URI"),
-		MongoDBName:   os.Getenv("MONGODB_NAME"),
+		JWTSecret:       os.Getenv("JWT_SECRET"),
+		JWTExpiration:   os.Getenv("JWT_EXPIRATION"),
+		JWTIssuer:       os.Getenv("JWT_ISSUER"),
+		JWTAudience:     os.Getenv("JWT_AUDIENCE"),
+		JWTKeyID:        os.Getenv("JWT_KEY_ID"),
+		JWTPreviousKeys: parseKeys(os.Getenv("JWT_PREVIOUS_KEYS")),
+		MongoDBURI:      os.Getenv("MONGODB_URI"),
+		MongoDBName:     os.Getenv("MONGODB_NAME"),
+
+		SMTPHost:         os.Getenv("SMTP_HOST"),
+		SMTPPort:         os.Getenv("SMTP_PORT"),
+		SMTPUsername:     os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:     os.Getenv("SMTP_PASSWORD"),
+		SMTPFrom:         os.Getenv("SMTP_FROM"),
+		PasswordResetURL: os.Getenv("PASSWORD_RESET_URL"),
+		PortalLoginURL:   os.Getenv("PORTAL_LOGIN_URL"),
+
+		TrashRetentionDays: os.Getenv("TRASH_RETENTION_DAYS"),
+
+		InvoiceIssuer:  os.Getenv("INVOICE_ISSUER"),
+		InvoiceDueDays: os.Getenv("INVOICE_DUE_DAYS"),
+
+		CounselorAssignment: os.Getenv("COUNSELOR_ASSIGNMENT"),
+
+		TaskDigestHour: os.Getenv("TASK_DIGEST_HOUR"),
+
+		BootstrapAdminUsername: os.Getenv("BOOTSTRAP_ADMIN_USERNAME"),
+		BootstrapAdminPassword: os.Getenv("BOOTSTRAP_ADMIN_PASSWORD"),
+		BootstrapAdminEmail:    os.Getenv("BOOTSTRAP_ADMIN_EMAIL"),
 	}
 }
 
@@ -32,3 +104,74 @@ func LoadConfig() (*Config, error) {
 
 	return NewConfig(), nil
 }
+
+// AccessTokenLifetime parses JWT_EXPIRATION as a Go duration such as "15m" or "1h".
+func (c *Config) AccessTokenLifetime() time.Duration {
+	lifetime, err := time.ParseDuration(strings.TrimSpace(c.JWTExpiration))
+	if err != nil || lifetime <= 0 {
+		return DefaultJWTExpiration
+	}
+	return lifetime
+}
+
+// TrashRetention is how long soft deleted documents stay restorable before they are purged.
+func (c *Config) TrashRetention() time.Duration {
+	days, err := strconv.Atoi(strings.TrimSpace(c.TrashRetentionDays))
+	if err != nil || days <= 0 {
+		days = DefaultTrashRetentionDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// InvoiceDueIn is how long after issuing an invoice is due when no due date is given.
+func (c *Config) InvoiceDueIn() time.Duration {
+	days, err := strconv.Atoi(strings.TrimSpace(c.InvoiceDueDays))
+	if err != nil || days <= 0 {
+		days = DefaultInvoiceDueDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// AssignmentStrategy is how new students get a counselor, round robin unless
+// COUNSELOR_ASSIGNMENT names another strategy.
+func (c *Config) AssignmentStrategy() string {
+	switch strategy := strings.ToLower(strings.TrimSpace(c.CounselorAssignment)); strategy {
+	case AssignmentLeastLoaded, AssignmentManual:
+		return strategy
+	default:
+		return AssignmentRoundRobin
+	}
+}
+
+// DigestHour is the hour of the day, Jakarta time, from which the daily task digest is sent.
+func (c *Config) DigestHour() int {
+	hour, err := strconv.Atoi(strings.TrimSpace(c.TaskDigestHour))
+	if err != nil || hour < 0 || hour > 23 {
+		return DefaultTaskDigestHour
+	}
+	return hour
+}
+
+// VerificationKey returns the secret for the given kid. Tokens without a kid, or
+// with the current kid, use JWT_SECRET; retired keys stay valid while they are
+// listed in JWT_PREVIOUS_KEYS.
+func (c *Config) VerificationKey(kid string) (string, bool) {
+	if kid == "" || kid == c.JWTKeyID {
+		return c.JWTSecret, c.JWTSecret != ""
+	}
+	secret, ok := c.JWTPreviousKeys[kid]
+	return secret, ok
+}
+
+// parseKeys reads a "kid:secret,kid:secret" list.
+func parseKeys(raw string) map[string]string {
+	keys := make(map[string]string)
+	for _, pair := range strings.Split(raw, ",") {
+		kid, secret, found := strings.Cut(strings.TrimSpace(pair), ":")
+		if !found || kid == "" || secret == "" {
+			continue
+		}
+		keys[kid] = secret
+	}
+	return keys
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestVerificationKey(t *testing.T) {
+	cfg := &Config{
+		JWTSecret:       "current-secret",
+		JWTKeyID:        "2024-06",
+		JWTPreviousKeys: map[string]string{"2024-01": "old-secret"},
+	}
+
+	tests := []struct {
+		name       string
+		cfg        *Config
+		kid        string
+		wantSecret string
+		wantOK     bool
+	}{
+		{"no kid uses the current secret", cfg, "", "current-secret", true},
+		{"current kid", cfg, "2024-06", "current-secret", true},
+		{"previous kid", cfg, "2024-01", "old-secret", true},
+		{"unknown kid", cfg, "2023-01", "", false},
+		{"no secret configured", &Config{}, "", "", false},
+		{"previous kid without current key id", &Config{JWTSecret: "current-secret", JWTPreviousKeys: map[string]string{"2024-01": "old-secret"}}, "2024-01", "old-secret", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret, ok := tt.cfg.VerificationKey(tt.kid)
+			if secret != tt.wantSecret || ok != tt.wantOK {
+				t.Errorf("VerificationKey(%q) = (%q, %v), want (%q, %v)", tt.kid, secret, ok, tt.wantSecret, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want map[string]string
+	}{
+		{"", map[string]string{}},
+		{"2024-01:old", map[string]string{"2024-01": "old"}},
+		{"a:one, b:two", map[string]string{"a": "one", "b": "two"}},
+		{"a:se:cret", map[string]string{"a": "se:cret"}},
+		{"missing-colon,:no-kid,no-secret:,b:two", map[string]string{"b": "two"}},
+	}
+
+	for _, tt := range tests {
+		if got := parseKeys(tt.raw); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseKeys(%q) = %v, want %v", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestAccessTokenLifetime(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want time.Duration
+	}{
+		{"", DefaultJWTExpiration},
+		{"1h", time.Hour},
+		{" 30m ", 30 * time.Minute},
+		{"fifteen minutes", DefaultJWTExpiration},
+		{"-5m", DefaultJWTExpiration},
+		{"0s", DefaultJWTExpiration},
+	}
+
+	for _, tt := range tests {
+		cfg := &Config{JWTExpiration: tt.raw}
+		if got := cfg.AccessTokenLifetime(); got != tt.want {
+			t.Errorf("AccessTokenLifetime(%q) = %v, want %v", tt.raw, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package mailer
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net"
+	"net/smtp"
+	"strings"
+	"text/template"
+
+	"elible/internal/config"
+)
+
+// Mailer sends plain text emails.
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+// NewMailer returns an SMTP mailer when SMTP_HOST is set and a LogMailer otherwise.
+// Any SMTP server works, including a local fake such as MailHog on port 1025.
+func NewMailer(cfg *config.Config) Mailer {
+	if cfg.SMTPHost == "" {
+		return &LogMailer{}
+	}
+
+	return &SMTPMailer{
+		Host:     cfg.SMTPHost,
+		Port:     cfg.SMTPPort,
+		Username: cfg.SMTPUsername,
+		Password: cfg.SMTPPassword,
+		From:     cfg.SMTPFrom,
+	}
+}
+
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+func (m *SMTPMailer) Send(to, subject, body string) error {
+	port := m.Port
+	if port == "" {
+		port = "25"
+	}
+
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", m.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", subject)
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+
+	return smtp.SendMail(net.JoinHostPort(m.Host, port), auth, m.From, []string{to}, msg.Bytes())
+}
+
+// LogMailer writes emails to the log instead of sending them, for local development.
+type LogMailer struct{}
+
+func (m *LogMailer) Send(to, subject, body string) error {
+	log.Printf("mail to %s: %s\n%s", to, subject, body)
+	return nil
+}
+
+// Render executes a template into an email body.
+func Render(tmpl *template.Template, data interface{}) (string, error) {
+	var body bytes.Buffer
+	if err := tmpl.Execute(&body, data); err != nil {
+		return "", err
+	}
+	return body.String(), nil
+}
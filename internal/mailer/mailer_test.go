@@ -0,0 +1,139 @@
+package mailer
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+)
+
+// fakeMail is what the fake SMTP server received for one message.
+type fakeMail struct {
+	from string
+	to   []string
+	data string
+}
+
+// startFakeSMTP serves a single SMTP session on a local port. rejectRcpt makes
+// the server refuse every recipient, like a server that does not know the address.
+func startFakeSMTP(t *testing.T, rejectRcpt bool) (host, port string, received <-chan fakeMail) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	mails := make(chan fakeMail, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+		var mail fakeMail
+		reply("220 localhost fake smtp")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			command := strings.ToUpper(line)
+
+			switch {
+			case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+				reply("250 localhost")
+			case strings.HasPrefix(command, "MAIL FROM:"):
+				mail.from = strings.Trim(line[len("MAIL FROM:"):], "<>")
+				reply("250 OK")
+			case strings.HasPrefix(command, "RCPT TO:"):
+				if rejectRcpt {
+					reply("550 no such user")
+					continue
+				}
+				mail.to = append(mail.to, strings.Trim(line[len("RCPT TO:"):], "<>"))
+				reply("250 OK")
+			case command == "DATA":
+				reply("354 end with <CRLF>.<CRLF>")
+				var data strings.Builder
+				for {
+					dataLine, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if dataLine == ".\r\n" {
+						break
+					}
+					data.WriteString(dataLine)
+				}
+				mail.data = data.String()
+				mails <- mail
+				reply("250 OK")
+			case command == "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+
+	host, port, err = net.SplitHostPort(listener.Addr().String())
+	if err != nil {
+		t.Fatalf("split address: %v", err)
+	}
+	return host, port, mails
+}
+
+func TestSMTPMailerSendsPasswordReset(t *testing.T) {
+	host, port, received := startFakeSMTP(t, false)
+	mailer := &SMTPMailer{Host: host, Port: port, From: "no-reply@elible.test"}
+
+	body, err := Render(PasswordResetTemplate, PasswordResetData{
+		Name:      "Rina",
+		Username:  "rina",
+		Link:      "https://elible.test/reset?token=abc123",
+		ExpiresIn: "1h0m0s",
+	})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+
+	if err := mailer.Send("rina@elible.test", PasswordResetSubject, body); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	mail := <-received
+	if mail.from != "no-reply@elible.test" {
+		t.Errorf("from = %q, want %q", mail.from, "no-reply@elible.test")
+	}
+	if len(mail.to) != 1 || mail.to[0] != "rina@elible.test" {
+		t.Errorf("to = %v, want [rina@elible.test]", mail.to)
+	}
+	for _, want := range []string{
+		"Subject: " + PasswordResetSubject + "\r\n",
+		"To: rina@elible.test\r\n",
+		"Hi Rina,\r\n",
+		"https://elible.test/reset?token=abc123\r\n",
+		"expires in 1h0m0s",
+	} {
+		if !strings.Contains(mail.data, want) {
+			t.Errorf("message does not contain %q:\n%s", want, mail.data)
+		}
+	}
+}
+
+func TestSMTPMailerReportsRejectedRecipient(t *testing.T) {
+	host, port, _ := startFakeSMTP(t, true)
+	mailer := &SMTPMailer{Host: host, Port: port, From: "no-reply@elible.test"}
+
+	if err := mailer.Send("unknown@elible.test", PasswordResetSubject, "body"); err == nil {
+		t.Fatal("send to a rejected recipient returned no error")
+	}
+}
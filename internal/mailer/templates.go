@@ -0,0 +1,68 @@
+package mailer
+
+import "text/template"
+
+const PasswordResetSubject = "Reset your Elible password"
+
+var PasswordResetTemplate = template.Must(template.New("password_reset").Parse(`Hi {{.Name}},
+
+We received a request to reset the password of your Elible admin account ({{.Username}}).
+
+Open the link below to choose a new password. The link can be used once and expires in {{.ExpiresIn}}.
+
+{{.Link}}
+
+If you did not ask for a password reset you can ignore this email, your password stays the same.
+`))
+
+type PasswordResetData struct {
+	Name      string
+	Username  string
+	Link      string
+	ExpiresIn string
+}
+
+const MagicLinkSubject = "Your Elible login link"
+
+var MagicLinkTemplate = template.Must(template.New("magic_link").Parse(`Hi {{.Name}},
+
+Use the link below to log in to the Elible student portal and check your progress. The link can be used once and expires in {{.ExpiresIn}}.
+
+{{.Link}}
+
+If you did not request this link you can ignore this email.
+`))
+
+type MagicLinkData struct {
+	Name      string
+	Link      string
+	ExpiresIn string
+}
+
+const TaskDigestSubject = "Your Elible follow-ups for today"
+
+var TaskDigestTemplate = template.Must(template.New("task_digest").Parse(`Hi {{.Name}},
+
+Here are your follow-ups for {{.Date}}.
+{{if .Overdue}}
+Overdue:
+{{range .Overdue}}- {{.Title}} ({{.StudentName}}), due {{.Due}}
+{{end}}{{end}}{{if .DueToday}}
+Due today:
+{{range .DueToday}}- {{.Title}} ({{.StudentName}})
+{{end}}{{end}}
+Mark tasks as done in Elible once you have followed up.
+`))
+
+type TaskDigestData struct {
+	Name     string
+	Date     string
+	Overdue  []TaskDigestItem
+	DueToday []TaskDigestItem
+}
+
+type TaskDigestItem struct {
+	Title       string
+	StudentName string
+	Due         string
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -32,3 +33,25 @@ func ConnectMongoDB(uri, dbName string) (*mongo.Client, error) {
 	// fmt.Println("Connected to MongoDB successfully at", time.Now().In(location))
 	return client, nil
 }
+
+// RequireTransactions fails when the server cannot run multi-document
+// transactions, which the API needs for merges, invoices and the pipeline.
+// That takes a replica set (a single node one is enough) or a sharded cluster.
+func RequireTransactions(client *mongo.Client) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello)
+	if err != nil {
+		return fmt.Errorf("failed to read MongoDB topology: %v", err)
+	}
+	if hello.SetName == "" && hello.Msg != "isdbgrid" {
+		return fmt.Errorf("MongoDB is a standalone server, but transactions need a replica set or sharded cluster; start mongod with --replSet and run rs.initiate()")
+	}
+
+	return nil
+}
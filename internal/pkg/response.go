@@ -3,6 +3,11 @@ package pkg
 import (
 	"encoding/json"
 	"net/http"
+
+	"elible/internal/app/repository"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type ResponseData struct {
@@ -50,3 +55,17 @@ func WriteErrorResponse(w http.ResponseWriter, statusCode int, message string) {
 	w.WriteHeader(statusCode)
 	json.NewEncoder(w).Encode(respErr)
 }
+
+// WriteStoreError answers a failed lookup, update, delete or restore of a soft
+// deleted collection: 404 with notFound when the document does not exist or is
+// in the trash, 400 when a restored document is not in the trash and 500 otherwise.
+func WriteStoreError(c *gin.Context, err error, notFound string) {
+	switch err {
+	case mongo.ErrNoDocuments:
+		c.JSON(http.StatusNotFound, NewResponseError(http.StatusNotFound, notFound))
+	case repository.ErrNotInTrash:
+		c.JSON(http.StatusBadRequest, NewResponseError(http.StatusBadRequest, err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, NewResponseError(http.StatusInternalServerError, err.Error()))
+	}
+}
@@ -0,0 +1,74 @@
+// Package scheduler runs background jobs at a fixed interval inside the API process.
+package scheduler
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func() error
+}
+
+type Scheduler struct {
+	jobs []Job
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func New() *Scheduler {
+	return &Scheduler{
+		stop: make(chan struct{}),
+	}
+}
+
+// Add registers a job. Jobs must be added before Start.
+func (s *Scheduler) Add(name string, interval time.Duration, run func() error) {
+	s.jobs = append(s.jobs, Job{Name: name, Interval: interval, Run: run})
+}
+
+// Start runs every job once right away and then on each interval until Stop is called.
+func (s *Scheduler) Start() {
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(job)
+	}
+}
+
+func (s *Scheduler) Stop() {
+	close(s.stop)
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(job Job) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		run(job)
+
+		select {
+		case <-ticker.C:
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// run executes a job, keeping a failing or panicking job from stopping the scheduler.
+func run(job Job) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Scheduled job %s panicked: %v\n", job.Name, r)
+		}
+	}()
+
+	if err := job.Run(); err != nil {
+		log.Printf("Scheduled job %s failed, Reason: %v\n", job.Name, err)
+	}
+}
@@ -5,9 +5,11 @@ import (
 	"elible/internal/app/handlers"
 	"elible/internal/config"
 	"elible/internal/mongodb"
+	"elible/internal/scheduler"
 	"log"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/didip/tollbooth"
 	"github.com/didip/tollbooth_gin"
@@ -24,12 +26,20 @@ func main() {
 	if err != nil {
 		log.Fatalf("Error connecting to MongoDB: %v", err)
 	}
+	if err := mongodb.RequireTransactions(mongoClient); err != nil {
+		log.Fatalf("Error checking MongoDB: %v", err)
+	}
 
 	deps, err := elible.InitializeDependencies(cfg, mongoClient)
 	if err != nil {
 		log.Fatalf("Error initializing dependencies: %v", err)
 	}
 
+	jobs := scheduler.New()
+	jobs.Add("purge-trash", 24*time.Hour, deps.TrashService.Purge)
+	jobs.Add("task-digest", time.Hour, deps.TaskService.SendDigests)
+	jobs.Start()
+
 	router := gin.Default()
 	router.Use(corsMiddleware(), rateLimitMiddleware(), cspMiddleware())
 
@@ -54,7 +64,7 @@ func corsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-API-Key")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(http.StatusOK)